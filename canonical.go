@@ -0,0 +1,158 @@
+package jo
+
+import (
+	"slices"
+	"strconv"
+	"unicode/utf16"
+)
+
+// Canonicalize returns the canonical form of the JSON value in src, as
+// defined by the JSON Canonicalization Scheme (RFC 8785): insignificant
+// whitespace is removed, object members are sorted by the UTF-16 code units
+// of their keys, numbers are serialized the way ECMAScript does, and strings
+// use as few escape sequences as possible.
+//
+// Input which isn't valid JSON results in a *SyntaxError. Input which is,
+// but has no canonical form, results in a *CanonicalizeError; that includes
+// duplicate object keys, strings containing invalid UTF-8 or unpaired
+// surrogates, and numbers too large to be represented as float64.
+func Canonicalize(src []byte) ([]byte, error) {
+	root, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return appendCanonical(make([]byte, 0, len(src)), root)
+}
+
+// A CanonicalizeError describes a JSON value which has no canonical form.
+type CanonicalizeError struct {
+	msg string
+
+	// Offset is the input offset of the offending value.
+	Offset int64
+}
+
+func (e *CanonicalizeError) Error() string {
+	return e.msg
+}
+
+// appendCanonical appends the canonical form of n to dst.
+func appendCanonical(dst []byte, n *Node) ([]byte, error) {
+	switch n.Kind {
+	case ObjectStart:
+		type member struct {
+			key   string
+			units []uint16
+			n     *Node
+		}
+
+		members := make([]member, len(n.kids))
+		for i := range n.kids {
+			kid := &n.kids[i]
+			if _, msg := checkString(kid.key); msg != "" {
+				return nil, &CanonicalizeError{"jo: invalid UTF-8 or unpaired surrogate in object key", keyOffset(kid)}
+			}
+
+			key := string(unquote(nil, kid.key))
+			members[i] = member{key, utf16.Encode([]rune(key)), kid}
+		}
+
+		slices.SortStableFunc(members, func(a, b member) int {
+			return slices.Compare(a.units, b.units)
+		})
+
+		dst = append(dst, '{')
+		for i, m := range members {
+			if i > 0 {
+				if m.key == members[i-1].key {
+					return nil, &CanonicalizeError{"jo: duplicate object key " + strconv.Quote(m.key), m.n.Offset}
+				}
+				dst = append(dst, ',')
+			}
+
+			dst = appendCanonicalString(dst, m.key)
+			dst = append(dst, ':')
+
+			var err error
+			if dst, err = appendCanonical(dst, m.n); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, '}'), nil
+
+	case ArrayStart:
+		dst = append(dst, '[')
+		for i := range n.kids {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+
+			var err error
+			if dst, err = appendCanonical(dst, &n.kids[i]); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, ']'), nil
+
+	case StringStart:
+		if _, msg := checkString(n.Raw); msg != "" {
+			return nil, &CanonicalizeError{"jo: invalid UTF-8 or unpaired surrogate in string", n.Offset}
+		}
+		return appendCanonicalString(dst, string(unquote(nil, n.Raw))), nil
+
+	case NumberStart:
+		f, err := strconv.ParseFloat(string(n.Raw), 64)
+		if err != nil {
+			return nil, &CanonicalizeError{"jo: number " + string(n.Raw) + " out of range", n.Offset}
+		}
+
+		// ECMAScript serializes numbers the same way encoding/json does,
+		// save for negative zero.
+		if f == 0 {
+			return append(dst, '0'), nil
+		}
+		return appendFloat(dst, f, 64), nil
+	}
+
+	return append(dst, n.Raw...), nil
+}
+
+// keyOffset returns the input offset of an object member's key. Both the key
+// and the value are slices of the parsed input, so the distance between them
+// shows in their capacities.
+func keyOffset(kid *Node) int64 {
+	return kid.Offset - int64(cap(kid.key)-cap(kid.Raw))
+}
+
+// appendCanonicalString appends s to dst as a quoted string literal, only
+// escaping what has to be escaped.
+func appendCanonicalString(dst []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+
+	dst = append(dst, '"')
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			dst = append(dst, '\\', c)
+		case '\b':
+			dst = append(dst, '\\', 'b')
+		case '\f':
+			dst = append(dst, '\\', 'f')
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		case '\t':
+			dst = append(dst, '\\', 't')
+		default:
+			if c < 0x20 {
+				dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+			} else {
+				dst = append(dst, c)
+			}
+		}
+	}
+
+	return append(dst, '"')
+}
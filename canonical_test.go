@@ -0,0 +1,142 @@
+package jo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func ExampleCanonicalize() {
+	out, err := Canonicalize([]byte(`{"b": [1.0, 2E3], "a": "\u00e9\/"}`))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+	// Output:
+	// {"a":"é/","b":[1,2000]}
+}
+
+// The test vectors in sections 3.2.2 and 3.2.3 of RFC 8785.
+var canonicalTests = []struct {
+	in, out string
+}{
+	{
+		`{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`,
+		`{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+	},
+	{
+		`{
+  "\u20ac": "Euro Sign",
+  "\r": "Carriage Return",
+  "\ufb33": "Hebrew Letter Dalet With Dagesh",
+  "1": "One",
+  "\ud83d\ude00": "Emoji: Grinning Face",
+  "\u0080": "Control",
+  "\u00f6": "Latin Small Letter O With Diaeresis"
+}`,
+		"{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"ö\":\"Latin Small Letter O With Diaeresis\",\"€\":\"Euro Sign\",\"😀\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+	},
+	{` [ ] `, `[]`},
+	{`{"a":{"d":[],"c":{}},"b":-0.0}`, `{"a":{"c":{},"d":[]},"b":0}`},
+	{`"\b\f\u0001\u001f\u007f"`, "\"\\b\\f\\u0001\\u001f\u007f\""},
+}
+
+func TestCanonicalize(t *testing.T) {
+	for _, test := range canonicalTests {
+		got, err := Canonicalize([]byte(test.in))
+		if err != nil || string(got) != test.out {
+			t.Errorf("Canonicalize(%#q):", test.in)
+			t.Errorf("  got  %s, %v", got, err)
+			t.Errorf("  want %s", test.out)
+		}
+	}
+}
+
+// The number serialization samples in appendix B of RFC 8785.
+var canonicalNumberTests = []struct {
+	bits uint64
+	out  string
+}{
+	{0x0000000000000000, "0"},
+	{0x8000000000000000, "0"},
+	{0x0000000000000001, "5e-324"},
+	{0x8000000000000001, "-5e-324"},
+	{0x7fefffffffffffff, "1.7976931348623157e+308"},
+	{0xffefffffffffffff, "-1.7976931348623157e+308"},
+	{0x4340000000000000, "9007199254740992"},
+	{0xc340000000000000, "-9007199254740992"},
+	{0x4430000000000000, "295147905179352830000"},
+	{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+	{0x44b52d02c7e14af6, "1e+23"},
+	{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+	{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+	{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+	{0x444b1ae4d6e2ef50, "1e+21"},
+	{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+	{0x3eb0c6f7a0b5ed8d, "0.000001"},
+	{0x41b3de4355555553, "333333333.3333332"},
+	{0x41b3de4355555554, "333333333.33333325"},
+	{0x41b3de4355555555, "333333333.3333333"},
+	{0x41b3de4355555556, "333333333.3333334"},
+	{0x41b3de4355555557, "333333333.33333343"},
+	{0xbecbf647612f3696, "-0.0000033333333333333333"},
+	{0x43143ff3c1cb0959, "1424953923781206.2"},
+}
+
+func TestCanonicalizeNumbers(t *testing.T) {
+	for _, test := range canonicalNumberTests {
+		f := math.Float64frombits(test.bits)
+
+		// Feed the number in as a long-winded literal, to make sure it
+		// isn't simply being copied.
+		in := strconv.FormatFloat(f, 'e', 40, 64)
+
+		got, err := Canonicalize([]byte(in))
+		if err != nil || string(got) != test.out {
+			var b [8]byte
+			binary.BigEndian.PutUint64(b[:], test.bits)
+			t.Errorf("Canonicalize(%x): got %s, %v, want %s", b, got, err, test.out)
+		}
+	}
+}
+
+func TestCanonicalizeErrors(t *testing.T) {
+	for _, test := range []struct {
+		in     string
+		offset int64
+	}{
+		{`{"a": 1, "b": 2, "a": 3}`, 22},
+		{`{"a": 1, "\u0061": 2}`, 19},
+		{`["\ud800"]`, 1},
+		{`["\udc00\ud800"]`, 1},
+		{`["\ud800\u0041"]`, 1},
+		{`{"\ud800x": 1}`, 1},
+		{`{"a": 1, "\udc00": 2}`, 9},
+		{"{\"k\": [1],\n \"\xff\": {}}", 12},
+		{"[\"\xff\"]", 1},
+		{"[\"\xed\xa0\x80\"]", 1},
+		{`[1e400]`, 1},
+		{`[-1e400]`, 1},
+	} {
+		_, err := Canonicalize([]byte(test.in))
+
+		var cerr *CanonicalizeError
+		if !errors.As(err, &cerr) || cerr.Offset != test.offset {
+			t.Errorf("Canonicalize(%#q): got error %v, want *CanonicalizeError at offset %d", test.in, err, test.offset)
+		}
+	}
+
+	for _, in := range []string{``, `{"a": 1,}`, `[1] [2]`, `nan`} {
+		var serr *SyntaxError
+		if _, err := Canonicalize([]byte(in)); !errors.As(err, &serr) {
+			t.Errorf("Canonicalize(%#q): got error %v, want *SyntaxError", in, err)
+		}
+	}
+}
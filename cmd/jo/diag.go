@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/erkl/jo"
+)
+
+// syntaxError reports a *jo.SyntaxError found in data, which starts at the
+// given line of the named input, with a snippet of the offending line.
+func (c *cli) syntaxError(name string, firstLine int, data []byte, err error) {
+	var serr *jo.SyntaxError
+	if !errors.As(err, &serr) {
+		fmt.Fprintf(c.stderr, "%s: %v\n", displayName(name), err)
+		return
+	}
+
+	d := jo.Diagnose(data, serr)
+	d.Line += firstLine - 1
+	d.Render(c.stderr, displayName(name), c.color)
+}
+
+// A recorder keeps the input read through it, so that syntax errors found
+// in a stream of values can be diagnosed. Only the input from the start of
+// the line on which the current value starts is kept.
+type recorder struct {
+	r   io.Reader
+	buf []byte
+
+	// Input offset of buf[0], and the number of the line it's on.
+	base int64
+	line int
+
+	// Input offset following the latest complete value.
+	done int64
+}
+
+func newRecorder(r io.Reader) *recorder {
+	return &recorder{r: r, line: 1}
+}
+
+func (r *recorder) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.buf = append(r.buf, p[:n]...)
+	return n, err
+}
+
+// complete records that the input up to offset off holds complete values.
+func (r *recorder) complete(off int64) {
+	r.discard()
+	r.done = off
+}
+
+// discard drops the input preceding the last line break between the latest
+// complete value and the next one.
+func (r *recorder) discard() {
+	cut := -1
+	for i := int(r.done - r.base); i < len(r.buf); i++ {
+		if c := r.buf[i]; c == '\n' {
+			cut = i
+		} else if c != ' ' && c != '\t' && c != '\r' {
+			break
+		}
+	}
+
+	if cut >= 0 {
+		r.line += bytes.Count(r.buf[:cut+1], []byte{'\n'})
+		r.base += int64(cut + 1)
+		r.buf = r.buf[cut+1:]
+	}
+}
+
+// syntaxError returns the input kept, the number of the line it starts on
+// and err with its offset made relative to it, for passing to
+// cli.syntaxError.
+func (r *recorder) syntaxError(err *jo.SyntaxError) ([]byte, int, *jo.SyntaxError) {
+	r.discard()
+
+	rel := *err
+	rel.Offset -= r.base
+	return r.buf, r.line, &rel
+}
+
+// colorTerminal reports whether f is a terminal which should get colored
+// output. The NO_COLOR convention is respected.
+func colorTerminal(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
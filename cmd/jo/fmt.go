@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/erkl/jo"
+)
+
+func (c *cli) fmt(args []string) int {
+	fs := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	var (
+		compact = fs.Bool("compact", false, "remove all insignificant whitespace")
+		indent  = fs.String("indent", "  ", "indentation `string`")
+		width   = fs.Int("width", 0, "keep arrays of scalars on one line if they fit in `n` columns")
+		write   = fs.Bool("w", false, "write the result back to each file")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "usage: jo fmt [-compact] [-indent string] [-width n] [-w] [file ...]\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	code := exitOK
+
+	for _, name := range inputs(fs.Args()) {
+		if *write && name == "-" {
+			fmt.Fprintf(c.stderr, "jo: can't use -w with standard input\n")
+			return exitUsage
+		}
+
+		data, err := c.readFile(name)
+		if err != nil {
+			code = worst(code, c.ioError(err))
+			continue
+		}
+
+		var out bytes.Buffer
+
+		r := jo.NewReformatter(&out)
+		if !*compact {
+			r.SetIndent("", *indent)
+			r.SetShortArrays(*width)
+		}
+
+		// Trailing whitespace would otherwise be kept when indenting.
+		_, err = r.Write(bytes.TrimRight(data, " \t\r\n"))
+		if err == nil {
+			err = r.Close()
+		}
+		if err != nil {
+			c.syntaxError(name, 1, data, err)
+			code = worst(code, exitInvalid)
+			continue
+		}
+		out.WriteByte('\n')
+
+		if *write {
+			err = os.WriteFile(name, out.Bytes(), 0666)
+		} else {
+			_, err = c.stdout.Write(out.Bytes())
+		}
+		if err != nil {
+			code = worst(code, c.ioError(err))
+		}
+	}
+
+	return code
+}
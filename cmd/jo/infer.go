@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+
+	"github.com/erkl/jo"
+	"github.com/erkl/jo/schema"
+)
+
+func (c *cli) infer(args []string) int {
+	fs := flag.NewFlagSet("infer", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	var (
+		compact       = fs.Bool("c", false, "write the schema on a single line")
+		maxEnum       = fs.Int("enum", 10, "suggest enums for strings with up to `n` distinct values, or none if 0")
+		maxProperties = fs.Int("max-properties", 200, "treat objects with more than `n` distinct keys as maps")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "usage: jo infer [-c] [-enum n] [-max-properties n] [file ...]\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	inf := schema.NewInferrer()
+	inf.SetMaxEnum(*maxEnum)
+	inf.SetMaxProperties(*maxProperties)
+
+	for _, name := range inputs(fs.Args()) {
+		if code := c.inferFile(name, inf); code != exitOK {
+			return code
+		}
+	}
+
+	var buf bytes.Buffer
+	inf.Write(&buf)
+
+	out := bufio.NewWriter(c.stdout)
+	r := jo.NewReformatter(out)
+	if !*compact {
+		r.SetIndent("", "  ")
+	}
+	r.Write(buf.Bytes())
+	r.Close()
+	out.WriteByte('\n')
+
+	if err := out.Flush(); err != nil {
+		return c.ioError(err)
+	}
+	return exitOK
+}
+
+// inferFile adds each value in the named input as a sample.
+func (c *cli) inferFile(name string, inf *schema.Inferrer) int {
+	data, err := c.readFile(name)
+	if err != nil {
+		return c.ioError(err)
+	}
+
+	if err := inf.Add(bytes.NewReader(data)); err != nil {
+		c.syntaxError(name, 1, data, err)
+		return exitInvalid
+	}
+	return exitOK
+}
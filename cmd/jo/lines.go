@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/erkl/jo"
+)
+
+func (c *cli) lines(args []string) int {
+	fs := flag.NewFlagSet("lines", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	quiet := fs.Bool("q", false, "don't report valid files")
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "usage: jo lines [-q] [file ...]\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	code := exitOK
+
+	for _, name := range inputs(fs.Args()) {
+		code = worst(code, c.linesFile(name, *quiet))
+	}
+
+	return code
+}
+
+// linesFile validates the named input as newline-delimited JSON. Blank
+// lines are ignored.
+func (c *cli) linesFile(name string, quiet bool) int {
+	f, err := c.open(name)
+	if err != nil {
+		return c.ioError(err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	code := exitOK
+	values := 0
+
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return c.ioError(err)
+		}
+
+		if text := bytes.TrimRight(line, "\r\n"); len(bytes.TrimSpace(text)) > 0 {
+			if verr := jo.Validate(text); verr != nil {
+				c.syntaxError(name, n, text, verr)
+				code = exitInvalid
+			} else {
+				values++
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	if code == exitOK && !quiet {
+		fmt.Fprintf(c.stdout, "%s: ok (%d values)\n", displayName(name), values)
+	}
+
+	return code
+}
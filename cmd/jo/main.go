@@ -0,0 +1,151 @@
+// Command jo validates, reformats and queries JSON documents.
+//
+// Usage:
+//
+//	jo <command> [flags] [file ...]
+//
+// The commands are:
+//
+//	validate  check that each file holds a single valid JSON value
+//	fmt       reformat JSON values, indenting them by default
+//	lines     check that each line of each file is a valid JSON value
+//	query     run a jq-like query on each JSON value in each file
+//	infer     infer a JSON Schema describing the JSON values in all files
+//	stats     describe the contents of each file
+//
+// Without file arguments, or given "-", commands read from standard input.
+// Syntax errors are reported with the file name, line and column, followed
+// by the offending line with a caret pointing at the error, what would have
+// been valid there, and the path to the value containing the error. They
+// are highlighted when standard error is a terminal, unless NO_COLOR is set.
+// The validate command can report several errors per file, skipping ahead to
+// the next plausible boundary after each one.
+//
+// The exit status is 0 on success, 1 when any input isn't valid JSON, 2 on
+// usage errors, 3 when an input can't be read or output can't be written,
+// and 4 when a query fails on some input.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Exit codes.
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitUsage   = 2
+	exitIO      = 3
+	exitQuery   = 4
+)
+
+// A command is a subcommand of jo.
+type command struct {
+	name    string
+	summary string
+	run     func(c *cli, args []string) int
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"validate", "check that each file holds a single valid JSON value", (*cli).validate},
+		{"fmt", "reformat JSON values, indenting them by default", (*cli).fmt},
+		{"lines", "check that each line of each file is a valid JSON value", (*cli).lines},
+		{"query", "run a jq-like query on each JSON value in each file", (*cli).query},
+		{"infer", "infer a JSON Schema describing the JSON values in all files", (*cli).infer},
+		{"stats", "describe the contents of each file", (*cli).stats},
+	}
+}
+
+func main() {
+	c := &cli{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr, color: colorTerminal(os.Stderr)}
+	os.Exit(c.run(os.Args[1:]))
+}
+
+// cli holds the standard streams used by commands.
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	// Whether syntax errors are highlighted.
+	color bool
+}
+
+// run runs the command named by args[0], and returns the exit code.
+func (c *cli) run(args []string) int {
+	if len(args) == 0 {
+		c.usage()
+		return exitUsage
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(c, args[1:])
+		}
+	}
+
+	if args[0] != "help" && args[0] != "-h" && args[0] != "-help" {
+		fmt.Fprintf(c.stderr, "jo: unknown command %q\n", args[0])
+	}
+	c.usage()
+	return exitUsage
+}
+
+func (c *cli) usage() {
+	fmt.Fprintf(c.stderr, "usage: jo <command> [flags] [file ...]\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(c.stderr, "  %-10s%s\n", cmd.name, cmd.summary)
+	}
+}
+
+// open opens the named input, which is standard input if name is "-".
+func (c *cli) open(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(c.stdin), nil
+	}
+	return os.Open(name)
+}
+
+// readFile reads the whole of the named input.
+func (c *cli) readFile(name string) ([]byte, error) {
+	r, err := c.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// displayName returns the name used for an input in messages.
+func displayName(name string) string {
+	if name == "-" {
+		return "<stdin>"
+	}
+	return name
+}
+
+// inputs returns the input names given as arguments, defaulting to standard
+// input.
+func inputs(args []string) []string {
+	if len(args) == 0 {
+		return []string{"-"}
+	}
+	return args
+}
+
+// ioError reports an I/O error and returns the matching exit code.
+func (c *cli) ioError(err error) int {
+	fmt.Fprintf(c.stderr, "jo: %v\n", err)
+	return exitIO
+}
+
+// worst combines two exit codes, keeping the more severe one.
+func worst(a, b int) int {
+	return max(a, b)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var cliTests = []struct {
+	args   []string
+	stdin  string
+	code   int
+	stdout string
+	stderr string
+}{
+	{[]string{"validate"}, `{"a": [1, 2]}`, exitOK, "<stdin>: ok\n", ""},
+	{[]string{"validate", "-q", "-"}, `{"a": [1, 2]}`, exitOK, "", ""},
+	{
+		[]string{"validate"}, "{\n\t\"a\": [1, 2}\n}", exitInvalid, "",
+		"<stdin>:2:12: invalid character '}': expected ',' or ']' after array element\n 1 | {\n 2 | \t\"a\": [1, 2}\n   | \t          ^\n   = at $.a\n",
+	},
+	{
+		[]string{"validate"}, `["é", tru]`, exitInvalid, "",
+		"<stdin>:1:10: invalid character ']': expected 'e' in literal true\n 1 | [\"é\", tru]\n   |          ^\n   = at $[1]\n",
+	},
+	{
+		[]string{"validate"}, `{"a": 1`, exitInvalid, "",
+		"<stdin>:1:8: unexpected end of JSON input\n 1 | {\"a\": 1\n   |        ^ expected digit, '.', exponent, ',' or '}'\n   = at $\n",
+	},
+	{
+		[]string{"validate", "-max-errors", "0"}, `[1 2, tru]`, exitInvalid, "",
+		"<stdin>:1:4: invalid character '2': expected ',' or ']' after array element\n 1 | [1 2, tru]\n   |    ^\n   = at $\n" +
+			"<stdin>:1:10: invalid character ']': expected 'e' in literal true\n 1 | [1 2, tru]\n   |          ^\n   = at $[1]\n",
+	},
+	{[]string{"validate", "does-not-exist.json"}, ``, exitIO, "", "jo: open does-not-exist.json: no such file or directory\n"},
+
+	{[]string{"fmt"}, `{"a":[1,2],"b":{}}` + "\n", exitOK, "{\n  \"a\": [\n    1,\n    2\n  ],\n  \"b\": {}\n}\n", ""},
+	{[]string{"fmt", "-width", "20"}, `{"a":[1,2],"b":{}}`, exitOK, "{\n  \"a\": [1, 2],\n  \"b\": {}\n}\n", ""},
+	{[]string{"fmt", "-indent", "\t"}, `[1]`, exitOK, "[\n\t1\n]\n", ""},
+	{[]string{"fmt", "-compact"}, " { \"a\" : [ 1 , 2 ] } \n", exitOK, "{\"a\":[1,2]}\n", ""},
+	{[]string{"fmt"}, `[1 2]`, exitInvalid, "", "<stdin>:1:4: invalid character '2': expected ',' or ']' after array element\n 1 | [1 2]\n   |    ^\n   = at $\n"},
+	{[]string{"fmt", "-w"}, `[]`, exitUsage, "", "jo: can't use -w with standard input\n"},
+
+	{[]string{"lines"}, "{\"a\": 1}\n\n[2]\n", exitOK, "<stdin>: ok (2 values)\n", ""},
+	{[]string{"lines"}, "{\"a\": 1}\r\n{\"a\" 2}\r\n[3", exitInvalid, "",
+		"<stdin>:2:6: invalid character '2': expected ':' after object key\n 2 | {\"a\" 2}\n   |      ^\n   = at $.a\n" +
+			"<stdin>:3:3: unexpected end of JSON input\n 3 | [3\n   |   ^ expected digit, '.', exponent, ',' or ']'\n   = at $\n",
+	},
+	{[]string{"lines"}, "1 2\n", exitInvalid, "", "<stdin>:1:3: invalid character '2': expected end of input after top-level value\n 1 | 1 2\n   |   ^\n   = at $\n"},
+
+	{[]string{"query", ".a"}, `{"a": {"b": [1, 2]}}`, exitOK, "{\n  \"b\": [\n    1,\n    2\n  ]\n}\n", ""},
+	{[]string{"query", "-c", "select(.n > 1) | {id}"}, "{\"id\":1,\"n\":1}\n\n{\"id\":\"<b>\",\"n\":2}\n", exitOK, "{\"id\":\"<b>\"}\n", ""},
+	{[]string{"query", "-r", ".[]"}, `["a\tb", 1, null]`, exitOK, "a\tb\n1\nnull\n", ""},
+	{[]string{"query", "-c", ".a"}, "{\"a\":1}\n2\n{\"a\":3}", exitQuery, "1\n3\n", "<stdin>: query: cannot index number with \"a\"\n"},
+	{[]string{"query", "-c", ".a"}, "{\"a\":1}\n{\"a\" 2}\n{\"a\":3}", exitInvalid, "1\n", "<stdin>:2:6: invalid character '2': expected ':' after object key\n 2 | {\"a\" 2}\n   |      ^\n   = at $.a\n"},
+	{[]string{"query", "-c", ".[0]"}, "[1,\n2]\n\n[3, [4,\n5,\n]]", exitInvalid, "1\n", "<stdin>:6:1: invalid character ']': expected value after ','; trailing commas are not allowed\n 5 | 5,\n 6 | ]]\n   | ^\n   = at $[1][2]\n"},
+	{[]string{"query", "-c", "."}, "1 {\"a\": tru}", exitInvalid, "1\n", "<stdin>:1:12: invalid character '}': expected 'e' in literal true\n 1 | 1 {\"a\": tru}\n   |            ^\n   = at $.a\n"},
+	{[]string{"query", ".a |"}, ``, exitUsage, "", "jo: query: unexpected end of expression at offset 4\n"},
+	{[]string{"query"}, ``, exitUsage, "", ""},
+
+	{
+		[]string{"infer", "-c"}, "{\"id\":1,\"kind\":\"a\"}\n{\"id\":2.5,\"kind\":\"a\",\"tags\":[]}\n", exitOK,
+		`{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"id":{"type":"number","minimum":1,"maximum":2.5},"kind":{"type":"string","enum":["a"]},"tags":{"type":"array"}},"required":["id","kind"]}` + "\n", "",
+	},
+	{
+		[]string{"stats"}, `{"a": [1, "xyz"], "b": {"a": null}}`, exitOK,
+		"<stdin>:\n" +
+			"  size            35 bytes, 5 whitespace\n" +
+			"  objects         2, with 3 keys\n" +
+			"  arrays          1\n" +
+			"  strings         1\n" +
+			"  numbers         1\n" +
+			"  booleans        0\n" +
+			"  nulls           1\n" +
+			"  max depth       2\n" +
+			"  longest string  3 bytes at $.a[1]\n" +
+			"  largest array   2 elements at $.a\n" +
+			"  distinct keys\n" +
+			"    $    2\n" +
+			"    $.b  1\n",
+		"",
+	},
+	{[]string{"stats"}, `[1,`, exitInvalid, "", "<stdin>:1:4: unexpected end of JSON input\n 1 | [1,\n   |    ^ expected value\n   = at $[1]\n"},
+	{[]string{"infer"}, "{\"a\":1}\n{\"a\" 2}", exitInvalid, "", "<stdin>:2:6: invalid character '2': expected ':' after object key\n 1 | {\"a\":1}\n 2 | {\"a\" 2}\n   |      ^\n   = at $.a\n"},
+
+	{[]string{}, ``, exitUsage, "", ""},
+	{[]string{"frobnicate"}, ``, exitUsage, "", ""},
+	{[]string{"validate", "-bogus"}, ``, exitUsage, "", ""},
+}
+
+func TestCLI(t *testing.T) {
+	for _, test := range cliTests {
+		var stdout, stderr bytes.Buffer
+
+		c := &cli{stdin: strings.NewReader(test.stdin), stdout: &stdout, stderr: &stderr}
+		code := c.run(test.args)
+
+		// Only check the error output if something specific is wanted.
+		if code != test.code || stdout.String() != test.stdout || test.stderr != "" && stderr.String() != test.stderr {
+			t.Errorf("jo %s < %#q:", strings.Join(test.args, " "), test.stdin)
+			t.Errorf("  got  %d, %#q, %#q", code, stdout.String(), stderr.String())
+			t.Errorf("  want %d, %#q, %#q", test.code, test.stdout, test.stderr)
+		}
+	}
+}
+
+func TestFmtWrite(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+	bad := filepath.Join(dir, "bad.json")
+
+	os.WriteFile(good, []byte(`{"a":1}`), 0666)
+	os.WriteFile(bad, []byte(`{"a":}`), 0666)
+
+	var stdout, stderr bytes.Buffer
+	c := &cli{stdin: strings.NewReader(""), stdout: &stdout, stderr: &stderr}
+
+	if code := c.run([]string{"fmt", "-w", good, bad}); code != exitInvalid {
+		t.Errorf("got exit code %d, want %d", code, exitInvalid)
+	}
+
+	if b, _ := os.ReadFile(good); string(b) != "{\n  \"a\": 1\n}\n" {
+		t.Errorf("got %#q in formatted file", b)
+	}
+	if b, _ := os.ReadFile(bad); string(b) != `{"a":}` {
+		t.Errorf("got %#q in invalid file", b)
+	}
+	if !strings.HasPrefix(stderr.String(), bad+":1:6: ") {
+		t.Errorf("got error output %#q", stderr.String())
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/erkl/jo"
+	"github.com/erkl/jo/query"
+)
+
+func (c *cli) query(args []string) int {
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	var (
+		compact = fs.Bool("c", false, "write each result on a single line")
+		raw     = fs.Bool("r", false, "write string results without quotes")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "usage: jo query [-c] [-r] expr [file ...]\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	q, err := query.Parse(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(c.stderr, "jo: %v\n", err)
+		return exitUsage
+	}
+
+	out := bufio.NewWriter(c.stdout)
+	code := exitOK
+
+	for _, name := range inputs(fs.Args()[1:]) {
+		code = worst(code, c.queryFile(name, q, out, *compact, *raw))
+	}
+
+	if err := out.Flush(); err != nil {
+		code = worst(code, c.ioError(err))
+	}
+
+	return code
+}
+
+// queryFile runs q on each value in the named input in turn, so that only
+// one of them is held in memory at a time.
+func (c *cli) queryFile(name string, q *query.Query, out *bufio.Writer, compact, raw bool) int {
+	f, err := c.open(name)
+	if err != nil {
+		return c.ioError(err)
+	}
+	defer f.Close()
+
+	rec := newRecorder(f)
+	s := query.NewStream(rec)
+	code := exitOK
+
+	for {
+		v, err := s.Next()
+		if err == io.EOF {
+			return code
+		}
+		if se, ok := err.(*jo.SyntaxError); ok {
+			data, line, se := rec.syntaxError(se)
+			c.syntaxError(name, line, data, se)
+			return worst(code, exitInvalid)
+		}
+		if err != nil {
+			return worst(code, c.ioError(err))
+		}
+		rec.complete(s.Offset())
+
+		for res, err := range q.Run(v) {
+			if err != nil {
+				fmt.Fprintf(c.stderr, "%s: %v\n", displayName(name), err)
+				code = worst(code, exitQuery)
+				break
+			}
+			if err := writeResult(out, res, compact, raw); err != nil {
+				return worst(code, c.ioError(err))
+			}
+		}
+	}
+}
+
+// writeResult writes a query result followed by a newline, indenting it by
+// two spaces unless compact is set. Strings are written unquoted if raw is
+// set.
+func writeResult(out *bufio.Writer, v any, compact, raw bool) error {
+	if s, ok := v.(string); ok && raw {
+		out.WriteString(s)
+		return out.WriteByte('\n')
+	}
+
+	r := jo.NewReformatter(out)
+	if !compact {
+		r.SetIndent("", "  ")
+	}
+
+	w := jo.NewWriter(r)
+	w.SetEscapeHTML(false)
+
+	if err := query.Write(w, v); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if err := r.Close(); err != nil {
+		return err
+	}
+
+	return out.WriteByte('\n')
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/erkl/jo"
+)
+
+func (c *cli) stats(args []string) int {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "usage: jo stats [file ...]\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	out := bufio.NewWriter(c.stdout)
+	code := exitOK
+
+	for _, name := range inputs(fs.Args()) {
+		code = worst(code, c.statsFile(name, out))
+	}
+
+	if err := out.Flush(); err != nil {
+		code = worst(code, c.ioError(err))
+	}
+
+	return code
+}
+
+// statsFile describes the named input.
+func (c *cli) statsFile(name string, out *bufio.Writer) int {
+	data, err := c.readFile(name)
+	if err != nil {
+		return c.ioError(err)
+	}
+
+	st, err := jo.Stats(bytes.NewReader(data))
+	if err != nil {
+		c.syntaxError(name, 1, data, err)
+		return exitInvalid
+	}
+
+	fmt.Fprintf(out, "%s:\n", displayName(name))
+	fmt.Fprintf(out, "  size            %d bytes, %d whitespace\n", st.Size, st.Whitespace)
+	fmt.Fprintf(out, "  objects         %d, with %d keys\n", st.Objects, st.Keys)
+	fmt.Fprintf(out, "  arrays          %d\n", st.Arrays)
+	fmt.Fprintf(out, "  strings         %d\n", st.Strings)
+	fmt.Fprintf(out, "  numbers         %d\n", st.Numbers)
+	fmt.Fprintf(out, "  booleans        %d\n", st.Bools)
+	fmt.Fprintf(out, "  nulls           %d\n", st.Nulls)
+	fmt.Fprintf(out, "  max depth       %d\n", st.MaxDepth)
+	if st.LongestString.Path != "" {
+		fmt.Fprintf(out, "  longest string  %d bytes at %s\n", st.LongestString.Size, st.LongestString.Path)
+	}
+	if st.LargestArray.Path != "" {
+		fmt.Fprintf(out, "  largest array   %d elements at %s\n", st.LargestArray.Size, st.LargestArray.Path)
+	}
+
+	if len(st.DistinctKeys) > 0 {
+		paths := make([]string, 0, len(st.DistinctKeys))
+		width := 0
+		for path := range st.DistinctKeys {
+			paths = append(paths, path)
+			width = max(width, len(path))
+		}
+		slices.Sort(paths)
+
+		fmt.Fprintf(out, "  distinct keys\n")
+		for _, path := range paths {
+			fmt.Fprintf(out, "    %s%s  %d\n", path, strings.Repeat(" ", width-len(path)), st.DistinctKeys[path])
+		}
+	}
+
+	return exitOK
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/erkl/jo"
+)
+
+func (c *cli) validate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	quiet := fs.Bool("q", false, "don't report valid files")
+	maxErrors := fs.Int("max-errors", 1, "report up to `n` syntax errors per file, or all of them if 0")
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "usage: jo validate [-q] [-max-errors n] [file ...]\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	code := exitOK
+
+	for _, name := range inputs(fs.Args()) {
+		data, err := c.readFile(name)
+		if err != nil {
+			code = worst(code, c.ioError(err))
+			continue
+		}
+
+		if errs := jo.ValidateAll(data, *maxErrors); errs != nil {
+			for _, err := range errs {
+				c.syntaxError(name, 1, data, err)
+			}
+			code = worst(code, exitInvalid)
+		} else if !*quiet {
+			fmt.Fprintf(c.stdout, "%s: ok\n", displayName(name))
+		}
+	}
+
+	return code
+}
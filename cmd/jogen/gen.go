@@ -0,0 +1,542 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A generator emits decoding methods for a set of struct types declared in a
+// single package.
+type generator struct {
+	pkg string
+
+	// Type declarations in the package, and the names of types which have
+	// UnmarshalJSON or UnmarshalText methods.
+	decls            map[string]*ast.TypeSpec
+	unmarshalers     map[string]bool
+	textUnmarshalers map[string]bool
+
+	// Types to generate methods for, in order.
+	targets []string
+
+	// Whether to generate UnmarshalJSON methods.
+	json bool
+
+	// Helper functions, keyed by the type they decode.
+	helpers    map[string]string
+	helperCode bytes.Buffer
+}
+
+// newGenerator returns a generator for the package made up of files.
+func newGenerator(files []*ast.File) *generator {
+	g := &generator{
+		decls:            map[string]*ast.TypeSpec{},
+		unmarshalers:     map[string]bool{},
+		textUnmarshalers: map[string]bool{},
+		helpers:          map[string]string{},
+	}
+
+	for _, f := range files {
+		g.pkg = f.Name.Name
+
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok {
+						g.decls[ts.Name.Name] = ts
+					}
+				}
+
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					continue
+				}
+
+				id, ok := derefExpr(decl.Recv.List[0].Type).(*ast.Ident)
+				if !ok {
+					continue
+				}
+
+				switch decl.Name.Name {
+				case "UnmarshalJSON":
+					g.unmarshalers[id.Name] = true
+				case "UnmarshalText":
+					g.textUnmarshalers[id.Name] = true
+				}
+			}
+		}
+	}
+
+	return g
+}
+
+// structTypes returns the names of all struct types declared in f.
+func structTypes(f *ast.File) []string {
+	var names []string
+
+	for _, decl := range f.Decls {
+		if gd, ok := decl.(*ast.GenDecl); ok {
+			for _, spec := range gd.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok && ts.TypeParams == nil {
+					if _, ok := ts.Type.(*ast.StructType); ok {
+						names = append(names, ts.Name.Name)
+					}
+				}
+			}
+		}
+	}
+
+	return names
+}
+
+// isTarget reports whether methods are being generated for the named type.
+func (g *generator) isTarget(name string) bool {
+	for _, t := range g.targets {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// generate returns the formatted source of the generated file.
+func (g *generator) generate() ([]byte, error) {
+	var body bytes.Buffer
+
+	for _, name := range g.targets {
+		ts, ok := g.decls[name]
+		if !ok {
+			return nil, fmt.Errorf("type %s not found", name)
+		}
+		st, ok := ts.Type.(*ast.StructType)
+		if !ok || ts.TypeParams != nil {
+			return nil, fmt.Errorf("type %s is not a non-generic struct type", name)
+		}
+
+		fields, err := g.fields(st)
+		if err != nil {
+			return nil, fmt.Errorf("type %s: %v", name, err)
+		}
+
+		g.genStruct(&body, name, fields)
+	}
+
+	var out bytes.Buffer
+
+	fmt.Fprintf(&out, "// Code generated by jogen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", g.pkg)
+	fmt.Fprintf(&out, "import \"github.com/erkl/jo\"\n")
+	out.Write(body.Bytes())
+	out.Write(g.helperCode.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %v\n%s", err, out.Bytes())
+	}
+
+	return src, nil
+}
+
+// A field is a struct field, possibly promoted from an embedded struct.
+type field struct {
+	name   string
+	tagged bool
+	quoted bool
+	typ    ast.Expr
+
+	// Go selector path from the outermost struct, and for each step
+	// whether it goes through an embedded pointer.
+	path  []string
+	ptrs  []string
+	index []int
+}
+
+// fields returns the fields of st which encoding/json would recognize,
+// applying its rules for promoting the fields of embedded structs.
+func (g *generator) fields(st *ast.StructType) ([]field, error) {
+	type level struct {
+		st    *ast.StructType
+		path  []string
+		ptrs  []string
+		index []int
+	}
+
+	var all []field
+	var next = []level{{st: st}}
+	var visited = map[*ast.StructType]bool{}
+
+	for len(next) > 0 {
+		current := next
+		next = nil
+
+		for _, lv := range current {
+			if visited[lv.st] {
+				continue
+			}
+			visited[lv.st] = true
+
+			i := -1
+			for _, f := range lv.st.Fields.List {
+				var tag reflect.StructTag
+				if f.Tag != nil {
+					s, _ := strconv.Unquote(f.Tag.Value)
+					tag = reflect.StructTag(s)
+				}
+
+				jsonTag := tag.Get("json")
+				name, opts, _ := strings.Cut(jsonTag, ",")
+
+				names := f.Names
+				if len(names) == 0 {
+					names = []*ast.Ident{ast.NewIdent(embeddedName(f.Type))}
+				}
+
+				for _, id := range names {
+					i++
+
+					if jsonTag == "-" {
+						continue
+					}
+
+					index := append(append([]int(nil), lv.index...), i)
+					path := append(append([]string(nil), lv.path...), id.Name)
+
+					// Embedded structs without a tag name have their fields
+					// promoted.
+					if len(f.Names) == 0 && name == "" {
+						typ, isPtr := f.Type, false
+						if star, ok := typ.(*ast.StarExpr); ok {
+							typ, isPtr = star.X, true
+						}
+
+						if ident, ok := typ.(*ast.Ident); ok {
+							if ts, ok := g.decls[ident.Name]; ok {
+								if est, ok := ts.Type.(*ast.StructType); ok {
+									ptrs := lv.ptrs
+									if isPtr {
+										ptrs = append(append([]string(nil), lv.ptrs...), strings.Join(path, "."))
+									}
+									next = append(next, level{est, path, ptrs, index})
+									continue
+								}
+							}
+						} else if _, ok := typ.(*ast.SelectorExpr); ok {
+							return nil, fmt.Errorf("embedded field %s: only types from the same package can be embedded", id.Name)
+						}
+					}
+
+					if !id.IsExported() {
+						continue
+					}
+
+					tagged := isValidTag(name)
+					if !tagged {
+						name = id.Name
+					}
+
+					quoted := false
+					if hasOption(opts, "string") {
+						if _, ok := g.basic(derefExpr(f.Type)); ok {
+							quoted = true
+						}
+					}
+
+					all = append(all, field{
+						name:   name,
+						tagged: tagged,
+						quoted: quoted,
+						typ:    f.Type,
+						path:   path,
+						ptrs:   lv.ptrs,
+						index:  index,
+					})
+				}
+			}
+		}
+	}
+
+	// Keep only the dominant field for each name: the shallowest, with
+	// tagged fields beating untagged ones at equal depth. Other conflicts
+	// cancel each other out.
+	byName := map[string][]field{}
+	for _, f := range all {
+		byName[f.name] = append(byName[f.name], f)
+	}
+
+	var out []field
+	for _, fs := range byName {
+		sort.SliceStable(fs, func(i, j int) bool {
+			if len(fs[i].index) != len(fs[j].index) {
+				return len(fs[i].index) < len(fs[j].index)
+			}
+			return fs[i].tagged && !fs[j].tagged
+		})
+
+		if len(fs) == 1 || len(fs[1].index) > len(fs[0].index) || fs[0].tagged && !fs[1].tagged {
+			out = append(out, fs[0])
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i].index, out[j].index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+
+	return out, nil
+}
+
+// genStruct emits the methods for a struct type.
+func (g *generator) genStruct(w *bytes.Buffer, name string, fields []field) {
+	var names []string
+	for _, f := range fields {
+		names = append(names, strconv.Quote(f.name))
+	}
+
+	fmt.Fprintf(w, "\nvar jogenFields%s = []string{%s}\n", name, strings.Join(names, ", "))
+
+	fmt.Fprintf(w, "\n// DecodeJO decodes the JSON value starting with tok into v, reading any\n")
+	fmt.Fprintf(w, "// further tokens from t.\n")
+	fmt.Fprintf(w, "func (v *%s) DecodeJO(t *jo.Tokenizer, tok jo.Token) error {\n", name)
+	fmt.Fprintf(w, "if tok.Kind == jo.NullStart {\nreturn nil\n}\n")
+	fmt.Fprintf(w, "if tok.Kind != jo.ObjectStart {\nreturn jo.Mismatch[%s](tok)\n}\n\n", name)
+	fmt.Fprintf(w, "for {\n")
+	fmt.Fprintf(w, "key, err := t.Next()\nif err != nil {\nreturn err\n}\n")
+	fmt.Fprintf(w, "if key.Kind == jo.ObjectEnd {\nreturn nil\n}\n\n")
+	fmt.Fprintf(w, "tok, err := t.Next()\nif err != nil {\nreturn err\n}\n\n")
+
+	fmt.Fprintf(w, "var f int\n")
+	fmt.Fprintf(w, "switch string(key.Raw) {\n")
+	for i, f := range fields {
+		fmt.Fprintf(w, "case %s:\nf = %d\n", strconv.Quote(`"`+f.name+`"`), i)
+	}
+	fmt.Fprintf(w, "default:\nf = jo.MatchKey(key.Raw, jogenFields%s)\n}\n\n", name)
+
+	fmt.Fprintf(w, "switch f {\n")
+	for i, f := range fields {
+		fmt.Fprintf(w, "case %d:\n", i)
+
+		for _, p := range f.ptrs {
+			// Embedded fields are named after their type.
+			typ := p[strings.LastIndexByte(p, '.')+1:]
+			fmt.Fprintf(w, "if v.%s == nil {\nv.%s = new(%s)\n}\n", p, p, typ)
+		}
+
+		target := "&v." + strings.Join(f.path, ".")
+
+		var expr string
+		if f.quoted {
+			expr = fmt.Sprintf("jo.DecodeQuoted[%s](tok, func(tok jo.Token) error {\nreturn %s\n})",
+				types.ExprString(f.typ), g.decodeExpr(f.typ, target))
+		} else {
+			expr = g.decodeExpr(f.typ, target)
+		}
+
+		fmt.Fprintf(w, "err = %s\n", expr)
+		fmt.Fprintf(w, "if err != nil {\nreturn jo.AtField(err, %s)\n}\n", strconv.Quote(f.path[len(f.path)-1]))
+	}
+	fmt.Fprintf(w, "default:\nif err := t.Skip(tok); err != nil {\nreturn err\n}\n")
+	fmt.Fprintf(w, "}\n}\n}\n")
+
+	if g.json {
+		fmt.Fprintf(w, "\n// UnmarshalJSON implements json.Unmarshaler.\n")
+		fmt.Fprintf(w, "func (v *%s) UnmarshalJSON(data []byte) error {\n", name)
+		fmt.Fprintf(w, "return jo.UnmarshalWith(data, v.DecodeJO)\n}\n")
+	}
+}
+
+// decodeExpr returns an expression decoding tok into the value of type typ
+// pointed to by the expression ptr, evaluating to an error.
+func (g *generator) decodeExpr(typ ast.Expr, ptr string) string {
+	if fn, ok := g.basic(typ); ok {
+		return fmt.Sprintf("jo.%s(tok, %s)", fn, ptr)
+	}
+
+	switch typ := typ.(type) {
+	case *ast.Ident:
+		if g.isTarget(typ.Name) {
+			return fmt.Sprintf("(%s).DecodeJO(t, tok)", ptr)
+		}
+		if g.unmarshalers[typ.Name] {
+			return fmt.Sprintf("jo.DecodeWith[%s](t, tok, (%s).UnmarshalJSON)", typ.Name, ptr)
+		}
+
+	case *ast.StarExpr, *ast.MapType:
+		if fn := g.helper(typ); fn != "" {
+			return fmt.Sprintf("%s(t, tok, %s)", fn, ptr)
+		}
+
+	case *ast.ArrayType:
+		if typ.Len == nil && !isByte(typ.Elt) {
+			return fmt.Sprintf("%s(t, tok, %s)", g.helper(typ), ptr)
+		}
+	}
+
+	return fmt.Sprintf("jo.DecodeValue(t, tok, %s)", ptr)
+}
+
+// basic returns the name of the jo function decoding values of typ, if it
+// is a boolean, numeric or string type.
+func (g *generator) basic(typ ast.Expr) (string, bool) {
+	id, ok := typ.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+
+	switch id.Name {
+	case "bool":
+		return "DecodeBool", true
+	case "string":
+		return "DecodeString", true
+	case "int", "int8", "int16", "int32", "int64", "rune":
+		return "DecodeInt", true
+	case "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte":
+		return "DecodeUint", true
+	case "float32", "float64":
+		return "DecodeFloat", true
+	}
+
+	// Named types with a basic underlying type, unless they decode
+	// themselves.
+	if ts, ok := g.decls[id.Name]; ok && !g.unmarshalers[id.Name] && !g.textUnmarshalers[id.Name] {
+		if under, ok := ts.Type.(*ast.Ident); ok && under.Name != id.Name {
+			return g.basic(under)
+		}
+	}
+
+	return "", false
+}
+
+// helper returns the name of a helper function decoding pointer, slice or
+// map values of type typ, generating it if necessary. It returns the empty
+// string for types which can't be handled.
+func (g *generator) helper(typ ast.Expr) string {
+	key := types.ExprString(typ)
+	if fn, ok := g.helpers[key]; ok {
+		return fn
+	}
+
+	var w bytes.Buffer
+
+	switch typ := typ.(type) {
+	case *ast.StarExpr:
+		fmt.Fprintf(&w, "if tok.Kind == jo.NullStart {\n*p = nil\nreturn nil\n}\n")
+		fmt.Fprintf(&w, "if *p == nil {\n*p = new(%s)\n}\n", types.ExprString(typ.X))
+		fmt.Fprintf(&w, "return %s\n", g.decodeExpr(typ.X, "*p"))
+
+	case *ast.ArrayType:
+		elem := types.ExprString(typ.Elt)
+		fmt.Fprintf(&w, "if tok.Kind == jo.NullStart {\n*p = nil\nreturn nil\n}\n")
+		fmt.Fprintf(&w, "if tok.Kind != jo.ArrayStart {\nreturn jo.Mismatch[%s](tok)\n}\n\n", key)
+		fmt.Fprintf(&w, "s := (*p)[:0]\n")
+		fmt.Fprintf(&w, "for i := 0; ; i++ {\n")
+		fmt.Fprintf(&w, "tok, err := t.Next()\nif err != nil {\nreturn err\n}\n")
+		fmt.Fprintf(&w, "if tok.Kind == jo.ArrayEnd {\nbreak\n}\n\n")
+		fmt.Fprintf(&w, "var zero %s\ns = append(s, zero)\n", elem)
+		fmt.Fprintf(&w, "if err := %s; err != nil {\n*p = s\nreturn jo.AtIndex(err, i)\n}\n}\n\n", g.decodeExpr(typ.Elt, "&s[i]"))
+		fmt.Fprintf(&w, "if len(s) == 0 {\ns = %s{}\n}\n", key)
+		fmt.Fprintf(&w, "*p = s\nreturn nil\n")
+
+	case *ast.MapType:
+		if id, ok := typ.Key.(*ast.Ident); !ok || id.Name != "string" {
+			return ""
+		}
+
+		elem := types.ExprString(typ.Value)
+		fmt.Fprintf(&w, "if tok.Kind == jo.NullStart {\n*p = nil\nreturn nil\n}\n")
+		fmt.Fprintf(&w, "if tok.Kind != jo.ObjectStart {\nreturn jo.Mismatch[%s](tok)\n}\n\n", key)
+		fmt.Fprintf(&w, "if *p == nil {\n*p = make(%s)\n}\n\n", key)
+		fmt.Fprintf(&w, "for {\n")
+		fmt.Fprintf(&w, "key, err := t.Next()\nif err != nil {\nreturn err\n}\n")
+		fmt.Fprintf(&w, "if key.Kind == jo.ObjectEnd {\nreturn nil\n}\n\n")
+		fmt.Fprintf(&w, "tok, err := t.Next()\nif err != nil {\nreturn err\n}\n\n")
+		fmt.Fprintf(&w, "k := jo.Unquote(key.Raw)\n\n")
+		fmt.Fprintf(&w, "var e %s\n", elem)
+		fmt.Fprintf(&w, "if err := %s; err != nil {\nreturn jo.AtKey(err, k)\n}\n", g.decodeExpr(typ.Value, "&e"))
+		fmt.Fprintf(&w, "(*p)[k] = e\n}\n")
+
+	default:
+		return ""
+	}
+
+	fn := fmt.Sprintf("jogenDecode%d", len(g.helpers))
+	g.helpers[key] = fn
+
+	fmt.Fprintf(&g.helperCode, "\nfunc %s(t *jo.Tokenizer, tok jo.Token, p *%s) error {\n", fn, key)
+	g.helperCode.Write(w.Bytes())
+	fmt.Fprintf(&g.helperCode, "}\n")
+
+	return fn
+}
+
+// embeddedName returns the implicit field name of an embedded type.
+func embeddedName(typ ast.Expr) string {
+	switch typ := typ.(type) {
+	case *ast.StarExpr:
+		return embeddedName(typ.X)
+	case *ast.SelectorExpr:
+		return typ.Sel.Name
+	case *ast.Ident:
+		return typ.Name
+	case *ast.IndexExpr:
+		return embeddedName(typ.X)
+	}
+	return ""
+}
+
+// derefExpr strips a pointer from a type expression.
+func derefExpr(typ ast.Expr) ast.Expr {
+	if star, ok := typ.(*ast.StarExpr); ok {
+		return star.X
+	}
+	return typ
+}
+
+// isByte reports whether typ is byte or uint8. Byte slices are encoded
+// using base64, which is left to jo.Unmarshal.
+func isByte(typ ast.Expr) bool {
+	id, ok := typ.(*ast.Ident)
+	return ok && (id.Name == "byte" || id.Name == "uint8")
+}
+
+// hasOption reports whether a comma-separated list of tag options contains
+// the given option.
+func hasOption(opts, name string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isValidTag reports whether a struct tag name is acceptable to
+// encoding/json.
+func isValidTag(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", c):
+		case !unicode.IsLetter(c) && !unicode.IsDigit(c):
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGolden checks that the generated code in internal/corpus is up to date.
+func TestGolden(t *testing.T) {
+	dir := filepath.Join("internal", "corpus")
+	output := filepath.Join(dir, "corpus_jogen.go")
+
+	got, err := run([]string{filepath.Join(dir, "types.go")}, nil, output, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s is out of date; run go generate", output)
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	dir := filepath.Join("internal", "corpus")
+
+	src, err := run([]string{filepath.Join(dir, "types.go")}, []string{"Item"}, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"func (v *Item) DecodeJO(t *jo.Tokenizer, tok jo.Token) error {",
+		"func (v *Item) UnmarshalJSON(data []byte) error {",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated code lacks %q", want)
+		}
+	}
+	if strings.Contains(string(src), "func (v *Order)") {
+		t.Errorf("generated code for unrequested type Order")
+	}
+}
@@ -0,0 +1,657 @@
+// Code generated by jogen; DO NOT EDIT.
+
+package corpus
+
+import "github.com/erkl/jo"
+
+var jogenFieldsBase = []string{"id", "created", "shadow"}
+
+// DecodeJO decodes the JSON value starting with tok into v, reading any
+// further tokens from t.
+func (v *Base) DecodeJO(t *jo.Tokenizer, tok jo.Token) error {
+	if tok.Kind == jo.NullStart {
+		return nil
+	}
+	if tok.Kind != jo.ObjectStart {
+		return jo.Mismatch[Base](tok)
+	}
+
+	for {
+		key, err := t.Next()
+		if err != nil {
+			return err
+		}
+		if key.Kind == jo.ObjectEnd {
+			return nil
+		}
+
+		tok, err := t.Next()
+		if err != nil {
+			return err
+		}
+
+		var f int
+		switch string(key.Raw) {
+		case "\"id\"":
+			f = 0
+		case "\"created\"":
+			f = 1
+		case "\"shadow\"":
+			f = 2
+		default:
+			f = jo.MatchKey(key.Raw, jogenFieldsBase)
+		}
+
+		switch f {
+		case 0:
+			err = jo.DecodeInt(tok, &v.ID)
+			if err != nil {
+				return jo.AtField(err, "ID")
+			}
+		case 1:
+			err = jo.DecodeString(tok, &v.Created)
+			if err != nil {
+				return jo.AtField(err, "Created")
+			}
+		case 2:
+			err = jo.DecodeString(tok, &v.Shadow)
+			if err != nil {
+				return jo.AtField(err, "Shadow")
+			}
+		default:
+			if err := t.Skip(tok); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+var jogenFieldsExtra = []string{"Note", "shadow"}
+
+// DecodeJO decodes the JSON value starting with tok into v, reading any
+// further tokens from t.
+func (v *Extra) DecodeJO(t *jo.Tokenizer, tok jo.Token) error {
+	if tok.Kind == jo.NullStart {
+		return nil
+	}
+	if tok.Kind != jo.ObjectStart {
+		return jo.Mismatch[Extra](tok)
+	}
+
+	for {
+		key, err := t.Next()
+		if err != nil {
+			return err
+		}
+		if key.Kind == jo.ObjectEnd {
+			return nil
+		}
+
+		tok, err := t.Next()
+		if err != nil {
+			return err
+		}
+
+		var f int
+		switch string(key.Raw) {
+		case "\"Note\"":
+			f = 0
+		case "\"shadow\"":
+			f = 1
+		default:
+			f = jo.MatchKey(key.Raw, jogenFieldsExtra)
+		}
+
+		switch f {
+		case 0:
+			err = jo.DecodeString(tok, &v.Note)
+			if err != nil {
+				return jo.AtField(err, "Note")
+			}
+		case 1:
+			err = jo.DecodeString(tok, &v.Shadow)
+			if err != nil {
+				return jo.AtField(err, "Shadow")
+			}
+		default:
+			if err := t.Skip(tok); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+var jogenFieldsOrder = []string{"id", "created", "Note", "shadow", "customer", "status", "total", "temp", "level", "count", "paid", "items", "tags", "attrs", "scores", "parent", "upper", "when", "any", "raw", "blob", "fixed", "nested", "Untagged"}
+
+// DecodeJO decodes the JSON value starting with tok into v, reading any
+// further tokens from t.
+func (v *Order) DecodeJO(t *jo.Tokenizer, tok jo.Token) error {
+	if tok.Kind == jo.NullStart {
+		return nil
+	}
+	if tok.Kind != jo.ObjectStart {
+		return jo.Mismatch[Order](tok)
+	}
+
+	for {
+		key, err := t.Next()
+		if err != nil {
+			return err
+		}
+		if key.Kind == jo.ObjectEnd {
+			return nil
+		}
+
+		tok, err := t.Next()
+		if err != nil {
+			return err
+		}
+
+		var f int
+		switch string(key.Raw) {
+		case "\"id\"":
+			f = 0
+		case "\"created\"":
+			f = 1
+		case "\"Note\"":
+			f = 2
+		case "\"shadow\"":
+			f = 3
+		case "\"customer\"":
+			f = 4
+		case "\"status\"":
+			f = 5
+		case "\"total\"":
+			f = 6
+		case "\"temp\"":
+			f = 7
+		case "\"level\"":
+			f = 8
+		case "\"count\"":
+			f = 9
+		case "\"paid\"":
+			f = 10
+		case "\"items\"":
+			f = 11
+		case "\"tags\"":
+			f = 12
+		case "\"attrs\"":
+			f = 13
+		case "\"scores\"":
+			f = 14
+		case "\"parent\"":
+			f = 15
+		case "\"upper\"":
+			f = 16
+		case "\"when\"":
+			f = 17
+		case "\"any\"":
+			f = 18
+		case "\"raw\"":
+			f = 19
+		case "\"blob\"":
+			f = 20
+		case "\"fixed\"":
+			f = 21
+		case "\"nested\"":
+			f = 22
+		case "\"Untagged\"":
+			f = 23
+		default:
+			f = jo.MatchKey(key.Raw, jogenFieldsOrder)
+		}
+
+		switch f {
+		case 0:
+			err = jo.DecodeInt(tok, &v.Base.ID)
+			if err != nil {
+				return jo.AtField(err, "ID")
+			}
+		case 1:
+			err = jo.DecodeString(tok, &v.Base.Created)
+			if err != nil {
+				return jo.AtField(err, "Created")
+			}
+		case 2:
+			if v.Extra == nil {
+				v.Extra = new(Extra)
+			}
+			err = jo.DecodeString(tok, &v.Extra.Note)
+			if err != nil {
+				return jo.AtField(err, "Note")
+			}
+		case 3:
+			err = jo.DecodeInt(tok, &v.Shadow)
+			if err != nil {
+				return jo.AtField(err, "Shadow")
+			}
+		case 4:
+			err = jo.DecodeString(tok, &v.Customer)
+			if err != nil {
+				return jo.AtField(err, "Customer")
+			}
+		case 5:
+			err = jo.DecodeString(tok, &v.Status)
+			if err != nil {
+				return jo.AtField(err, "Status")
+			}
+		case 6:
+			err = jo.DecodeFloat(tok, &v.Total)
+			if err != nil {
+				return jo.AtField(err, "Total")
+			}
+		case 7:
+			err = jo.DecodeFloat(tok, &v.Temp)
+			if err != nil {
+				return jo.AtField(err, "Temp")
+			}
+		case 8:
+			err = jo.DecodeInt(tok, &v.Level)
+			if err != nil {
+				return jo.AtField(err, "Level")
+			}
+		case 9:
+			err = jo.DecodeQuoted[uint16](tok, func(tok jo.Token) error {
+				return jo.DecodeUint(tok, &v.Count)
+			})
+			if err != nil {
+				return jo.AtField(err, "Count")
+			}
+		case 10:
+			err = jo.DecodeBool(tok, &v.Paid)
+			if err != nil {
+				return jo.AtField(err, "Paid")
+			}
+		case 11:
+			err = jogenDecode0(t, tok, &v.Items)
+			if err != nil {
+				return jo.AtField(err, "Items")
+			}
+		case 12:
+			err = jogenDecode1(t, tok, &v.Tags)
+			if err != nil {
+				return jo.AtField(err, "Tags")
+			}
+		case 13:
+			err = jogenDecode2(t, tok, &v.Attrs)
+			if err != nil {
+				return jo.AtField(err, "Attrs")
+			}
+		case 14:
+			err = jogenDecode5(t, tok, &v.Scores)
+			if err != nil {
+				return jo.AtField(err, "Scores")
+			}
+		case 15:
+			err = jogenDecode6(t, tok, &v.Parent)
+			if err != nil {
+				return jo.AtField(err, "Parent")
+			}
+		case 16:
+			err = jo.DecodeWith[Upper](t, tok, (&v.Upper).UnmarshalJSON)
+			if err != nil {
+				return jo.AtField(err, "Upper")
+			}
+		case 17:
+			err = jo.DecodeValue(t, tok, &v.When)
+			if err != nil {
+				return jo.AtField(err, "When")
+			}
+		case 18:
+			err = jo.DecodeValue(t, tok, &v.Any)
+			if err != nil {
+				return jo.AtField(err, "Any")
+			}
+		case 19:
+			err = jo.DecodeValue(t, tok, &v.Raw)
+			if err != nil {
+				return jo.AtField(err, "Raw")
+			}
+		case 20:
+			err = jo.DecodeValue(t, tok, &v.Blob)
+			if err != nil {
+				return jo.AtField(err, "Blob")
+			}
+		case 21:
+			err = jo.DecodeValue(t, tok, &v.Fixed)
+			if err != nil {
+				return jo.AtField(err, "Fixed")
+			}
+		case 22:
+			err = jogenDecode7(t, tok, &v.Nested)
+			if err != nil {
+				return jo.AtField(err, "Nested")
+			}
+		case 23:
+			err = jo.DecodeString(tok, &v.Untagged)
+			if err != nil {
+				return jo.AtField(err, "Untagged")
+			}
+		default:
+			if err := t.Skip(tok); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+var jogenFieldsItem = []string{"sku", "qty", "price", "Discount", "weight"}
+
+// DecodeJO decodes the JSON value starting with tok into v, reading any
+// further tokens from t.
+func (v *Item) DecodeJO(t *jo.Tokenizer, tok jo.Token) error {
+	if tok.Kind == jo.NullStart {
+		return nil
+	}
+	if tok.Kind != jo.ObjectStart {
+		return jo.Mismatch[Item](tok)
+	}
+
+	for {
+		key, err := t.Next()
+		if err != nil {
+			return err
+		}
+		if key.Kind == jo.ObjectEnd {
+			return nil
+		}
+
+		tok, err := t.Next()
+		if err != nil {
+			return err
+		}
+
+		var f int
+		switch string(key.Raw) {
+		case "\"sku\"":
+			f = 0
+		case "\"qty\"":
+			f = 1
+		case "\"price\"":
+			f = 2
+		case "\"Discount\"":
+			f = 3
+		case "\"weight\"":
+			f = 4
+		default:
+			f = jo.MatchKey(key.Raw, jogenFieldsItem)
+		}
+
+		switch f {
+		case 0:
+			err = jo.DecodeString(tok, &v.SKU)
+			if err != nil {
+				return jo.AtField(err, "SKU")
+			}
+		case 1:
+			err = jo.DecodeInt(tok, &v.Qty)
+			if err != nil {
+				return jo.AtField(err, "Qty")
+			}
+		case 2:
+			err = jogenDecode8(t, tok, &v.Price)
+			if err != nil {
+				return jo.AtField(err, "Price")
+			}
+		case 3:
+			err = jo.DecodeQuoted[float64](tok, func(tok jo.Token) error {
+				return jo.DecodeFloat(tok, &v.Discount)
+			})
+			if err != nil {
+				return jo.AtField(err, "Discount")
+			}
+		case 4:
+			err = jo.DecodeFloat(tok, &v.Weight)
+			if err != nil {
+				return jo.AtField(err, "Weight")
+			}
+		default:
+			if err := t.Skip(tok); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func jogenDecode0(t *jo.Tokenizer, tok jo.Token, p *[]Item) error {
+	if tok.Kind == jo.NullStart {
+		*p = nil
+		return nil
+	}
+	if tok.Kind != jo.ArrayStart {
+		return jo.Mismatch[[]Item](tok)
+	}
+
+	s := (*p)[:0]
+	for i := 0; ; i++ {
+		tok, err := t.Next()
+		if err != nil {
+			return err
+		}
+		if tok.Kind == jo.ArrayEnd {
+			break
+		}
+
+		var zero Item
+		s = append(s, zero)
+		if err := (&s[i]).DecodeJO(t, tok); err != nil {
+			*p = s
+			return jo.AtIndex(err, i)
+		}
+	}
+
+	if len(s) == 0 {
+		s = []Item{}
+	}
+	*p = s
+	return nil
+}
+
+func jogenDecode1(t *jo.Tokenizer, tok jo.Token, p *[]string) error {
+	if tok.Kind == jo.NullStart {
+		*p = nil
+		return nil
+	}
+	if tok.Kind != jo.ArrayStart {
+		return jo.Mismatch[[]string](tok)
+	}
+
+	s := (*p)[:0]
+	for i := 0; ; i++ {
+		tok, err := t.Next()
+		if err != nil {
+			return err
+		}
+		if tok.Kind == jo.ArrayEnd {
+			break
+		}
+
+		var zero string
+		s = append(s, zero)
+		if err := jo.DecodeString(tok, &s[i]); err != nil {
+			*p = s
+			return jo.AtIndex(err, i)
+		}
+	}
+
+	if len(s) == 0 {
+		s = []string{}
+	}
+	*p = s
+	return nil
+}
+
+func jogenDecode2(t *jo.Tokenizer, tok jo.Token, p *map[string]string) error {
+	if tok.Kind == jo.NullStart {
+		*p = nil
+		return nil
+	}
+	if tok.Kind != jo.ObjectStart {
+		return jo.Mismatch[map[string]string](tok)
+	}
+
+	if *p == nil {
+		*p = make(map[string]string)
+	}
+
+	for {
+		key, err := t.Next()
+		if err != nil {
+			return err
+		}
+		if key.Kind == jo.ObjectEnd {
+			return nil
+		}
+
+		tok, err := t.Next()
+		if err != nil {
+			return err
+		}
+
+		k := jo.Unquote(key.Raw)
+
+		var e string
+		if err := jo.DecodeString(tok, &e); err != nil {
+			return jo.AtKey(err, k)
+		}
+		(*p)[k] = e
+	}
+}
+
+func jogenDecode3(t *jo.Tokenizer, tok jo.Token, p **int) error {
+	if tok.Kind == jo.NullStart {
+		*p = nil
+		return nil
+	}
+	if *p == nil {
+		*p = new(int)
+	}
+	return jo.DecodeInt(tok, *p)
+}
+
+func jogenDecode4(t *jo.Tokenizer, tok jo.Token, p *[]*int) error {
+	if tok.Kind == jo.NullStart {
+		*p = nil
+		return nil
+	}
+	if tok.Kind != jo.ArrayStart {
+		return jo.Mismatch[[]*int](tok)
+	}
+
+	s := (*p)[:0]
+	for i := 0; ; i++ {
+		tok, err := t.Next()
+		if err != nil {
+			return err
+		}
+		if tok.Kind == jo.ArrayEnd {
+			break
+		}
+
+		var zero *int
+		s = append(s, zero)
+		if err := jogenDecode3(t, tok, &s[i]); err != nil {
+			*p = s
+			return jo.AtIndex(err, i)
+		}
+	}
+
+	if len(s) == 0 {
+		s = []*int{}
+	}
+	*p = s
+	return nil
+}
+
+func jogenDecode5(t *jo.Tokenizer, tok jo.Token, p *map[string][]*int) error {
+	if tok.Kind == jo.NullStart {
+		*p = nil
+		return nil
+	}
+	if tok.Kind != jo.ObjectStart {
+		return jo.Mismatch[map[string][]*int](tok)
+	}
+
+	if *p == nil {
+		*p = make(map[string][]*int)
+	}
+
+	for {
+		key, err := t.Next()
+		if err != nil {
+			return err
+		}
+		if key.Kind == jo.ObjectEnd {
+			return nil
+		}
+
+		tok, err := t.Next()
+		if err != nil {
+			return err
+		}
+
+		k := jo.Unquote(key.Raw)
+
+		var e []*int
+		if err := jogenDecode4(t, tok, &e); err != nil {
+			return jo.AtKey(err, k)
+		}
+		(*p)[k] = e
+	}
+}
+
+func jogenDecode6(t *jo.Tokenizer, tok jo.Token, p **Order) error {
+	if tok.Kind == jo.NullStart {
+		*p = nil
+		return nil
+	}
+	if *p == nil {
+		*p = new(Order)
+	}
+	return (*p).DecodeJO(t, tok)
+}
+
+func jogenDecode7(t *jo.Tokenizer, tok jo.Token, p *[][]Item) error {
+	if tok.Kind == jo.NullStart {
+		*p = nil
+		return nil
+	}
+	if tok.Kind != jo.ArrayStart {
+		return jo.Mismatch[[][]Item](tok)
+	}
+
+	s := (*p)[:0]
+	for i := 0; ; i++ {
+		tok, err := t.Next()
+		if err != nil {
+			return err
+		}
+		if tok.Kind == jo.ArrayEnd {
+			break
+		}
+
+		var zero []Item
+		s = append(s, zero)
+		if err := jogenDecode0(t, tok, &s[i]); err != nil {
+			*p = s
+			return jo.AtIndex(err, i)
+		}
+	}
+
+	if len(s) == 0 {
+		s = [][]Item{}
+	}
+	*p = s
+	return nil
+}
+
+func jogenDecode8(t *jo.Tokenizer, tok jo.Token, p **float32) error {
+	if tok.Kind == jo.NullStart {
+		*p = nil
+		return nil
+	}
+	if *p == nil {
+		*p = new(float32)
+	}
+	return jo.DecodeFloat(tok, *p)
+}
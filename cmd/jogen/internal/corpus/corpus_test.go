@@ -0,0 +1,99 @@
+package corpus
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/erkl/jo"
+)
+
+var corpusTests = []string{
+	`{}`,
+	`null`,
+	`{"id": 1, "created": "today", "shadow": 2, "Note": "n", "customer": "c", "status": "open"}`,
+	`{"total": 1.5, "temp": -40, "level": 3, "count": "12", "paid": true, "untagged": "x", "Skipped": "no", "internal": "no"}`,
+	`{"items": [{"sku": "a", "qty": 2, "price": 9.5, "Discount": "0.25"}, {"SKU": "b", "price": null}], "tags": ["x", "y"]}`,
+	`{"attrs": {"k": "v", "k2": null}, "scores": {"a": [1, null, 3], "b": []}, "nested": [[{"qty": 1}], [], null]}`,
+	`{"parent": {"customer": "p", "parent": {"id": 7}}, "upper": "shout", "when": "2010-08-02T21:27:44Z"}`,
+	`{"any": {"k": [1, "two", true, null]}, "raw": [1, {"a": 2}], "blob": "aGVsbG8=", "fixed": [1, 2, 3]}`,
+	`{"unknown": {"deep": [1, [2, {"x": null}]]}, "ID": 5, "CUSTOMER": "folded"}`,
+	`{"items": null, "tags": [], "attrs": {}, "parent": null}`,
+	`{"count": null, "paid": null, "items": [null]}`,
+	`{"customer": "é😀\ud800"}`,
+	`{"items": [{"price": 16777217, "weight": 16777217}, {"price": 1e-50, "weight": -1e-50}]}`,
+
+	// Type errors.
+	`[]`,
+	`{"id": "1"}`,
+	`{"level": 300}`,
+	`{"count": 12}`,
+	`{"count": "x"}`,
+	`{"count": "-1"}`,
+	`{"paid": 1}`,
+	`{"items": [{"qty": 1}, {"qty": 1.5}]}`,
+	`{"items": [{"Discount": "1", "price": "x"}]}`,
+	`{"items": [{"price": 3.5e38}]}`,
+	`{"items": [{"weight": -3.5e38}]}`,
+	`{"attrs": {"k": 1}}`,
+	`{"scores": {"k": [1, "2"]}}`,
+	`{"parent": {"parent": {"total": false}}}`,
+	`{"upper": 5}`,
+	`{"when": "yesterday"}`,
+	`{"nested": [[], [{"sku": []}]]}`,
+	`{"Note": 1}`,
+
+	// Syntax errors.
+	``,
+	`{"id": 1`,
+	`{"id" 1}`,
+	`{"tags": ["a",]}`,
+	`{} {}`,
+	`{"items": [{"qty": 1}}`,
+}
+
+func TestCorpus(t *testing.T) {
+	for _, in := range corpusTests {
+		var want, got Order
+
+		wantErr := jo.Unmarshal([]byte(in), &want)
+		err := jo.UnmarshalWith([]byte(in), got.DecodeJO)
+
+		if (err == nil) != (wantErr == nil) {
+			t.Errorf("DecodeJO(%#q):", in)
+			t.Errorf("  got  error %v", err)
+			t.Errorf("  want error %v", wantErr)
+			continue
+		}
+
+		if err != nil {
+			checkError(t, in, err, wantErr)
+		} else if !reflect.DeepEqual(got, want) {
+			t.Errorf("DecodeJO(%#q):", in)
+			t.Errorf("  got  %+v", got)
+			t.Errorf("  want %+v", want)
+		}
+	}
+}
+
+func checkError(t *testing.T, in string, err, wantErr error) {
+	var uerr, wantUerr *jo.UnmarshalError
+	var serr, wantSerr *jo.SyntaxError
+
+	switch {
+	case errors.As(wantErr, &wantUerr):
+		if !errors.As(err, &uerr) {
+			t.Errorf("DecodeJO(%#q): got error %v, want %v", in, err, wantErr)
+		} else if uerr.Offset != wantUerr.Offset || uerr.Path != wantUerr.Path || uerr.Type != wantUerr.Type {
+			t.Errorf("DecodeJO(%#q): got error at %d, %q (%v), want %d, %q (%v)", in,
+				uerr.Offset, uerr.Path, uerr.Type, wantUerr.Offset, wantUerr.Path, wantUerr.Type)
+		}
+
+	case errors.As(wantErr, &wantSerr):
+		if !errors.As(err, &serr) {
+			t.Errorf("DecodeJO(%#q): got error %v, want %v", in, err, wantErr)
+		} else if serr.Offset != wantSerr.Offset {
+			t.Errorf("DecodeJO(%#q): got error at %d, want %d", in, serr.Offset, wantSerr.Offset)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Package corpus holds types for verifying the code generated by jogen
+// against jo.Unmarshal.
+package corpus
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+//go:generate go run github.com/erkl/jo/cmd/jogen -json=false -output corpus_jogen.go types.go
+
+type Celsius float64
+
+type Grams float32
+
+type Status string
+
+type Level int8
+
+// Upper decodes itself, upper-casing a JSON string.
+type Upper string
+
+func (u *Upper) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*u = Upper(strings.ToUpper(s))
+	return nil
+}
+
+type Base struct {
+	ID      int64  `json:"id"`
+	Created string `json:"created,omitempty"`
+	Shadow  string `json:"shadow"`
+}
+
+type Extra struct {
+	Note   string
+	Shadow string `json:"shadow"`
+}
+
+type Order struct {
+	Base
+	*Extra
+
+	Shadow   int               `json:"shadow"`
+	Customer string            `json:"customer"`
+	Status   Status            `json:"status"`
+	Total    float64           `json:"total"`
+	Temp     Celsius           `json:"temp"`
+	Level    Level             `json:"level"`
+	Count    uint16            `json:"count,string"`
+	Paid     bool              `json:"paid"`
+	Items    []Item            `json:"items"`
+	Tags     []string          `json:"tags"`
+	Attrs    map[string]string `json:"attrs"`
+	Scores   map[string][]*int `json:"scores"`
+	Parent   *Order            `json:"parent"`
+	Upper    Upper             `json:"upper"`
+	When     time.Time         `json:"when"`
+	Any      any               `json:"any"`
+	Raw      json.RawMessage   `json:"raw"`
+	Blob     []byte            `json:"blob"`
+	Fixed    [2]int            `json:"fixed"`
+	Nested   [][]Item          `json:"nested"`
+	Skipped  string            `json:"-"`
+	Untagged string
+	internal string
+}
+
+type Item struct {
+	SKU      string   `json:"sku"`
+	Qty      int      `json:"qty"`
+	Price    *float32 `json:"price"`
+	Discount float64  `json:",string"`
+	Weight   Grams    `json:"weight"`
+}
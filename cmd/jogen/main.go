@@ -0,0 +1,147 @@
+// Command jogen generates JSON decoding methods for Go struct types, which
+// read straight from a jo.Tokenizer rather than relying on reflection.
+//
+// It is meant to be invoked by go generate:
+//
+//	//go:generate go run github.com/erkl/jo/cmd/jogen -type=Order,Item
+//
+// For each struct type T, jogen emits a method
+//
+//	func (v *T) DecodeJO(t *jo.Tokenizer, tok jo.Token) error
+//
+// which decodes the value starting with tok, and, unless -json=false is
+// given, an UnmarshalJSON method built on top of it. Object keys are matched
+// by switching on their raw bytes, falling back to the case-insensitive
+// matching of encoding/json. The generated code follows the same rules as
+// jo.Unmarshal, except that decoding stops at the first error. Field types
+// which can't be handled directly, such as types from other packages, are
+// decoded using jo.Unmarshal.
+//
+// Usage:
+//
+//	jogen [flags] [file.go ...]
+//
+// Types are looked up among all Go files in the package of the given files,
+// which default to $GOFILE. The flags are:
+//
+//	-type list
+//		comma-separated list of types to generate methods for; defaults
+//		to all struct types declared in the given files
+//	-output file
+//		output file name; defaults to <first file>_jogen.go
+//	-json
+//		whether to generate UnmarshalJSON methods (default true)
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	var (
+		typeNames = flag.String("type", "", "comma-separated list of type names")
+		output    = flag.String("output", "", "output file name")
+		json      = flag.Bool("json", true, "generate UnmarshalJSON methods")
+	)
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: jogen [flags] [file.go ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	files := flag.Args()
+	if len(files) == 0 {
+		if gofile := os.Getenv("GOFILE"); gofile != "" {
+			files = []string{gofile}
+		} else {
+			flag.Usage()
+			os.Exit(2)
+		}
+	}
+
+	out := *output
+	if out == "" {
+		out = strings.TrimSuffix(files[0], ".go") + "_jogen.go"
+	}
+
+	var types []string
+	if *typeNames != "" {
+		types = strings.Split(*typeNames, ",")
+	}
+
+	src, err := run(files, types, out, *json)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "jogen: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(out, src, 0666); err != nil {
+		fmt.Fprintf(os.Stderr, "jogen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run generates code for the given types, or all struct types declared in
+// files, and returns it. The output file itself is ignored when looking up
+// types.
+func run(files, types []string, output string, json bool) ([]byte, error) {
+	fset := token.NewFileSet()
+	dir := filepath.Dir(files[0])
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg []*ast.File
+	var inputs []*ast.File
+
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") || sameFile(path, output) {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		pkg = append(pkg, f)
+
+		for _, name := range files {
+			if sameFile(path, name) {
+				inputs = append(inputs, f)
+			}
+		}
+	}
+
+	if len(inputs) != len(files) {
+		return nil, fmt.Errorf("input files must all belong to the package in %s", dir)
+	}
+
+	g := newGenerator(pkg)
+	g.json = json
+	g.targets = types
+
+	if g.targets == nil {
+		for _, f := range inputs {
+			g.targets = append(g.targets, structTypes(f)...)
+		}
+	}
+
+	return g.generate()
+}
+
+// sameFile reports whether two paths refer to the same file.
+func sameFile(a, b string) bool {
+	a, _ = filepath.Abs(a)
+	b, _ = filepath.Abs(b)
+	return a == b
+}
@@ -0,0 +1,130 @@
+package jo
+
+// Bytes tried, in order, when completing truncated input. Closing delimiters
+// come first so that containers are closed as early as possible, followed by
+// the start of a null value where one is missing, the quote which starts a
+// missing key, and then the bytes needed to finish partial literals, numbers
+// and escapes.
+var completionBytes = []byte{']', '}', 'n', '"', ':', '0', 'r', 'u', 'e', 'a', 'l', 's'}
+
+// Complete returns a minimal suffix which turns the input scanned so far into
+// a complete JSON value. The Scanner itself is left unchanged.
+//
+// Open strings are closed, partial literals such as "tru" finished, partial
+// numbers given the digits they lack, and open containers closed in order.
+// Input which is empty, ends right after a ',' or ':', or ends in the middle
+// of an object key can't be completed without inventing a key or value, and
+// since a suffix can't drop anything, Complete returns nil for it, as it
+// does once the Scanner has encountered an error. Repair drops such dangling
+// input instead, and CompleteWithPlaceholders fills it in.
+func (s *Scanner) Complete() []byte {
+	return s.complete(false)
+}
+
+// CompleteWithPlaceholders is like Complete, but completes input which ends
+// right after a ',' or ':', or in the middle of an object key, with an empty
+// key and null values as placeholders, and empty input with null. It only
+// returns nil once the Scanner has encountered an error.
+func (s *Scanner) CompleteWithPlaceholders() []byte {
+	return s.complete(true)
+}
+
+// complete returns the completion of the input scanned so far, or nil if it
+// would have to start any values or keys of its own and placeholders isn't
+// set.
+func (s *Scanner) complete(placeholders bool) []byte {
+	if s.err != nil {
+		return nil
+	}
+
+	probe := NewScanner()
+	next := NewScanner()
+	probe.copyFrom(s)
+
+	suffix := []byte{}
+
+	// Each step either finishes a token or closes a container, so the
+	// number of steps needed is bounded by the nesting depth.
+	for steps := 0; steps < 16+8*len(s.stack); steps++ {
+		next.copyFrom(probe)
+		if next.End() != Error {
+			return suffix
+		}
+		next.copyFrom(probe)
+
+		// Inside a string, where anything but control characters goes,
+		// only a quote makes progress.
+		candidates := completionBytes
+		if ev := next.Scan('x'); ev != Error && ev&Start == 0 {
+			candidates = []byte{'"'}
+		}
+
+		for _, c := range candidates {
+			next.copyFrom(probe)
+
+			ev := next.Scan(c)
+			if ev == Error || c == 'n' && ev&NullStart == 0 {
+				continue
+			}
+			if ev&Start != 0 && !placeholders {
+				return nil
+			}
+
+			suffix = append(suffix, c)
+			probe, next = next, probe
+			break
+		}
+	}
+
+	// Not reached for any state the Scanner can be in.
+	return nil
+}
+
+// Repair completes data, which must be the beginning of a JSON value, such as
+// the output of a stream which was cut short. Input which Complete can't
+// complete has its trailing ',' or ':', and any partial object key, dropped
+// rather than keys and values invented to go with them. Empty input is
+// completed with null.
+//
+// If data isn't the beginning of any valid JSON value, the returned error is
+// a *SyntaxError.
+func Repair(data []byte) ([]byte, error) {
+	s := NewScanner()
+
+	// The length of the longest prefix which ends after a complete value,
+	// or right after the start of a container.
+	safe := 0
+
+	for i, c := range data {
+		ev := s.Scan(c)
+		if ev == Error {
+			return nil, s.syntaxError(int64(i))
+		}
+
+		if ev&(End&^KeyEnd) != 0 {
+			safe = i
+		}
+		if ev&(ObjectStart|ArrayStart) != 0 {
+			safe = i + 1
+		}
+	}
+
+	suffix := s.Complete()
+	if suffix == nil && safe > 0 {
+		data = data[:safe]
+
+		s.Reset()
+		for _, c := range data {
+			s.Scan(c)
+		}
+		suffix = s.Complete()
+	}
+	if suffix == nil {
+		// Nothing but whitespace has been read.
+		suffix = s.CompleteWithPlaceholders()
+	}
+
+	out := make([]byte, 0, len(data)+len(suffix))
+	out = append(out, data...)
+	return append(out, suffix...), nil
+}
@@ -0,0 +1,162 @@
+package jo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func ExampleRepair() {
+	// A response cut off mid-stream.
+	partial := []byte(`{"id": 7, "tags": ["a", "b`)
+
+	out, err := Repair(partial)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+	// Output:
+	// {"id": 7, "tags": ["a", "b"]}
+}
+
+var completeTests = []struct {
+	in, suffix, repaired string
+}{
+	{`1`, ``, `1`},
+	{`[1, 2] `, ``, `[1, 2] `},
+	{`"ab`, `"`, `"ab"`},
+	{`"ab\`, `""`, `"ab\""`},
+	{`"\u12`, `00"`, `"\u1200"`},
+	{`t`, `rue`, `true`},
+	{`[tru`, `e]`, `[true]`},
+	{`{"a":f`, `alse}`, `{"a":false}`},
+	{`[nu`, `ll]`, `[null]`},
+	{`-`, `0`, `-0`},
+	{`[1.`, `0]`, `[1.0]`},
+	{`[1e`, `0]`, `[1e0]`},
+	{`[1e+`, `0]`, `[1e+0]`},
+	{`[12`, `]`, `[12]`},
+	{`[`, `]`, `[]`},
+	{`[[{`, `}]]`, `[[{}]]`},
+	{`{"a":[1,{"b":"c`, `"}]}`, `{"a":[1,{"b":"c"}]}`},
+	{`{"a":[1,{"b":"c"`, `}]}`, `{"a":[1,{"b":"c"}]}`},
+}
+
+// Input which can only be completed by inventing keys or values.
+var danglingTests = []struct {
+	in, placeholders, repaired string
+}{
+	{``, `null`, `null`},
+	{`  `, `null`, `  null`},
+	{`[1,`, `null]`, `[1]`},
+	{`[1, `, `null]`, `[1]`},
+	{`[[1],`, `null]`, `[[1]]`},
+	{`{"a"`, `:null}`, `{}`},
+	{`{"a":`, `null}`, `{}`},
+	{`{"a": `, `null}`, `{}`},
+	{`{"a":1,`, `"":null}`, `{"a":1}`},
+	{`{"a":1,"b`, `":null}`, `{"a":1}`},
+	{`{"a":{"b":1},"c":`, `null}`, `{"a":{"b":1}}`},
+	{`[{"a":`, `null}]`, `[{}]`},
+}
+
+func TestComplete(t *testing.T) {
+	for _, test := range completeTests {
+		s := NewScanner()
+		for _, c := range []byte(test.in) {
+			if s.Scan(c) == Error {
+				t.Fatalf("%#q: %v", test.in, s.LastError())
+			}
+		}
+
+		if got := s.Complete(); got == nil || string(got) != test.suffix {
+			t.Errorf("Complete after %#q:", test.in)
+			t.Errorf("  got  %#q", got)
+			t.Errorf("  want %#q", test.suffix)
+		}
+		if got := s.CompleteWithPlaceholders(); string(got) != test.suffix {
+			t.Errorf("CompleteWithPlaceholders after %#q:", test.in)
+			t.Errorf("  got  %#q", got)
+			t.Errorf("  want %#q", test.suffix)
+		}
+
+		if got, err := Repair([]byte(test.in)); err != nil || string(got) != test.repaired {
+			t.Errorf("Repair(%#q):", test.in)
+			t.Errorf("  got  %#q, %v", got, err)
+			t.Errorf("  want %#q", test.repaired)
+		}
+	}
+}
+
+func TestCompleteDangling(t *testing.T) {
+	for _, test := range danglingTests {
+		s := NewScanner()
+		for _, c := range []byte(test.in) {
+			if s.Scan(c) == Error {
+				t.Fatalf("%#q: %v", test.in, s.LastError())
+			}
+		}
+
+		if got := s.Complete(); got != nil {
+			t.Errorf("Complete after %#q: got %#q, want nil", test.in, got)
+		}
+		if got := s.CompleteWithPlaceholders(); string(got) != test.placeholders {
+			t.Errorf("CompleteWithPlaceholders after %#q:", test.in)
+			t.Errorf("  got  %#q", got)
+			t.Errorf("  want %#q", test.placeholders)
+		}
+
+		if got, err := Repair([]byte(test.in)); err != nil || string(got) != test.repaired {
+			t.Errorf("Repair(%#q):", test.in)
+			t.Errorf("  got  %#q, %v", got, err)
+			t.Errorf("  want %#q", test.repaired)
+		}
+	}
+}
+
+// Every prefix of a valid document can be completed.
+func TestCompletePrefixes(t *testing.T) {
+	data := []byte(sample + `{"a":[-1.5e+10,"\"é",true,false,null,{}],"b":[[]]}`)
+
+	s := NewScanner()
+	for i := range data {
+		suffix := s.CompleteWithPlaceholders()
+		if err := Validate(append(data[:i:i], suffix...)); err != nil {
+			t.Fatalf("%#q + %#q: %v", data[max(0, i-20):i], suffix, err)
+		}
+		if c := s.Complete(); c != nil && string(c) != string(suffix) {
+			t.Fatalf("%#q: Complete returned %#q, want %#q or nil", data[max(0, i-20):i], c, suffix)
+		}
+
+		repaired, err := Repair(data[:i])
+		if err == nil {
+			err = Validate(repaired)
+		}
+		if err != nil {
+			t.Fatalf("Repair(%#q...): %v", data[max(0, i-20):i], err)
+		}
+
+		if s.Scan(data[i]) == Error {
+			// The sample is followed by a second value.
+			break
+		}
+	}
+}
+
+func TestCompleteErrors(t *testing.T) {
+	s := NewScanner()
+	s.Scan('[')
+	s.Scan('}')
+
+	if got := s.Complete(); got != nil {
+		t.Errorf("Complete after an error: got %#q, want nil", got)
+	}
+	if got := s.CompleteWithPlaceholders(); got != nil {
+		t.Errorf("CompleteWithPlaceholders after an error: got %#q, want nil", got)
+	}
+
+	_, err := Repair([]byte(`[1, }`))
+	if serr := (*SyntaxError)(nil); !errors.As(err, &serr) || serr.Offset != 4 {
+		t.Errorf("Repair: got %v, want a syntax error at offset 4", err)
+	}
+}
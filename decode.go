@@ -0,0 +1,877 @@
+package jo
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Unmarshal parses the JSON document in data and stores the result in the
+// value pointed to by v, following the same rules as encoding/json's
+// Unmarshal: struct fields are matched using `json` struct tags (including
+// the "string" option), embedded structs have their fields promoted, and
+// types implementing json.Unmarshaler or encoding.TextUnmarshaler decode
+// themselves.
+//
+// Decoding is driven directly by Scanner events, so on malformed input v
+// may already have been partially filled in by the time a *SyntaxError is
+// returned. When a JSON value can't be stored in the Go value at hand, it is
+// skipped and decoding carries on; the first such problem is then returned
+// as an *UnmarshalError.
+func Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+
+	d := &decodeState{data: data, t: NewBytesTokenizer(data)}
+	return d.decode(rv.Elem())
+}
+
+// Decode decodes the value into the Go value pointed to by dst, just like
+// Unmarshal would.
+func (v Value) Decode(dst any) error {
+	err := Unmarshal(v.Raw, dst)
+	if e, ok := err.(*UnmarshalError); ok {
+		e.Offset += v.Offset
+	}
+	return err
+}
+
+// An InvalidUnmarshalError describes an invalid argument passed to
+// Unmarshal. (The argument to Unmarshal must be a non-nil pointer.)
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "jo: Unmarshal(nil)"
+	}
+	if e.Type.Kind() != reflect.Pointer {
+		return "jo: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "jo: Unmarshal(nil " + e.Type.String() + ")"
+}
+
+// An UnmarshalError describes a JSON value which couldn't be stored in a Go
+// value.
+type UnmarshalError struct {
+	// Description of the JSON value, such as "string" or "number 1.5".
+	Value string
+
+	// Type of the Go value it couldn't be stored in.
+	Type reflect.Type
+
+	// Input offset of the JSON value.
+	Offset int64
+
+	// Go path of the value, relative to the value passed to Unmarshal,
+	// such as "Items[3].Name". Empty when referring to that value itself.
+	Path string
+
+	// Underlying error, such as one returned by a json.Unmarshaler.
+	Err error
+}
+
+func (e *UnmarshalError) Error() string {
+	var b strings.Builder
+
+	b.WriteString("jo: cannot unmarshal ")
+	b.WriteString(e.Value)
+	b.WriteString(" into Go value of type ")
+	b.WriteString(e.Type.String())
+	if e.Path != "" {
+		b.WriteString(" at ")
+		b.WriteString(e.Path)
+	}
+	b.WriteString(" (offset ")
+	b.WriteString(strconv.FormatInt(e.Offset, 10))
+	b.WriteString(")")
+	if e.Err != nil {
+		b.WriteString(": ")
+		b.WriteString(e.Err.Error())
+	}
+
+	return b.String()
+}
+
+func (e *UnmarshalError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	unmarshalerType     = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	numberType          = reflect.TypeFor[json.Number]()
+)
+
+// decodeState holds the state of a single call to Unmarshal.
+type decodeState struct {
+	data []byte
+	t    *Tokenizer
+
+	// Go path of the value being decoded.
+	path []pathElem
+
+	// First error which didn't stop decoding.
+	err error
+}
+
+// A pathElem is a single step in a Go path: a struct field, a slice or array
+// index, or a map key.
+type pathElem struct {
+	name  string
+	index int
+}
+
+const (
+	fieldElem = -1
+	keyElem   = -2
+)
+
+// decode decodes the document into v.
+func (d *decodeState) decode(v reflect.Value) error {
+	tok, err := d.t.Next()
+	if err != nil {
+		return err
+	}
+
+	if err := typeDecoder(v.Type())(d, tok, v); err != nil {
+		return err
+	}
+
+	// Make sure nothing but whitespace follows.
+	if _, err := d.t.Next(); err != io.EOF {
+		return err
+	}
+
+	return d.err
+}
+
+// next returns the next token.
+func (d *decodeState) next() (Token, error) {
+	tok, err := d.t.Next()
+	if err == io.EOF {
+		err = &SyntaxError{msg: "unexpected end of JSON input", Offset: int64(len(d.data))}
+	}
+	return tok, err
+}
+
+// skip skips past the remainder of the value starting with tok.
+func (d *decodeState) skip(tok Token) error {
+	return d.t.Skip(tok)
+}
+
+// saveError records err, unless an error has already been recorded.
+func (d *decodeState) saveError(tok Token, t reflect.Type, err error) {
+	if d.err != nil {
+		return
+	}
+
+	desc := kindName(tok.Kind)
+	if tok.Kind == NumberStart {
+		desc += " " + string(tok.Raw)
+	}
+
+	d.err = &UnmarshalError{
+		Value:  desc,
+		Type:   t,
+		Offset: tok.Offset,
+		Path:   d.pathString(),
+		Err:    err,
+	}
+}
+
+// mismatch records that the value starting with tok can't be stored in a
+// value of type t, and skips it.
+func (d *decodeState) mismatch(tok Token, t reflect.Type) error {
+	d.saveError(tok, t, nil)
+	return d.skip(tok)
+}
+
+// pathString renders the current Go path.
+func (d *decodeState) pathString() string {
+	var b strings.Builder
+
+	for _, e := range d.path {
+		switch e.index {
+		case fieldElem:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(e.name)
+		case keyElem:
+			b.WriteByte('[')
+			b.WriteString(strconv.Quote(e.name))
+			b.WriteByte(']')
+		default:
+			b.WriteByte('[')
+			b.WriteString(strconv.Itoa(e.index))
+			b.WriteByte(']')
+		}
+	}
+
+	return b.String()
+}
+
+// A decoderFunc decodes the value starting with tok into v. Only syntax and
+// I/O errors are returned, other errors are recorded using saveError.
+type decoderFunc func(d *decodeState, tok Token, v reflect.Value) error
+
+var decoderCache sync.Map // map[reflect.Type]decoderFunc
+
+// typeDecoder returns the decoderFunc for values of type t.
+func typeDecoder(t reflect.Type) decoderFunc {
+	if f, ok := decoderCache.Load(t); ok {
+		return f.(decoderFunc)
+	}
+
+	// Recursive types are dealt with by caching an indirect decoderFunc,
+	// which waits for the real one to be built before calling it.
+	var wg sync.WaitGroup
+	var f decoderFunc
+
+	wg.Add(1)
+	fi, loaded := decoderCache.LoadOrStore(t, decoderFunc(func(d *decodeState, tok Token, v reflect.Value) error {
+		wg.Wait()
+		return f(d, tok, v)
+	}))
+	if loaded {
+		return fi.(decoderFunc)
+	}
+
+	f = newTypeDecoder(t)
+	wg.Done()
+	decoderCache.Store(t, f)
+
+	return f
+}
+
+// newTypeDecoder builds a decoderFunc for values of type t.
+func newTypeDecoder(t reflect.Type) decoderFunc {
+	if t.Kind() != reflect.Pointer {
+		if reflect.PointerTo(t).Implements(unmarshalerType) {
+			return decodeUnmarshaler
+		}
+		if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+			return decodeTextUnmarshaler
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return decodeBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return decodeInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return decodeUint
+	case reflect.Float32, reflect.Float64:
+		return decodeFloat
+	case reflect.String:
+		if t == numberType {
+			return decodeNumber
+		}
+		return decodeString
+	case reflect.Interface:
+		return decodeInterface
+	case reflect.Struct:
+		return newStructDecoder(t)
+	case reflect.Map:
+		return newMapDecoder(t)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && !reflect.PointerTo(t.Elem()).Implements(unmarshalerType) && !reflect.PointerTo(t.Elem()).Implements(textUnmarshalerType) {
+			return decodeBytes
+		}
+		return newSliceDecoder(t)
+	case reflect.Array:
+		return newArrayDecoder(t)
+	case reflect.Pointer:
+		return newPointerDecoder(t)
+	}
+
+	return decodeUnsupported
+}
+
+func decodeUnmarshaler(d *decodeState, tok Token, v reflect.Value) error {
+	raw, err := d.t.RawValue(tok)
+	if err != nil {
+		return err
+	}
+
+	if err := v.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(raw); err != nil {
+		d.saveError(tok, v.Type(), err)
+	}
+
+	return nil
+}
+
+func decodeTextUnmarshaler(d *decodeState, tok Token, v reflect.Value) error {
+	if tok.Kind == NullStart {
+		return nil
+	}
+	if tok.Kind != StringStart {
+		return d.mismatch(tok, v.Type())
+	}
+
+	if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(unquote(nil, tok.Raw)); err != nil {
+		d.saveError(tok, v.Type(), err)
+	}
+
+	return nil
+}
+
+func decodeBool(d *decodeState, tok Token, v reflect.Value) error {
+	switch tok.Kind {
+	case NullStart:
+	case BoolStart:
+		v.SetBool(tok.Raw[0] == 't')
+	default:
+		return d.mismatch(tok, v.Type())
+	}
+	return nil
+}
+
+func decodeInt(d *decodeState, tok Token, v reflect.Value) error {
+	switch tok.Kind {
+	case NullStart:
+	case NumberStart:
+		n, err := strconv.ParseInt(string(tok.Raw), 10, 64)
+		if err != nil || v.OverflowInt(n) {
+			d.saveError(tok, v.Type(), nil)
+			return nil
+		}
+		v.SetInt(n)
+	default:
+		return d.mismatch(tok, v.Type())
+	}
+	return nil
+}
+
+func decodeUint(d *decodeState, tok Token, v reflect.Value) error {
+	switch tok.Kind {
+	case NullStart:
+	case NumberStart:
+		n, err := strconv.ParseUint(string(tok.Raw), 10, 64)
+		if err != nil || v.OverflowUint(n) {
+			d.saveError(tok, v.Type(), nil)
+			return nil
+		}
+		v.SetUint(n)
+	default:
+		return d.mismatch(tok, v.Type())
+	}
+	return nil
+}
+
+func decodeFloat(d *decodeState, tok Token, v reflect.Value) error {
+	switch tok.Kind {
+	case NullStart:
+	case NumberStart:
+		f, err := strconv.ParseFloat(string(tok.Raw), v.Type().Bits())
+		if err != nil || v.OverflowFloat(f) {
+			d.saveError(tok, v.Type(), nil)
+			return nil
+		}
+		v.SetFloat(f)
+	default:
+		return d.mismatch(tok, v.Type())
+	}
+	return nil
+}
+
+func decodeString(d *decodeState, tok Token, v reflect.Value) error {
+	switch tok.Kind {
+	case NullStart:
+	case StringStart:
+		v.SetString(string(unquote(nil, tok.Raw)))
+	default:
+		return d.mismatch(tok, v.Type())
+	}
+	return nil
+}
+
+func decodeNumber(d *decodeState, tok Token, v reflect.Value) error {
+	switch tok.Kind {
+	case NullStart:
+	case NumberStart:
+		v.SetString(string(tok.Raw))
+	case StringStart:
+		s := unquote(nil, tok.Raw)
+		if len(s) > 0 && !validNumber(s) {
+			d.saveError(tok, v.Type(), nil)
+			return nil
+		}
+		v.SetString(string(s))
+	default:
+		return d.mismatch(tok, v.Type())
+	}
+	return nil
+}
+
+func decodeBytes(d *decodeState, tok Token, v reflect.Value) error {
+	switch tok.Kind {
+	case NullStart:
+		v.SetZero()
+	case StringStart:
+		s := unquote(nil, tok.Raw)
+		b := make([]byte, base64.StdEncoding.DecodedLen(len(s)))
+		n, err := base64.StdEncoding.Decode(b, s)
+		if err != nil {
+			d.saveError(tok, v.Type(), err)
+			return nil
+		}
+		v.SetBytes(b[:n])
+	default:
+		return d.mismatch(tok, v.Type())
+	}
+	return nil
+}
+
+func decodeInterface(d *decodeState, tok Token, v reflect.Value) error {
+	if tok.Kind == NullStart {
+		v.SetZero()
+		return nil
+	}
+
+	// Values already holding a non-nil pointer are decoded into.
+	if e := v.Elem(); !v.IsNil() && e.Kind() == reflect.Pointer && !e.IsNil() {
+		return typeDecoder(e.Type().Elem())(d, tok, e.Elem())
+	}
+
+	if v.NumMethod() != 0 {
+		return d.mismatch(tok, v.Type())
+	}
+
+	x, err := d.any(tok)
+	if err != nil {
+		return err
+	}
+	if x != nil {
+		v.Set(reflect.ValueOf(x))
+	}
+
+	return nil
+}
+
+// any decodes the value starting with tok into the Go value encoding/json
+// would use for an empty interface.
+func (d *decodeState) any(tok Token) (any, error) {
+	switch tok.Kind {
+	case ObjectStart:
+		m := make(map[string]any)
+		for {
+			key, err := d.next()
+			if err != nil {
+				return nil, err
+			}
+			if key.Kind == ObjectEnd {
+				return m, nil
+			}
+
+			k := string(unquote(nil, key.Raw))
+
+			tok, err := d.next()
+			if err != nil {
+				return nil, err
+			}
+
+			d.path = append(d.path, pathElem{k, keyElem})
+			x, err := d.any(tok)
+			d.path = d.path[:len(d.path)-1]
+
+			if err != nil {
+				return nil, err
+			}
+			m[k] = x
+		}
+
+	case ArrayStart:
+		a := make([]any, 0)
+		for {
+			tok, err := d.next()
+			if err != nil {
+				return nil, err
+			}
+			if tok.Kind == ArrayEnd {
+				return a, nil
+			}
+
+			d.path = append(d.path, pathElem{"", len(a)})
+			x, err := d.any(tok)
+			d.path = d.path[:len(d.path)-1]
+
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, x)
+		}
+
+	case StringStart:
+		return string(unquote(nil, tok.Raw)), nil
+
+	case NumberStart:
+		f, err := strconv.ParseFloat(string(tok.Raw), 64)
+		if err != nil {
+			d.saveError(tok, reflect.TypeFor[float64](), nil)
+		}
+		return f, nil
+
+	case BoolStart:
+		return tok.Raw[0] == 't', nil
+	}
+
+	return nil, nil
+}
+
+func decodeUnsupported(d *decodeState, tok Token, v reflect.Value) error {
+	return d.mismatch(tok, v.Type())
+}
+
+func newPointerDecoder(t reflect.Type) decoderFunc {
+	elem := typeDecoder(t.Elem())
+
+	return func(d *decodeState, tok Token, v reflect.Value) error {
+		if tok.Kind == NullStart {
+			v.SetZero()
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return elem(d, tok, v.Elem())
+	}
+}
+
+func newStructDecoder(t reflect.Type) decoderFunc {
+	fields := cachedFields(t)
+
+	decs := make([]decoderFunc, len(fields.list))
+	for i, f := range fields.list {
+		decs[i] = typeDecoder(f.typ)
+	}
+
+	return func(d *decodeState, tok Token, v reflect.Value) error {
+		if tok.Kind == NullStart {
+			return nil
+		}
+		if tok.Kind != ObjectStart {
+			return d.mismatch(tok, v.Type())
+		}
+
+		var buf []byte
+
+		for {
+			key, err := d.next()
+			if err != nil {
+				return err
+			}
+			if key.Kind == ObjectEnd {
+				return nil
+			}
+
+			buf = unquote(buf[:0], key.Raw)
+			i := fields.lookup(string(buf))
+
+			tok, err := d.next()
+			if err != nil {
+				return err
+			}
+
+			if i < 0 {
+				if err := d.skip(tok); err != nil {
+					return err
+				}
+				continue
+			}
+
+			f := &fields.list[i]
+			d.path = append(d.path, pathElem{f.goName, fieldElem})
+
+			if fv, ok := d.field(tok, v, f.index); !ok {
+				err = d.skip(tok)
+			} else if f.quoted {
+				err = d.quoted(tok, fv, decs[i])
+			} else {
+				err = decs[i](d, tok, fv)
+			}
+
+			d.path = d.path[:len(d.path)-1]
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// field returns the struct field with the given index sequence, allocating
+// embedded structs along the way.
+func (d *decodeState) field(tok Token, v reflect.Value, index []int) (reflect.Value, bool) {
+	for _, i := range index {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					d.saveError(tok, v.Type(), errEmbeddedPointer)
+					return v, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v, true
+}
+
+var errEmbeddedPointer = errors.New("cannot set embedded pointer to unexported struct")
+
+// quoted decodes a value from inside a JSON string, as requested using the
+// "string" struct tag option.
+func (d *decodeState) quoted(tok Token, v reflect.Value, dec decoderFunc) error {
+	if tok.Kind == NullStart {
+		return nil
+	}
+	if tok.Kind != StringStart {
+		return d.mismatch(tok, v.Type())
+	}
+
+	inner := unquote(nil, tok.Raw)
+	sub := &decodeState{data: inner, t: NewBytesTokenizer(inner)}
+
+	itok, err := sub.t.Next()
+	if err != nil || itok.Kind == ObjectStart || itok.Kind == ArrayStart {
+		d.saveError(tok, v.Type(), errInvalidQuoted)
+		return nil
+	}
+
+	if err := dec(sub, itok, v); err != nil {
+		d.saveError(tok, v.Type(), errInvalidQuoted)
+		return nil
+	}
+
+	if _, err := sub.t.Next(); err != io.EOF || sub.err != nil {
+		d.saveError(tok, v.Type(), errInvalidQuoted)
+	}
+
+	return nil
+}
+
+var errInvalidQuoted = errors.New("invalid use of ,string struct tag")
+
+func newMapDecoder(t reflect.Type) decoderFunc {
+	kt := t.Key()
+
+	switch kt.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		if !reflect.PointerTo(kt).Implements(textUnmarshalerType) {
+			return decodeUnsupported
+		}
+	}
+
+	elem := typeDecoder(t.Elem())
+
+	return func(d *decodeState, tok Token, v reflect.Value) error {
+		if tok.Kind == NullStart {
+			v.SetZero()
+			return nil
+		}
+		if tok.Kind != ObjectStart {
+			return d.mismatch(tok, v.Type())
+		}
+
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(t))
+		}
+
+		ev := reflect.New(t.Elem()).Elem()
+
+		for {
+			key, err := d.next()
+			if err != nil {
+				return err
+			}
+			if key.Kind == ObjectEnd {
+				return nil
+			}
+
+			k := string(unquote(nil, key.Raw))
+			kv, kerr := mapKey(kt, k)
+
+			tok, err := d.next()
+			if err != nil {
+				return err
+			}
+
+			if kerr != nil {
+				d.saveError(key, kt, kerr)
+				if err := d.skip(tok); err != nil {
+					return err
+				}
+				continue
+			}
+
+			ev.SetZero()
+			d.path = append(d.path, pathElem{k, keyElem})
+			err = elem(d, tok, ev)
+			d.path = d.path[:len(d.path)-1]
+
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(kv, ev)
+		}
+	}
+}
+
+// mapKey converts a decoded object key into a map key of type kt.
+func mapKey(kt reflect.Type, k string) (reflect.Value, error) {
+	if reflect.PointerTo(kt).Implements(textUnmarshalerType) {
+		kv := reflect.New(kt)
+		if err := kv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(k)); err != nil {
+			return kv, err
+		}
+		return kv.Elem(), nil
+	}
+
+	kv := reflect.New(kt).Elem()
+
+	switch kt.Kind() {
+	case reflect.String:
+		kv.SetString(k)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(k, 10, 64)
+		if err != nil || kv.OverflowInt(n) {
+			return kv, errInvalidKey
+		}
+		kv.SetInt(n)
+	default:
+		n, err := strconv.ParseUint(k, 10, 64)
+		if err != nil || kv.OverflowUint(n) {
+			return kv, errInvalidKey
+		}
+		kv.SetUint(n)
+	}
+
+	return kv, nil
+}
+
+var errInvalidKey = errors.New("invalid map key")
+
+func newSliceDecoder(t reflect.Type) decoderFunc {
+	elem := typeDecoder(t.Elem())
+
+	return func(d *decodeState, tok Token, v reflect.Value) error {
+		if tok.Kind == NullStart {
+			v.SetZero()
+			return nil
+		}
+		if tok.Kind != ArrayStart {
+			return d.mismatch(tok, v.Type())
+		}
+
+		i := 0
+		for ; ; i++ {
+			tok, err := d.next()
+			if err != nil {
+				return err
+			}
+			if tok.Kind == ArrayEnd {
+				break
+			}
+
+			if i >= v.Cap() {
+				v.Grow(1)
+			}
+			if i >= v.Len() {
+				v.SetLen(i + 1)
+			}
+
+			ev := v.Index(i)
+			ev.SetZero()
+
+			d.path = append(d.path, pathElem{"", i})
+			err = elem(d, tok, ev)
+			d.path = d.path[:len(d.path)-1]
+
+			if err != nil {
+				return err
+			}
+		}
+
+		if i == 0 {
+			v.Set(reflect.MakeSlice(t, 0, 0))
+		} else {
+			v.SetLen(i)
+		}
+
+		return nil
+	}
+}
+
+func newArrayDecoder(t reflect.Type) decoderFunc {
+	elem := typeDecoder(t.Elem())
+
+	return func(d *decodeState, tok Token, v reflect.Value) error {
+		if tok.Kind == NullStart {
+			return nil
+		}
+		if tok.Kind != ArrayStart {
+			return d.mismatch(tok, v.Type())
+		}
+
+		i := 0
+		for ; ; i++ {
+			tok, err := d.next()
+			if err != nil {
+				return err
+			}
+			if tok.Kind == ArrayEnd {
+				break
+			}
+
+			if i >= v.Len() {
+				if err := d.skip(tok); err != nil {
+					return err
+				}
+				continue
+			}
+
+			d.path = append(d.path, pathElem{"", i})
+			err = elem(d, tok, v.Index(i))
+			d.path = d.path[:len(d.path)-1]
+
+			if err != nil {
+				return err
+			}
+		}
+
+		for ; i < v.Len(); i++ {
+			v.Index(i).SetZero()
+		}
+
+		return nil
+	}
+}
+
+// validNumber reports whether b is a single numeric literal.
+func validNumber(b []byte) bool {
+	s := NewScanner()
+
+	for i, c := range b {
+		if ev := s.Scan(c); ev == Error || i == 0 && ev != NumberStart {
+			return false
+		}
+	}
+
+	return s.End() == NumberEnd
+}
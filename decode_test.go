@@ -0,0 +1,206 @@
+package jo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type decodeInner struct {
+	A int    `json:"a"`
+	B string `json:"b,omitempty"`
+}
+
+type DecodeEmbedded struct {
+	E1 string
+	E2 int `json:"e2"`
+}
+
+type decodeHidden struct {
+	H string
+}
+
+type decodeOuter struct {
+	DecodeEmbedded
+	*decodeHidden
+
+	Name    string            `json:"name"`
+	Count   int64             `json:"count,string"`
+	Ratio   float32           `json:"ratio"`
+	Flag    bool              `json:"flag,string"`
+	Ignored string            `json:"-"`
+	Inner   decodeInner       `json:"inner"`
+	Ptr     *decodeInner      `json:"ptr"`
+	List    []decodeInner     `json:"list"`
+	Arr     [2]int            `json:"arr"`
+	Map     map[string]int    `json:"map"`
+	IntMap  map[int]string    `json:"int_map"`
+	Any     any               `json:"any"`
+	Raw     json.RawMessage   `json:"raw"`
+	Time    time.Time         `json:"time"`
+	Num     json.Number       `json:"num"`
+	Bytes   []byte            `json:"bytes"`
+	Nested  map[string][]*int `json:"nested"`
+	Untag   string
+	private string
+}
+
+type decodeRecursive struct {
+	Value int              `json:"value"`
+	Next  *decodeRecursive `json:"next"`
+	Kids  []decodeRecursive
+}
+
+var unmarshalTests = []struct {
+	in  string
+	ptr func() any
+}{
+	{`{"name": "x", "count": "12", "ratio": 1.5, "flag": "true", "Ignored": "no", "inner": {"a": 1, "b": "c"}}`, func() any { return new(decodeOuter) }},
+	{`{"ptr": {"a": 2}, "list": [{"a": 3}, {"b": "d"}], "arr": [1, 2, 3], "map": {"x": 1, "y": 2}, "int_map": {"-1": "a", "2": "b"}}`, func() any { return new(decodeOuter) }},
+	{`{"any": {"k": [1, "two", true, null, {"x": 1.5}]}, "raw": [1, {"a": 2}], "time": "2010-08-02T21:27:44Z", "num": 1.000000000000000001}`, func() any { return new(decodeOuter) }},
+	{`{"bytes": "aGVsbG8=", "nested": {"a": [1, null, 3]}, "UNTAG": "case", "E1": "e", "e2": 2, "H": "hidden", "private": "p"}`, func() any { return new(decodeOuter) }},
+	{`{"value": 1, "next": {"value": 2, "next": null}, "Kids": [{"value": 3}]}`, func() any { return new(decodeRecursive) }},
+	{`[1, 2.5, "x", null, [], {}]`, func() any { return new(any) }},
+	{`[1, 2, 3]`, func() any { return new([]int) }},
+	{`[]`, func() any { return new([]int) }},
+	{`null`, func() any { return new(*int) }},
+	{`"é😀"`, func() any { return new(string) }},
+	{`{"a": 1, "b": "x", "a": 2}`, func() any { return new(decodeInner) }},
+	{`{"a": "x", "b": 1}`, func() any { return new(decodeInner) }},
+	{`{"a": 1.5}`, func() any { return new(decodeInner) }},
+	{`{"a": 300}`, func() any { return new(map[string]int8) }},
+	{`[-1]`, func() any { return new([]uint) }},
+	{`{"count": 12}`, func() any { return new(decodeOuter) }},
+	{`{"count": "x"}`, func() any { return new(decodeOuter) }},
+	{`{"int_map": {"x": "y"}}`, func() any { return new(decodeOuter) }},
+	{`{"time": "yesterday"}`, func() any { return new(decodeOuter) }},
+}
+
+func TestUnmarshalDifferential(t *testing.T) {
+	for _, test := range unmarshalTests {
+		want, got := test.ptr(), test.ptr()
+
+		wantErr := json.Unmarshal([]byte(test.in), want)
+		err := Unmarshal([]byte(test.in), got)
+
+		if (err == nil) != (wantErr == nil) {
+			t.Errorf("Unmarshal(%#q, %T):", test.in, got)
+			t.Errorf("  got  error %v", err)
+			t.Errorf("  want error %v", wantErr)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Unmarshal(%#q, %T):", test.in, got)
+			t.Errorf("  got  %+v", reflect.ValueOf(got).Elem())
+			t.Errorf("  want %+v", reflect.ValueOf(want).Elem())
+		}
+	}
+}
+
+var unmarshalErrorTests = []struct {
+	in     string
+	ptr    any
+	offset int64
+	path   string
+}{
+	{`{"list": [{"a": 1}, {"a": "x"}]}`, new(decodeOuter), 26, "List[1].A"},
+	{`{"map": {"k": true}}`, new(decodeOuter), 14, `Map["k"]`},
+	{`{"nested": {"k": [1, "2"]}}`, new(decodeOuter), 21, `Nested["k"][1]`},
+	{`{"any": {"x": [1e999]}}`, new(decodeOuter), 15, `Any["x"][0]`},
+	{`{"e2": "x"}`, new(decodeOuter), 7, "E2"},
+	{`[true]`, new([]string), 1, "[0]"},
+	{`{"time": 5}`, new(decodeOuter), 9, "Time"},
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for _, test := range unmarshalErrorTests {
+		err := Unmarshal([]byte(test.in), test.ptr)
+
+		var uerr *UnmarshalError
+		if !errors.As(err, &uerr) {
+			t.Errorf("Unmarshal(%#q): got error %v, want *UnmarshalError", test.in, err)
+		} else if uerr.Offset != test.offset || uerr.Path != test.path {
+			t.Errorf("Unmarshal(%#q): got error at %d, %q, want %d, %q", test.in, uerr.Offset, uerr.Path, test.offset, test.path)
+		}
+	}
+}
+
+func TestUnmarshalSyntaxErrors(t *testing.T) {
+	for _, in := range []string{``, `{"a": 1`, `{"a" 1}`, `[1, 2,]`, `{} {}`, `{"list": [{"a": 1}, {"a": 2}}`} {
+		var v decodeOuter
+
+		var serr *SyntaxError
+		if err := Unmarshal([]byte(in), &v); !errors.As(err, &serr) {
+			t.Errorf("Unmarshal(%#q): got error %v, want *SyntaxError", in, err)
+		}
+	}
+}
+
+func TestUnmarshalInvalidArgument(t *testing.T) {
+	var v decodeInner
+
+	for _, ptr := range []any{nil, v, (*decodeInner)(nil)} {
+		var ierr *InvalidUnmarshalError
+		if err := Unmarshal([]byte(`{}`), ptr); !errors.As(err, &ierr) {
+			t.Errorf("Unmarshal(%T): got error %v, want *InvalidUnmarshalError", ptr, err)
+		}
+	}
+}
+
+func TestValueDecode(t *testing.T) {
+	var got []decodeInner
+	var err error
+
+	for v := range Elements([]byte(`[{"a": 1}, {"a": "2"}]`)) {
+		var x decodeInner
+		if err = v.Decode(&x); err != nil {
+			break
+		}
+		got = append(got, x)
+	}
+
+	var uerr *UnmarshalError
+	if !errors.As(err, &uerr) || uerr.Offset != 17 {
+		t.Errorf("got error %v, want *UnmarshalError at offset 17", err)
+	}
+	if fmt.Sprint(got) != "[{1 }]" {
+		t.Errorf("got %v", got)
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	type sampleType struct {
+		Data []struct {
+			ID   string `json:"id"`
+			From struct {
+				Name string `json:"name"`
+				ID   string `json:"id"`
+			} `json:"from"`
+			Message string `json:"message"`
+			Actions []struct {
+				Name string `json:"name"`
+				Link string `json:"link"`
+			} `json:"actions"`
+			Type        string `json:"type"`
+			CreatedTime string `json:"created_time"`
+			UpdatedTime string `json:"updated_time"`
+		} `json:"data"`
+	}
+
+	var data = []byte(sample)
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var v sampleType
+		if err := Unmarshal(data, &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package jo
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// A Decoder reads a stream of JSON values token by token. It is a drop-in
+// replacement for the token API of encoding/json's Decoder.
+//
+// Unlike its encoding/json counterpart, a Decoder reports malformed input
+// using *SyntaxError rather than *json.SyntaxError.
+type Decoder struct {
+	t         *Tokenizer
+	useNumber bool
+}
+
+// NewDecoder returns a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	t := NewTokenizer(r)
+	t.multi = true
+	return &Decoder{t: t}
+}
+
+// UseNumber causes the Decoder to return numbers as json.Number rather than
+// float64.
+func (d *Decoder) UseNumber() {
+	d.useNumber = true
+}
+
+// Token returns the next JSON token in the input stream. At the end of the
+// input stream, Token returns nil, io.EOF.
+//
+// Just like encoding/json's Decoder, the returned value is one of:
+//
+//	json.Delim, for the four JSON delimiters [ ] { }
+//	bool, for JSON booleans
+//	float64 or json.Number, for JSON numbers
+//	string, for JSON string literals
+//	nil, for JSON null
+//
+// Commas and colons are elided.
+func (d *Decoder) Token() (json.Token, error) {
+	tok, err := d.t.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok.Kind {
+	case ObjectStart, ObjectEnd, ArrayStart, ArrayEnd:
+		return json.Delim(tok.Raw[0]), nil
+
+	case KeyStart, StringStart:
+		return string(unquote(nil, tok.Raw)), nil
+
+	case NumberStart:
+		if d.useNumber {
+			return json.Number(tok.Raw), nil
+		}
+
+		f, err := strconv.ParseFloat(string(tok.Raw), 64)
+		if err != nil {
+			return nil, &json.UnmarshalTypeError{
+				Value:  "number " + string(tok.Raw),
+				Type:   reflect.TypeFor[float64](),
+				Offset: tok.Offset,
+			}
+		}
+		return f, nil
+
+	case BoolStart:
+		return tok.Raw[0] == 't', nil
+	}
+
+	return nil, nil
+}
+
+// More reports whether there is another element in the current array or
+// object being parsed.
+func (d *Decoder) More() bool {
+	c, err := d.t.peek()
+	return err == nil && c != ']' && c != '}'
+}
+
+// InputOffset returns the input stream byte offset of the current decoder
+// position, which is the end of the most recently returned token.
+func (d *Decoder) InputOffset() int64 {
+	return d.t.Offset()
+}
+
+// Buffered returns a reader of the data remaining in the Decoder's buffer.
+// The reader is valid until the next call to Token.
+func (d *Decoder) Buffered() io.Reader {
+	return bytes.NewReader(d.t.buf[d.t.mark-d.t.off:])
+}
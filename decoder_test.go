@@ -0,0 +1,144 @@
+package jo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+var decoderInputs = []string{
+	``,
+	`   `,
+	`null`,
+	` true false `,
+	`123 -0.5e-3 4E+2`,
+	`"a" "bé\n" "😀" "\ud83d" "\udc00x"`,
+	`{}{}[][]`,
+	`1{"a":2}[3]"x"null`,
+	`{"a": [1, 2, {"b": null}], "c": {"d": [[], {}]}, "e": "f"}`,
+	`[{"id": 1, "tags": ["x", "y"]}, {"id": 2, "tags": []}]`,
+	"[\n\t1 ,\r\n 2 ]\n",
+	"\"\xff\xfe\"",
+	sample,
+}
+
+var decoderErrorInputs = []string{
+	`[1 2]`,
+	`{"a" 1}`,
+	`{"a":1,}`,
+	`[1,]`,
+	`{1:2}`,
+	`[tru]`,
+	`[-]`,
+	`nulL`,
+	`[1}`,
+	`{"a":1]`,
+	`]`,
+}
+
+// decoderTrace drives a token decoder through its input, recording the
+// results of each call to More, Token and InputOffset.
+func decoderTrace(more func() bool, token func() (json.Token, error), offset func() int64) ([]string, error) {
+	var out []string
+
+	for {
+		m := more()
+
+		tok, err := token()
+		if err == io.EOF {
+			return out, nil
+		} else if err != nil {
+			return out, err
+		}
+
+		out = append(out, fmt.Sprintf("%v %T(%v) @%d", m, tok, tok, offset()))
+	}
+}
+
+func TestDecoderDifferential(t *testing.T) {
+	for _, in := range decoderInputs {
+		for _, useNumber := range []bool{false, true} {
+			std := json.NewDecoder(strings.NewReader(in))
+			dec := NewDecoder(iotest.OneByteReader(strings.NewReader(in)))
+			if useNumber {
+				std.UseNumber()
+				dec.UseNumber()
+			}
+
+			want, err := decoderTrace(std.More, std.Token, std.InputOffset)
+			if err != nil {
+				t.Fatalf("encoding/json failed on %#q: %s", in, err)
+			}
+
+			got, err := decoderTrace(dec.More, dec.Token, dec.InputOffset)
+			if err != nil {
+				t.Errorf("Decoder(%#q): unexpected error: %s", in, err)
+			}
+
+			if strings.Join(got, "\n") != strings.Join(want, "\n") {
+				t.Errorf("Decoder(%#q):", in)
+				t.Errorf("  got  %q", got)
+				t.Errorf("  want %q", want)
+			}
+		}
+	}
+}
+
+func TestDecoderDifferentialErrors(t *testing.T) {
+	for _, in := range decoderErrorInputs {
+		std := json.NewDecoder(strings.NewReader(in))
+		dec := NewDecoder(strings.NewReader(in))
+
+		want, stdErr := decoderTrace(std.More, std.Token, std.InputOffset)
+		if stdErr == nil {
+			t.Fatalf("encoding/json accepted %#q", in)
+		}
+
+		got, err := decoderTrace(dec.More, dec.Token, dec.InputOffset)
+		if _, ok := err.(*SyntaxError); !ok {
+			t.Errorf("Decoder(%#q): got error %v, want *SyntaxError", in, err)
+		}
+
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("Decoder(%#q):", in)
+			t.Errorf("  got  %q", got)
+			t.Errorf("  want %q", want)
+		}
+	}
+}
+
+func TestDecoderBuffered(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(`{"a": 1} trailing`))
+
+	for i := 0; i < 4; i++ {
+		if _, err := dec.Token(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	rest, _ := io.ReadAll(dec.Buffered())
+	if string(rest) != " trailing" {
+		t.Errorf("Buffered: got %q, want %q", rest, " trailing")
+	}
+}
+
+func BenchmarkDecoder(b *testing.B) {
+	var r = strings.NewReader(sample)
+
+	b.SetBytes(int64(len(sample)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		r.Reset(sample)
+		dec := NewDecoder(r)
+
+		for {
+			if _, err := dec.Token(); err != nil {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,395 @@
+package jo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A Diagnostic describes a syntax error in terms of the input it was found
+// in, for presenting it to a human.
+type Diagnostic struct {
+	// The error being described.
+	Err *SyntaxError
+
+	// 1-based position of the offending byte. Columns count runes rather
+	// than bytes.
+	Line   int
+	Column int
+
+	// Path to the value in which the error was found, such as `$.a[2]`.
+	Path string
+
+	// Descriptions of what would have been valid in place of the offending
+	// byte, such as "value" or "','". It's empty when the error was found
+	// inside a string literal, where almost anything is valid.
+	Expected []string
+
+	src []byte
+}
+
+// Diagnose describes err, which must have been found in src, either as the
+// first error or by ValidateAll. Errors found by ValidateLines should be
+// diagnosed using the line they were found in.
+//
+// Diagnose rescans src up to the error to work out the path and the expected
+// tokens, so it should only be used once an error has actually occurred.
+func Diagnose(src []byte, err *SyntaxError) *Diagnostic {
+	offset := min(max(err.Offset, 0), int64(len(src)))
+	d := &Diagnostic{Err: err, src: src}
+
+	before := src[:offset]
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	d.Line = bytes.Count(before, []byte{'\n'}) + 1
+	d.Column = utf8.RuneCount(before[lineStart:]) + 1
+
+	// Replay the input preceding the error, keeping track of the path.
+	// Earlier errors are recovered from the same way ValidateAll does.
+	var p pathTracker
+
+	r := newRecoverer(false)
+	r.run(src, int(offset), func(ev Event, i int) {
+		p.track(ev, src, i)
+	}, func(*SyntaxError) bool {
+		return true
+	})
+	s := r.s
+
+	d.Expected = expected(s)
+
+	// Whitespace flushes any delayed end events, so that a value which
+	// has ended isn't included in the path.
+	probe := NewScanner()
+	probe.copyFrom(s)
+	if ev := probe.Scan(' '); ev != Error {
+		p.track(ev, src[:offset], int(offset))
+	}
+
+	d.Path = p.String()
+	return d
+}
+
+// pathTracker follows the path to the current value through scanning events.
+// A value is only part of the path until it ends; after a '[' or a comma in
+// an array, the path leads to the element which should follow it.
+type pathTracker struct {
+	frames   []pathFrame
+	keyStart int
+	inString bool
+}
+
+type pathFrame struct {
+	array bool
+	index int // -1 between elements
+	count int // number of elements so far
+	key   string
+	keyed bool
+}
+
+// track updates the path with the event produced by the byte at src[i].
+// Passing i == len(src) updates it with end events alone.
+func (p *pathTracker) track(ev Event, src []byte, i int) {
+	if ev&(KeyEnd|StringEnd) != 0 {
+		p.inString = false
+	}
+	if ev&KeyEnd != 0 {
+		top := &p.frames[len(p.frames)-1]
+		top.key, top.keyed = Unquote(src[p.keyStart:i]), true
+	} else if ev&End != 0 {
+		if ev&(ObjectEnd|ArrayEnd) != 0 {
+			p.frames = p.frames[:len(p.frames)-1]
+		}
+		if n := len(p.frames); n > 0 {
+			p.frames[n-1].index, p.frames[n-1].keyed = -1, false
+		}
+	}
+
+	if i < len(src) && src[i] == ',' && !p.inString && len(p.frames) > 0 {
+		top := &p.frames[len(p.frames)-1]
+		top.keyed = false
+		if top.array {
+			top.index = top.count
+		}
+	}
+
+	if ev&(KeyStart|StringStart) != 0 {
+		p.inString = true
+	}
+	if ev&KeyStart != 0 {
+		p.keyStart = i
+	} else if ev&Start != 0 {
+		if n := len(p.frames); n > 0 && p.frames[n-1].array {
+			top := &p.frames[n-1]
+			top.index = top.count
+			top.count++
+		}
+		if ev&ObjectStart != 0 {
+			p.frames = append(p.frames, pathFrame{})
+		} else if ev&ArrayStart != 0 {
+			p.frames = append(p.frames, pathFrame{array: true})
+		}
+	}
+}
+
+// String formats the path, using dot notation for keys which are valid
+// identifiers, and bracket notation otherwise.
+func (p *pathTracker) String() string {
+	buf := []byte{'$'}
+
+	for _, f := range p.frames {
+		switch {
+		case f.array && f.index >= 0:
+			buf = append(buf, '[')
+			buf = strconv.AppendInt(buf, int64(f.index), 10)
+			buf = append(buf, ']')
+
+		case f.keyed:
+			buf = appendKey(buf, f.key)
+		}
+	}
+
+	return string(buf)
+}
+
+// appendKey appends an object key to a path, in dot notation if the key is
+// a valid identifier, and bracket notation otherwise.
+func appendKey(buf []byte, key string) []byte {
+	if isIdentifier(key) {
+		buf = append(buf, '.')
+		return append(buf, key...)
+	}
+
+	buf = append(buf, '[')
+	buf = quote(buf, key, false)
+	return append(buf, ']')
+}
+
+func isIdentifier(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' || c == '$' || i > 0 && '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// expected describes the bytes which s would accept next, by trying each
+// printable ASCII character on a copy of it.
+func expected(s *Scanner) []string {
+	probe := NewScanner()
+	try := func(c byte) (Event, bool) {
+		probe.copyFrom(s)
+		ev := probe.Scan(c)
+		return ev, ev != Error
+	}
+
+	// Only string literals accept arbitrary letters.
+	if ev, ok := try('x'); ok && ev&Start == 0 {
+		return nil
+	}
+
+	var (
+		want   []string
+		value  bool
+		key    bool
+		digits = true
+		hex    = true
+		other  []byte
+	)
+
+	for c := byte('!'); c <= '~'; c++ {
+		ev, ok := try(c)
+
+		isDigit := '0' <= c && c <= '9'
+		isHex := 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+
+		// Digits only count as such if they don't start a value.
+		plain := ok && ev&Start == 0
+		digits = digits && (plain || !isDigit)
+		hex = hex && (plain || !isDigit && !isHex)
+
+		switch {
+		case !ok:
+		case ev&KeyStart != 0:
+			key = true
+		case ev&Start != 0:
+			value = true
+		default:
+			other = append(other, c)
+		}
+	}
+
+	if value {
+		want = append(want, "value")
+	}
+	if key {
+		want = append(want, "object key")
+	}
+	if hex {
+		want = append(want, "hexadecimal digit")
+	} else if digits {
+		want = append(want, "digit")
+	}
+
+	// The rest of a number comes before any delimiters.
+	exponent := bytes.IndexByte(other, 'e') >= 0 && bytes.IndexByte(other, 'E') >= 0 && !hex
+	if bytes.IndexByte(other, '.') >= 0 {
+		want = append(want, "'.'")
+	}
+	if exponent {
+		want = append(want, "exponent")
+	}
+
+	for _, c := range other {
+		switch {
+		case digits && '0' <= c && c <= '9', hex && ('a' <= c && c <= 'f' || 'A' <= c && c <= 'F'):
+		case c == '.', exponent && (c == 'e' || c == 'E'):
+		default:
+			want = append(want, "'"+string(c)+"'")
+		}
+	}
+
+	probe.copyFrom(s)
+	if probe.End() != Error {
+		want = append(want, "end of input")
+	}
+
+	return want
+}
+
+// oneOf joins alternatives into a phrase like "a, b or c".
+func oneOf(alts []string) string {
+	if len(alts) <= 1 {
+		return strings.Join(alts, "")
+	}
+	return strings.Join(alts[:len(alts)-1], ", ") + " or " + alts[len(alts)-1]
+}
+
+// The maximum number of columns shown before and after the offending byte
+// in a snippet. Long lines, like those of minified JSON, are cut short.
+const (
+	snippetBefore = 60
+	snippetAfter  = 20
+)
+
+// ANSI escape sequences used by Render.
+const (
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[1;31m"
+	ansiCyan  = "\x1b[36m"
+	ansiDim   = "\x1b[2m"
+	ansiReset = "\x1b[0m"
+)
+
+// Render writes a description of the error to w, starting with a header line
+// in the form "name:line:column: message". It's followed by a snippet of the
+// input, showing the offending line and the one before it with a caret
+// pointing at the error, the tokens which would have been valid instead
+// unless the message already lists them, and the path to the value in which
+// the error was found.
+//
+// If color is set, the output is highlighted using ANSI escape sequences.
+func (d *Diagnostic) Render(w io.Writer, name string, color bool) error {
+	paint := func(code, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + ansiReset
+	}
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "%s %s\n", paint(ansiBold, fmt.Sprintf("%s:%d:%d:", name, d.Line, d.Column)), paint(ansiRed, d.Err.Error()))
+
+	offset := min(max(d.Err.Offset, 0), int64(len(d.src)))
+	lineStart := bytes.LastIndexByte(d.src[:offset], '\n') + 1
+
+	gutter := len(strconv.Itoa(d.Line))
+	number := func(n int) string {
+		return paint(ansiDim, fmt.Sprintf(" %*d |", gutter, n))
+	}
+	blank := paint(ansiDim, fmt.Sprintf(" %*s |", gutter, ""))
+
+	// The preceding line, if any.
+	if lineStart > 0 {
+		prev := d.src[:lineStart-1]
+		prev = bytes.TrimSuffix(prev[bytes.LastIndexByte(prev, '\n')+1:], []byte{'\r'})
+		text, _ := window(prev, 0, 0, snippetBefore+snippetAfter)
+		fmt.Fprintf(&buf, "%s %s\n", number(d.Line-1), text)
+	}
+
+	line := d.src[lineStart:]
+	if end := bytes.IndexByte(line, '\n'); end >= 0 {
+		line = line[:end]
+	}
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+
+	text, pad := window(line, int(offset)-lineStart, snippetBefore, snippetAfter)
+	fmt.Fprintf(&buf, "%s %s\n", number(d.Line), text)
+
+	// Scanner errors mostly say what was expected already.
+	caret := paint(ansiRed, "^")
+	if len(d.Expected) > 0 && !strings.Contains(d.Err.Error(), ": expected ") {
+		caret += " " + paint(ansiCyan, "expected "+oneOf(d.Expected))
+	}
+	fmt.Fprintf(&buf, "%s %s%s\n", blank, pad, caret)
+
+	if d.Path != "" {
+		fmt.Fprintf(&buf, "%s at %s\n", paint(ansiDim, fmt.Sprintf(" %*s =", gutter, "")), d.Path)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// window returns the part of line shown in a snippet, with at most before
+// runes preceding pos and after runes following it, together with padding
+// which lines a caret up with the rune at pos. Tabs are kept in the padding,
+// and control characters are replaced.
+func window(line []byte, pos, before, after int) (text, pad string) {
+	pos = min(pos, len(line))
+
+	start := pos
+	for n := 0; start > 0 && n < before; n++ {
+		_, size := utf8.DecodeLastRune(line[:start])
+		start -= size
+	}
+
+	end := pos
+	for n := 0; end < len(line) && n <= after; n++ {
+		_, size := utf8.DecodeRune(line[end:])
+		end += size
+	}
+
+	var t, p strings.Builder
+
+	if start > 0 {
+		t.WriteString("...")
+		p.WriteString("   ")
+	}
+
+	for i, r := range string(line[start:end]) {
+		if r < 0x20 && r != '\t' || r == 0x7f {
+			r = utf8.RuneError
+		}
+		t.WriteRune(r)
+
+		if start+i < pos {
+			if r == '\t' {
+				p.WriteByte('\t')
+			} else {
+				p.WriteByte(' ')
+			}
+		}
+	}
+
+	if end < len(line) {
+		t.WriteString("...")
+	}
+
+	return t.String(), p.String()
+}
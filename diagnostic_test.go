@@ -0,0 +1,131 @@
+package jo
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func ExampleDiagnose() {
+	src := []byte("{\n  \"name\": \"jo\",\n  \"tags\": [\"json\" \"go\"]\n}")
+
+	var serr *SyntaxError
+	if errors.As(Validate(src), &serr) {
+		Diagnose(src, serr).Render(os.Stdout, "config.json", false)
+	}
+	// Output:
+	// config.json:3:19: invalid character '"': expected ',' or ']' after array element
+	//  2 |   "name": "jo",
+	//  3 |   "tags": ["json" "go"]
+	//    |                   ^
+	//    = at $.tags
+}
+
+var diagnoseTests = []struct {
+	in       string
+	line     int
+	col      int
+	path     string
+	expected []string
+}{
+	{`{"a": [1, 2}`, 1, 12, `$.a`, []string{"digit", "'.'", "exponent", "','", "']'"}},
+	{`{"a": [1, 2 }`, 1, 13, `$.a`, []string{"','", "']'"}},
+	{`{"a": 1`, 1, 8, `$`, []string{"digit", "'.'", "exponent", "','", "'}'"}},
+	{`{"a":{"b c":[1,{"d":-}]}}`, 1, 22, `$.a["b c"][1].d`, []string{"digit"}},
+	{`{"a":{"b":1}x}`, 1, 13, `$`, []string{"','", "'}'"}},
+	{`[[], [] x]`, 1, 9, `$`, []string{"','", "']'"}},
+	{`[1,2] x`, 1, 7, `$`, []string{"end of input"}},
+	{`{'a':1}`, 1, 2, `$`, []string{"object key", "'}'"}},
+	{`{"a":1,}`, 1, 8, `$`, []string{"object key"}},
+	{`{"a":[1,2],}`, 1, 12, `$`, []string{"object key"}},
+	{`{"a":1,"b":}`, 1, 12, `$.b`, []string{"value"}},
+	{`{"a":}`, 1, 6, `$.a`, []string{"value"}},
+	{`[1,]`, 1, 4, `$[1]`, []string{"value"}},
+	{`[1,,2]`, 1, 4, `$[1]`, []string{"value"}},
+	{`[[1],[2,`, 1, 9, `$[1][1]`, []string{"value"}},
+	{`[",",]`, 1, 6, `$[1]`, []string{"value"}},
+	{`{"a" 1}`, 1, 6, `$.a`, []string{"':'"}},
+	{`[-x]`, 1, 3, `$[0]`, []string{"digit"}},
+	{`[x]`, 1, 2, `$[0]`, []string{"value", "']'"}},
+	{`[NaN]`, 1, 2, `$[0]`, []string{"value", "']'"}},
+	{`[[x]]`, 1, 3, `$[0][0]`, []string{"value", "']'"}},
+	{`{"a":[ x`, 1, 8, `$.a[0]`, []string{"value", "']'"}},
+	{`[1e]`, 1, 4, `$[0]`, []string{"digit", "'+'", "'-'"}},
+	{`[nul]`, 1, 5, `$[0]`, []string{"'l'"}},
+	{`"\u12x"`, 1, 6, `$`, []string{"hexadecimal digit"}},
+	{"\"a\x01\"", 1, 3, `$`, nil},
+	{"[\n\"é\",\n?]", 3, 1, `$[1]`, []string{"value"}},
+	{``, 1, 1, `$`, []string{"value"}},
+}
+
+func TestDiagnose(t *testing.T) {
+	for _, test := range diagnoseTests {
+		var serr *SyntaxError
+		if !errors.As(Validate([]byte(test.in)), &serr) {
+			t.Errorf("Validate(%#q) succeeded", test.in)
+			continue
+		}
+
+		d := Diagnose([]byte(test.in), serr)
+		if d.Line != test.line || d.Column != test.col || d.Path != test.path || !slices.Equal(d.Expected, test.expected) {
+			t.Errorf("Diagnose(%#q):", test.in)
+			t.Errorf("  got  %d:%d %s %q", d.Line, d.Column, d.Path, d.Expected)
+			t.Errorf("  want %d:%d %s %q", test.line, test.col, test.path, test.expected)
+		}
+	}
+}
+
+func TestDiagnosticRender(t *testing.T) {
+	// A long, minified document is cut short around the error.
+	src := []byte(`{"k":[` + strings.Repeat(`"xxxxxxxxxx",`, 100))
+
+	var serr *SyntaxError
+	if !errors.As(Validate(src), &serr) {
+		t.Fatal("no error")
+	}
+
+	var plain, color bytes.Buffer
+	Diagnose(src, serr).Render(&plain, "in", false)
+	Diagnose(src, serr).Render(&color, "in", true)
+
+	want := "in:1:1307: unexpected end of JSON input\n" +
+		" 1 | ...xxxxxx\"," + strings.Repeat(`"xxxxxxxxxx",`, 4) + "\n" +
+		"   | " + strings.Repeat(" ", 63) + "^ expected value\n" +
+		"   = at $.k[100]\n"
+
+	if plain.String() != want {
+		t.Errorf("plain:\n%s\nwant:\n%s", plain.String(), want)
+	}
+
+	stripped := color.String()
+	for _, code := range []string{ansiBold, ansiRed, ansiCyan, ansiDim, ansiReset} {
+		stripped = strings.ReplaceAll(stripped, code, "")
+	}
+	if stripped != want || stripped == color.String() {
+		t.Errorf("color:\n%q", color.String())
+	}
+}
+
+func TestDiagnosticRenderControl(t *testing.T) {
+	src := []byte("[1,\r\n\t\"a\x01b\"]")
+
+	var serr *SyntaxError
+	if !errors.As(Validate(src), &serr) {
+		t.Fatal("no error")
+	}
+
+	var buf bytes.Buffer
+	Diagnose(src, serr).Render(&buf, "in", false)
+
+	want := "in:2:4: invalid character '\\x01' in string literal; control characters must be escaped\n" +
+		" 1 | [1,\n" +
+		" 2 | \t\"a�b\"]\n" +
+		"   | \t  ^\n" +
+		"   = at $[1]\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%q\nwant:\n%q", buf.String(), want)
+	}
+}
@@ -0,0 +1,245 @@
+package jo
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// A Node is a single value in a parsed JSON document. Scalar values keep
+// their raw input bytes, so numbers lose no precision, and object members
+// are kept in input order, duplicates included.
+type Node struct {
+	Value
+
+	// Quoted key, for object members.
+	key []byte
+
+	// Elements of an array, or members of an object.
+	kids []Node
+}
+
+// Parse parses a JSON document into a tree of Nodes. The tree refers to data
+// rather than copying it, and all of its nodes are allocated from a single
+// arena.
+func Parse(data []byte) (*Node, error) {
+	var p parser
+
+	if err := p.parse(data); err != nil {
+		return nil, err
+	}
+
+	return &p.arena.alloc(1, p.work)[0], nil
+}
+
+// Len returns the number of elements in an array, or members in an object.
+func (n *Node) Len() int {
+	return len(n.kids)
+}
+
+// Children returns the elements of an array, or members of an object.
+func (n *Node) Children() []Node {
+	return n.kids
+}
+
+// Index returns the i-th element of an array, or the value of the i-th
+// member of an object. It returns nil if i is out of range.
+func (n *Node) Index(i int) *Node {
+	if i < 0 || i >= len(n.kids) {
+		return nil
+	}
+	return &n.kids[i]
+}
+
+// Get returns the value of the first object member with the given key, or
+// nil if there is no such member.
+func (n *Node) Get(key string) *Node {
+	for i := range n.kids {
+		if keyEquals(n.kids[i].key, key) {
+			return &n.kids[i]
+		}
+	}
+	return nil
+}
+
+// Key returns the decoded key of an object member. It returns the empty
+// string for nodes which aren't object members.
+func (n *Node) Key() string {
+	if n.key == nil {
+		return ""
+	}
+	return string(unquote(nil, n.key))
+}
+
+// Lookup resolves a JSON Pointer (RFC 6901) relative to n. It returns nil
+// if the pointer is malformed or doesn't refer to an existing value.
+func (n *Node) Lookup(pointer string) *Node {
+	if pointer == "" {
+		return n
+	}
+	if pointer[0] != '/' {
+		return nil
+	}
+
+	for _, tok := range strings.Split(pointer[1:], "/") {
+		tok = strings.ReplaceAll(tok, "~1", "/")
+		tok = strings.ReplaceAll(tok, "~0", "~")
+
+		switch n.Kind {
+		case ObjectStart:
+			n = n.Get(tok)
+		case ArrayStart:
+			i, err := strconv.Atoi(tok)
+			if err != nil || tok != strconv.Itoa(i) {
+				return nil
+			}
+			n = n.Index(i)
+		default:
+			return nil
+		}
+
+		if n == nil {
+			return nil
+		}
+	}
+
+	return n
+}
+
+// keyEquals reports whether the quoted key raw decodes to key.
+func keyEquals(raw []byte, key string) bool {
+	if len(raw) < 2 {
+		return false
+	}
+	if bytes.IndexByte(raw, '\\') < 0 {
+		return string(raw[1:len(raw)-1]) == key
+	}
+	return string(unquote(nil, raw)) == key
+}
+
+// A parser builds a tree of Nodes from Scanner events.
+type parser struct {
+	arena arena
+
+	// Completed nodes whose parent is still open.
+	work []Node
+
+	// Open containers.
+	frames []frame
+
+	// Treatment of duplicate keys, and when they're rejected, the keys
+	// seen so far.
+	policy DuplicatePolicy
+	keys   *keyChecker
+}
+
+// A frame records an open container.
+type frame struct {
+	kind  Event
+	start int
+	key   []byte
+
+	// Index of the container's first child in the work stack.
+	base int
+}
+
+// parse parses data, leaving the root node as the single entry in the
+// work stack.
+func (p *parser) parse(data []byte) error {
+	var s = NewScanner()
+	var start, kstart int
+	var kind Event
+	var key []byte
+
+	// Reserve space for roughly as many nodes as a document made up of
+	// short values would need.
+	p.arena.next = len(data)/16 + 1
+
+	for i := 0; i <= len(data); i++ {
+		var ev Event
+		if i < len(data) {
+			ev = s.Scan(data[i])
+		} else {
+			ev = s.End()
+		}
+
+		if ev == Error {
+			return s.syntaxError(int64(i))
+		}
+		if p.keys != nil && p.keys.track(ev, data, i) {
+			return p.keys.error(int64(kstart))
+		}
+
+		switch ev & End {
+		case None:
+		case KeyEnd:
+			key = data[kstart:i]
+		case ObjectEnd, ArrayEnd:
+			f := p.frames[len(p.frames)-1]
+			p.frames = p.frames[:len(p.frames)-1]
+
+			members := p.work[f.base:]
+			if f.kind == ObjectStart && (p.policy == FirstWins || p.policy == LastWins) {
+				members = dedupe(members, p.policy)
+			}
+
+			kids := p.arena.alloc(len(members), members)
+			p.work = append(p.work[:f.base], Node{
+				Value: Value{Kind: f.kind, Raw: data[f.start:i], Offset: int64(f.start)},
+				key:   f.key,
+				kids:  kids,
+			})
+		default:
+			p.work = append(p.work, Node{
+				Value: Value{Kind: kind, Raw: data[start:i], Offset: int64(start)},
+				key:   key,
+			})
+			key = nil
+		}
+
+		switch ev & Start {
+		case None:
+		case KeyStart:
+			kstart = i
+		case ObjectStart, ArrayStart:
+			p.frames = append(p.frames, frame{ev & Start, i, key, len(p.work)})
+			key = nil
+		default:
+			start = i
+			kind = ev & Start
+		}
+	}
+
+	return nil
+}
+
+// An arena hands out blocks of nodes. Blocks stay put as the arena grows,
+// so they may be referenced for as long as the arena itself.
+type arena struct {
+	free []Node
+
+	// Size of the next chunk to be allocated.
+	next int
+}
+
+// alloc returns a block of n nodes, initialized by copying from src.
+func (a *arena) alloc(n int, src []Node) []Node {
+	if n == 0 {
+		return nil
+	}
+
+	if len(a.free) < n {
+		size := a.next
+		if size < n {
+			size = n
+		}
+		a.free = make([]Node, size)
+		a.next = 2 * size
+	}
+
+	block := a.free[:n:n]
+	a.free = a.free[n:]
+	copy(block, src)
+
+	return block
+}
@@ -0,0 +1,96 @@
+package jo
+
+import (
+	"fmt"
+	"testing"
+)
+
+func ExampleParse() {
+	doc, err := Parse([]byte(`{"user": {"name": "Tom", "tags": ["a", "b"]}, "n": 1.000000000000000001}`))
+	if err != nil {
+		panic(err)
+	}
+
+	name, _ := doc.Get("user").Get("name").Text()
+	fmt.Println(name)
+	fmt.Printf("%s\n", doc.Lookup("/user/tags/1").Raw)
+	fmt.Printf("%s\n", doc.Get("n").Raw)
+	// Output:
+	// Tom
+	// "b"
+	// 1.000000000000000001
+}
+
+func TestParse(t *testing.T) {
+	doc, err := Parse([]byte(` {"a": 1, "b": [true, null, {}], "a": "x", "cA": [], "d/e": {"~f": 2}} `))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if doc.Kind != ObjectStart || doc.Len() != 5 || doc.Offset != 1 {
+		t.Fatalf("unexpected root: %s with %d children at %d", doc.Kind, doc.Len(), doc.Offset)
+	}
+
+	var keys []string
+	for _, m := range doc.Children() {
+		keys = append(keys, m.Key())
+	}
+	if fmt.Sprint(keys) != "[a b a cA d/e]" {
+		t.Errorf("got keys %v", keys)
+	}
+
+	var lookupTests = []struct {
+		ptr  string
+		kind Event
+		raw  string
+	}{
+		{"/a", NumberStart, `1`},
+		{"/b", ArrayStart, `[true, null, {}]`},
+		{"/b/0", BoolStart, `true`},
+		{"/b/1", NullStart, `null`},
+		{"/b/2", ObjectStart, `{}`},
+		{"/cA", ArrayStart, `[]`},
+		{"/d~1e/~0f", NumberStart, `2`},
+		{"/b/3", Error, ``},
+		{"/b/01", Error, ``},
+		{"/a/0", Error, ``},
+		{"a", Error, ``},
+	}
+
+	for _, test := range lookupTests {
+		n := doc.Lookup(test.ptr)
+		if n == nil {
+			if test.kind != Error {
+				t.Errorf("Lookup(%q): got nil", test.ptr)
+			}
+		} else if n.Kind != test.kind || string(n.Raw) != test.raw {
+			t.Errorf("Lookup(%q): got %s %#q, want %s %#q", test.ptr, n.Kind, n.Raw, test.kind, test.raw)
+		}
+	}
+
+	if doc.Index(2).Raw[1] != 'x' {
+		t.Errorf("Index(2): got %#q", doc.Index(2).Raw)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, in := range []string{``, `[`, `{"a":}`, `[1,2,]`, `{} {}`} {
+		if _, err := Parse([]byte(in)); err == nil {
+			t.Errorf("Parse(%#q): expected error", in)
+		} else if _, ok := err.(*SyntaxError); !ok {
+			t.Errorf("Parse(%#q): got %T, want *SyntaxError", in, err)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	var data = []byte(sample)
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		Parse(data)
+	}
+}
@@ -0,0 +1,203 @@
+package jo
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// A DuplicateKeyError reports an object with more than one member with the
+// same key. Keys are compared after decoding escape sequences, so "a" and
+// "a" are the same key.
+type DuplicateKeyError struct {
+	// Key is the decoded key, and Path the path to the second member with
+	// that key, such as $.users[3].name.
+	Key  string
+	Path string
+
+	// Offset is the input offset of the second member's key.
+	Offset int64
+}
+
+// Error returns a description of the duplicate key.
+func (e *DuplicateKeyError) Error() string {
+	return "duplicate key " + strconv.Quote(e.Key) + " at " + e.Path
+}
+
+// DisallowDuplicateKeys causes t to report a *DuplicateKeyError in place of
+// the second key token of any object member whose key isn't unique within
+// its object. This costs memory proportional to the number of members of
+// the objects being read.
+func (t *Tokenizer) DisallowDuplicateKeys() {
+	if t.keys == nil {
+		t.keys = new(keyChecker)
+	}
+}
+
+// DisallowDuplicateKeys causes d to report a *DuplicateKeyError in place of
+// the second key of any object member whose key isn't unique within its
+// object, as with Tokenizer.DisallowDuplicateKeys.
+func (d *Decoder) DisallowDuplicateKeys() {
+	d.t.DisallowDuplicateKeys()
+}
+
+// A DuplicatePolicy determines how ParseWithPolicy treats object members
+// whose keys aren't unique within their object.
+type DuplicatePolicy int
+
+const (
+	// KeepDuplicates keeps all members, as Parse does.
+	KeepDuplicates DuplicatePolicy = iota
+
+	// FirstWins keeps only the first member with each key.
+	FirstWins
+
+	// LastWins keeps the value of the last member with each key, in the
+	// position of the first, just like JavaScript's JSON.parse does.
+	LastWins
+
+	// RejectDuplicates results in a *DuplicateKeyError.
+	RejectDuplicates
+)
+
+// ParseWithPolicy is like Parse, but treats duplicate keys according to
+// the given policy.
+func ParseWithPolicy(data []byte, policy DuplicatePolicy) (*Node, error) {
+	p := parser{policy: policy}
+	if policy == RejectDuplicates {
+		p.keys = new(keyChecker)
+	}
+
+	if err := p.parse(data); err != nil {
+		return nil, err
+	}
+
+	return &p.arena.alloc(1, p.work)[0], nil
+}
+
+// dedupe removes the members of an object whose keys aren't unique, as
+// directed by the policy, and returns the remaining members.
+func dedupe(kids []Node, policy DuplicatePolicy) []Node {
+	if len(kids) < 2 {
+		return kids
+	}
+
+	// Small objects are cheaper to search than to index.
+	var seen map[string]int
+	if len(kids) > 16 {
+		seen = make(map[string]int, len(kids))
+	}
+
+	out := kids[:0]
+
+	for _, n := range kids {
+		j := -1
+		if seen != nil {
+			k := decodeKey(n.key)
+			if i, ok := seen[k]; ok {
+				j = i
+			} else {
+				seen[k] = len(out)
+			}
+		} else {
+			for i := range out {
+				if sameKey(out[i].key, n.key) {
+					j = i
+					break
+				}
+			}
+		}
+
+		if j < 0 {
+			out = append(out, n)
+		} else if policy == LastWins {
+			out[j].Value = n.Value
+			out[j].kids = n.kids
+		}
+	}
+
+	return out
+}
+
+// decodeKey decodes a quoted key.
+func decodeKey(raw []byte) string {
+	if bytes.IndexByte(raw, '\\') < 0 {
+		return string(raw[1 : len(raw)-1])
+	}
+	return string(unquote(nil, raw))
+}
+
+// sameKey reports whether the quoted keys a and b decode to the same key.
+func sameKey(a, b []byte) bool {
+	if bytes.IndexByte(a, '\\') < 0 && bytes.IndexByte(b, '\\') < 0 {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(unquote(nil, a), unquote(nil, b))
+}
+
+// A keyChecker detects duplicate keys through scanning events, keeping the
+// decoded keys of each open object.
+type keyChecker struct {
+	path pathTracker
+
+	// Keys of each open container, innermost last, with nil for arrays.
+	keys []map[string]struct{}
+
+	// Sets no longer in use.
+	free []map[string]struct{}
+}
+
+// track updates the checker with the event produced by the byte at src[i],
+// and reports whether it completes a duplicate key.
+func (k *keyChecker) track(ev Event, src []byte, i int) bool {
+	k.path.track(ev, src, i)
+
+	dup := false
+	if ev&KeyEnd != 0 {
+		set := k.keys[len(k.keys)-1]
+		key := k.path.frames[len(k.path.frames)-1].key
+		if _, dup = set[key]; !dup {
+			set[key] = struct{}{}
+		}
+	}
+
+	if ev&(ObjectEnd|ArrayEnd) != 0 {
+		if set := k.keys[len(k.keys)-1]; set != nil {
+			clear(set)
+			k.free = append(k.free, set)
+		}
+		k.keys = k.keys[:len(k.keys)-1]
+	}
+
+	if ev&ObjectStart != 0 {
+		var set map[string]struct{}
+		if n := len(k.free); n > 0 {
+			set, k.free = k.free[n-1], k.free[:n-1]
+		} else {
+			set = make(map[string]struct{})
+		}
+		k.keys = append(k.keys, set)
+	} else if ev&ArrayStart != 0 {
+		k.keys = append(k.keys, nil)
+	}
+
+	return dup
+}
+
+// token updates the checker with a token, and reports whether it's a
+// duplicate key.
+func (k *keyChecker) token(tok Token) bool {
+	if tok.Kind == KeyStart {
+		k.track(KeyStart, tok.Raw, 0)
+		return k.track(KeyEnd, tok.Raw, len(tok.Raw))
+	}
+	return k.track(tok.Kind, nil, 0)
+}
+
+// error describes the duplicate key just found.
+func (k *keyChecker) error(off int64) *DuplicateKeyError {
+	return &DuplicateKeyError{
+		Key:    k.path.frames[len(k.path.frames)-1].key,
+		Path:   k.path.String(),
+		Offset: off,
+	}
+}
@@ -0,0 +1,135 @@
+package jo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func ExampleParseWithPolicy() {
+	data := []byte(`{"role": "user", "name": "Tom", "role": "admin"}`)
+
+	for _, policy := range []DuplicatePolicy{FirstWins, LastWins, RejectDuplicates} {
+		doc, err := ParseWithPolicy(data, policy)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Printf("%d members, role %s\n", doc.Len(), doc.Get("role").Raw)
+	}
+	// Output:
+	// 2 members, role "user"
+	// 2 members, role "admin"
+	// duplicate key "role" at $.role
+}
+
+var duplicateTests = []struct {
+	in string
+
+	// The duplicate key, if any, its path and its offset.
+	key    string
+	path   string
+	offset int64
+}{
+	{`{"a": 1, "b": {"a": 2}, "c": [{"a": 3}, {"a": 4}]}`, "", "", 0},
+	{`[{"a": 1}, {"b": 2, "a": 3, "b": 4}]`, "b", "$[1].b", 28},
+	{`{"a": 1, "a": 2}`, "a", "$.a", 9},
+	{`{"x": {"a b": {}, "a b": []}}`, "a b", `$.x["a b"]`, 18},
+	{`{"a": {"a": {"a": 1}}, "a": 2}`, "a", "$.a", 23},
+	{`{"": 1, "\/": 2, "/": 3}`, "/", `$["/"]`, 17},
+}
+
+func TestDisallowDuplicateKeys(t *testing.T) {
+	for _, test := range duplicateTests {
+		tz := NewBytesTokenizer([]byte(test.in))
+		tz.DisallowDuplicateKeys()
+		_, terr := readTokens(tz)
+
+		_, perr := ParseWithPolicy([]byte(test.in), RejectDuplicates)
+
+		for _, err := range []error{terr, perr} {
+			if test.key == "" {
+				if err != nil {
+					t.Errorf("%#q: unexpected error: %s", test.in, err)
+				}
+				continue
+			}
+
+			derr, ok := err.(*DuplicateKeyError)
+			if !ok {
+				t.Errorf("%#q: got error %v, want *DuplicateKeyError", test.in, err)
+			} else if derr.Key != test.key || derr.Path != test.path || derr.Offset != test.offset {
+				t.Errorf("%#q:", test.in)
+				t.Errorf("  got  %q at %s, offset %d", derr.Key, derr.Path, derr.Offset)
+				t.Errorf("  want %q at %s, offset %d", test.key, test.path, test.offset)
+			}
+		}
+	}
+}
+
+func TestDisallowDuplicateKeysStream(t *testing.T) {
+	d := NewDecoder(strings.NewReader(`{"a": 1} {"a": 2} {"b": 3, "b": 4}`))
+	d.DisallowDuplicateKeys()
+
+	var err error
+	for err == nil {
+		_, err = d.Token()
+	}
+
+	if derr, ok := err.(*DuplicateKeyError); !ok || derr.Offset != 27 {
+		t.Errorf("got error %v, want duplicate key at offset 27", err)
+	}
+}
+
+// renderNode formats a tree of Nodes compactly, for comparison.
+func renderNode(n *Node) string {
+	switch n.Kind {
+	case ObjectStart:
+		var parts []string
+		for i := range n.Children() {
+			m := n.Index(i)
+			parts = append(parts, m.Key()+":"+renderNode(m))
+		}
+		return "{" + strings.Join(parts, ",") + "}"
+	case ArrayStart:
+		var parts []string
+		for i := range n.Children() {
+			parts = append(parts, renderNode(n.Index(i)))
+		}
+		return "[" + strings.Join(parts, ",") + "]"
+	}
+	return string(n.Raw)
+}
+
+var policyTests = []struct {
+	in     string
+	policy DuplicatePolicy
+	out    string
+}{
+	{`{"a": 1, "b": 2, "a": 3}`, KeepDuplicates, `{a:1,b:2,a:3}`},
+	{`{"a": 1, "b": 2, "a": 3}`, FirstWins, `{a:1,b:2}`},
+	{`{"a": 1, "b": 2, "a": 3}`, LastWins, `{a:3,b:2}`},
+	{`{"a": [1], "a": {"b": 1, "b": 2}}`, FirstWins, `{a:[1]}`},
+	{`{"a": [1], "a": {"b": 1, "b": 2}}`, LastWins, `{a:{b:2}}`},
+	{`[{"a": 1, "a": 2}, {"a": 3}]`, LastWins, `[{a:2},{a:3}]`},
+	{`{"a":0,"b":1,"c":2,"d":3,"e":4,"f":5,"g":6,"h":7,"i":8,"j":9,"k":10,"l":11,"m":12,"n":13,"o":14,"p":15,"q":16,"b":17,"a":18}`, LastWins,
+		`{a:18,b:17,c:2,d:3,e:4,f:5,g:6,h:7,i:8,j:9,k:10,l:11,m:12,n:13,o:14,p:15,q:16}`},
+	{`{"a":0,"b":1,"c":2,"d":3,"e":4,"f":5,"g":6,"h":7,"i":8,"j":9,"k":10,"l":11,"m":12,"n":13,"o":14,"p":15,"q":16,"b":17,"a":18}`, FirstWins,
+		`{a:0,b:1,c:2,d:3,e:4,f:5,g:6,h:7,i:8,j:9,k:10,l:11,m:12,n:13,o:14,p:15,q:16}`},
+}
+
+func TestParseWithPolicy(t *testing.T) {
+	for _, test := range policyTests {
+		doc, err := ParseWithPolicy([]byte(test.in), test.policy)
+		if err != nil {
+			t.Errorf("%#q: unexpected error: %s", test.in, err)
+			continue
+		}
+
+		if out := renderNode(doc); out != test.out {
+			t.Errorf("%#q with policy %d:", test.in, test.policy)
+			t.Errorf("  got  %s", out)
+			t.Errorf("  want %s", test.out)
+		}
+	}
+}
@@ -0,0 +1,680 @@
+package jo
+
+import (
+	"cmp"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// Marshal returns the JSON encoding of v, following the same rules as
+// encoding/json's Marshal: struct fields are named and omitted according to
+// their `json` struct tags, map keys are sorted, and types implementing
+// json.Marshaler or encoding.TextMarshaler encode themselves. The characters
+// '<', '>' and '&' are escaped in strings.
+//
+// The output is byte-identical to what encoding/json would produce.
+func Marshal(v any) ([]byte, error) {
+	e := newEncodeState(true, true)
+	defer encodeStatePool.Put(e)
+
+	if err := e.marshal(v); err != nil {
+		return nil, err
+	}
+
+	return append([]byte(nil), e.buf...), nil
+}
+
+// An Encoder writes JSON values to an output stream.
+type Encoder struct {
+	w          io.Writer
+	escapeHTML bool
+	sortKeys   bool
+}
+
+// NewEncoder returns an Encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, escapeHTML: true, sortKeys: true}
+}
+
+// SetEscapeHTML specifies whether the characters '<', '>' and '&' should be
+// escaped in strings. It defaults to true.
+func (enc *Encoder) SetEscapeHTML(on bool) {
+	enc.escapeHTML = on
+}
+
+// SetSortMapKeys specifies whether map entries should be written in order of
+// their keys, rather than in the map's iteration order. It defaults to true.
+func (enc *Encoder) SetSortMapKeys(on bool) {
+	enc.sortKeys = on
+}
+
+// Encode writes the JSON encoding of v to the stream, followed by a newline.
+// Nothing is written if v can't be encoded.
+func (enc *Encoder) Encode(v any) error {
+	e := newEncodeState(enc.escapeHTML, enc.sortKeys)
+	defer encodeStatePool.Put(e)
+
+	if err := e.marshal(v); err != nil {
+		return err
+	}
+	e.buf = append(e.buf, '\n')
+
+	_, err := enc.w.Write(e.buf)
+	return err
+}
+
+// An UnsupportedTypeError is returned when attempting to encode a value of
+// an unsupported type.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return "jo: unsupported type: " + e.Type.String()
+}
+
+// An UnsupportedValueError is returned when attempting to encode an
+// unsupported value, such as NaN or a cyclic data structure.
+type UnsupportedValueError struct {
+	Value reflect.Value
+	Str   string
+}
+
+func (e *UnsupportedValueError) Error() string {
+	return "jo: unsupported value: " + e.Str
+}
+
+// A MarshalerError describes an error returned by a MarshalJSON or
+// MarshalText method, or invalid JSON produced by MarshalJSON.
+type MarshalerError struct {
+	Type   reflect.Type
+	Err    error
+	method string
+}
+
+func (e *MarshalerError) Error() string {
+	return "jo: error calling " + e.method + " for type " + e.Type.String() + ": " + e.Err.Error()
+}
+
+func (e *MarshalerError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	marshalerType     = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// encodeState holds the output and options of a single call to Marshal or
+// Encode. Instances are pooled, so that output buffers can be reused.
+type encodeState struct {
+	buf []byte
+
+	escapeHTML bool
+	sortKeys   bool
+
+	// Number of pointers, maps and slices currently being encoded, and
+	// those seen once there are suspiciously many of them.
+	depth int
+	seen  map[any]struct{}
+}
+
+var encodeStatePool = sync.Pool{
+	New: func() any {
+		return &encodeState{buf: make([]byte, 0, 1024)}
+	},
+}
+
+func newEncodeState(escapeHTML, sortKeys bool) *encodeState {
+	e := encodeStatePool.Get().(*encodeState)
+	e.buf = e.buf[:0]
+	e.escapeHTML = escapeHTML
+	e.sortKeys = sortKeys
+	e.depth = 0
+	clear(e.seen)
+	return e
+}
+
+// marshal appends the encoding of v to the output.
+func (e *encodeState) marshal(v any) error {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		e.buf = append(e.buf, "null"...)
+		return nil
+	}
+	return typeEncoder(rv.Type())(e, rv)
+}
+
+// Cycles are only looked for once this many pointers, maps and slices are
+// being encoded at once, to keep the common case cheap.
+const cycleDepth = 1000
+
+// enter is called before encoding the contents of a pointer, map or slice,
+// and fails if they are already being encoded further up.
+func (e *encodeState) enter(v reflect.Value) error {
+	if e.depth++; e.depth <= cycleDepth {
+		return nil
+	}
+
+	key := e.cycleKey(v)
+	if _, ok := e.seen[key]; ok {
+		return &UnsupportedValueError{v, "encountered a cycle via " + v.Type().String()}
+	}
+
+	if e.seen == nil {
+		e.seen = map[any]struct{}{}
+	}
+	e.seen[key] = struct{}{}
+
+	return nil
+}
+
+// leave undoes enter.
+func (e *encodeState) leave(v reflect.Value) {
+	if e.depth--; e.depth >= cycleDepth {
+		delete(e.seen, e.cycleKey(v))
+	}
+}
+
+// cycleKey identifies the memory a pointer, map or slice refers to. Slices
+// sharing a backing array only collide if their lengths match as well.
+func (e *encodeState) cycleKey(v reflect.Value) any {
+	if v.Kind() == reflect.Slice {
+		return struct {
+			ptr uintptr
+			len int
+		}{v.Pointer(), v.Len()}
+	}
+	return v.Pointer()
+}
+
+// An encoderFunc appends the encoding of v to the output.
+type encoderFunc func(e *encodeState, v reflect.Value) error
+
+var encoderCache sync.Map // map[reflect.Type]encoderFunc
+
+// typeEncoder returns the encoderFunc for values of type t.
+func typeEncoder(t reflect.Type) encoderFunc {
+	if f, ok := encoderCache.Load(t); ok {
+		return f.(encoderFunc)
+	}
+
+	// Recursive types are dealt with the same way as in typeDecoder.
+	var wg sync.WaitGroup
+	var f encoderFunc
+
+	wg.Add(1)
+	fi, loaded := encoderCache.LoadOrStore(t, encoderFunc(func(e *encodeState, v reflect.Value) error {
+		wg.Wait()
+		return f(e, v)
+	}))
+	if loaded {
+		return fi.(encoderFunc)
+	}
+
+	f = newTypeEncoder(t, true)
+	wg.Done()
+	encoderCache.Store(t, f)
+
+	return f
+}
+
+// newTypeEncoder builds an encoderFunc for values of type t. With allowAddr
+// set, methods on *t are used for addressable values.
+func newTypeEncoder(t reflect.Type, allowAddr bool) encoderFunc {
+	if t.Kind() != reflect.Pointer && allowAddr && reflect.PointerTo(t).Implements(marshalerType) {
+		return condAddrEncoder(encodeAddrMarshaler, newTypeEncoder(t, false))
+	}
+	if t.Implements(marshalerType) {
+		return encodeMarshaler
+	}
+	if t.Kind() != reflect.Pointer && allowAddr && reflect.PointerTo(t).Implements(textMarshalerType) {
+		return condAddrEncoder(encodeAddrTextMarshaler, newTypeEncoder(t, false))
+	}
+	if t.Implements(textMarshalerType) {
+		return encodeTextMarshaler
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return encodeBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return encodeInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return encodeUint
+	case reflect.Float32, reflect.Float64:
+		return encodeFloat
+	case reflect.String:
+		if t == numberType {
+			return encodeNumber
+		}
+		return encodeString
+	case reflect.Interface:
+		return encodeInterface
+	case reflect.Struct:
+		return newStructEncoder(t)
+	case reflect.Map:
+		return newMapEncoder(t)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && !reflect.PointerTo(t.Elem()).Implements(marshalerType) && !reflect.PointerTo(t.Elem()).Implements(textMarshalerType) {
+			return encodeBytes
+		}
+		return newSliceEncoder(t)
+	case reflect.Array:
+		return newArrayEncoder(t)
+	case reflect.Pointer:
+		return newPointerEncoder(t)
+	}
+
+	return encodeUnsupported
+}
+
+// condAddrEncoder uses addr for addressable values, and other for the rest.
+func condAddrEncoder(addr, other encoderFunc) encoderFunc {
+	return func(e *encodeState, v reflect.Value) error {
+		if v.CanAddr() {
+			return addr(e, v)
+		}
+		return other(e, v)
+	}
+}
+
+func encodeMarshaler(e *encodeState, v reflect.Value) error {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		e.buf = append(e.buf, "null"...)
+		return nil
+	}
+	return e.marshaler(v.Type(), v.Interface().(json.Marshaler))
+}
+
+func encodeAddrMarshaler(e *encodeState, v reflect.Value) error {
+	return e.marshaler(v.Type(), v.Addr().Interface().(json.Marshaler))
+}
+
+// marshaler appends the output of a MarshalJSON method, which is validated
+// and compacted along the way.
+func (e *encodeState) marshaler(t reflect.Type, m json.Marshaler) error {
+	b, err := m.MarshalJSON()
+	if err == nil {
+		e.buf, err = compact(e.buf, b, e.escapeHTML)
+	}
+	if err != nil {
+		return &MarshalerError{t, err, "MarshalJSON"}
+	}
+	return nil
+}
+
+func encodeTextMarshaler(e *encodeState, v reflect.Value) error {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		e.buf = append(e.buf, "null"...)
+		return nil
+	}
+	return e.textMarshaler(v.Type(), v.Interface().(encoding.TextMarshaler))
+}
+
+func encodeAddrTextMarshaler(e *encodeState, v reflect.Value) error {
+	return e.textMarshaler(v.Type(), v.Addr().Interface().(encoding.TextMarshaler))
+}
+
+// textMarshaler appends the output of a MarshalText method as a string.
+func (e *encodeState) textMarshaler(t reflect.Type, m encoding.TextMarshaler) error {
+	b, err := m.MarshalText()
+	if err != nil {
+		return &MarshalerError{t, err, "MarshalText"}
+	}
+	e.buf = quote(e.buf, string(b), e.escapeHTML)
+	return nil
+}
+
+func encodeBool(e *encodeState, v reflect.Value) error {
+	e.buf = strconv.AppendBool(e.buf, v.Bool())
+	return nil
+}
+
+func encodeInt(e *encodeState, v reflect.Value) error {
+	e.buf = strconv.AppendInt(e.buf, v.Int(), 10)
+	return nil
+}
+
+func encodeUint(e *encodeState, v reflect.Value) error {
+	e.buf = strconv.AppendUint(e.buf, v.Uint(), 10)
+	return nil
+}
+
+func encodeFloat(e *encodeState, v reflect.Value) error {
+	bits := v.Type().Bits()
+
+	f := v.Float()
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return &UnsupportedValueError{v, strconv.FormatFloat(f, 'g', -1, bits)}
+	}
+
+	e.buf = appendFloat(e.buf, f, bits)
+	return nil
+}
+
+func encodeString(e *encodeState, v reflect.Value) error {
+	e.buf = quote(e.buf, v.String(), e.escapeHTML)
+	return nil
+}
+
+func encodeNumber(e *encodeState, v reflect.Value) error {
+	// The zero Number is encoded as 0, for compatibility.
+	n := v.String()
+	if n == "" {
+		n = "0"
+	}
+	if !validNumber([]byte(n)) {
+		return &UnsupportedValueError{v, "invalid number literal " + strconv.Quote(n)}
+	}
+
+	e.buf = append(e.buf, n...)
+	return nil
+}
+
+func encodeBytes(e *encodeState, v reflect.Value) error {
+	if v.IsNil() {
+		e.buf = append(e.buf, "null"...)
+		return nil
+	}
+
+	e.buf = append(e.buf, '"')
+	e.buf = base64.StdEncoding.AppendEncode(e.buf, v.Bytes())
+	e.buf = append(e.buf, '"')
+	return nil
+}
+
+func encodeInterface(e *encodeState, v reflect.Value) error {
+	if v.IsNil() {
+		e.buf = append(e.buf, "null"...)
+		return nil
+	}
+	return typeEncoder(v.Elem().Type())(e, v.Elem())
+}
+
+func encodeUnsupported(e *encodeState, v reflect.Value) error {
+	return &UnsupportedTypeError{v.Type()}
+}
+
+func newPointerEncoder(t reflect.Type) encoderFunc {
+	elem := typeEncoder(t.Elem())
+
+	return func(e *encodeState, v reflect.Value) error {
+		if v.IsNil() {
+			e.buf = append(e.buf, "null"...)
+			return nil
+		}
+
+		if err := e.enter(v); err != nil {
+			return err
+		}
+		err := elem(e, v.Elem())
+		e.leave(v)
+
+		return err
+	}
+}
+
+// A fieldEncoder encodes a single struct field.
+type fieldEncoder struct {
+	index []int
+
+	// The field's quoted name followed by a colon, with and without
+	// HTML characters escaped.
+	key     []byte
+	keyHTML []byte
+
+	omitEmpty bool
+	quoted    bool
+	enc       encoderFunc
+}
+
+func newStructEncoder(t reflect.Type) encoderFunc {
+	fields := cachedFields(t)
+
+	fes := make([]fieldEncoder, len(fields.list))
+	for i, f := range fields.list {
+		fe := &fes[i]
+
+		fe.index = f.index
+		fe.key = append(quote(nil, f.name, false), ':')
+		fe.keyHTML = append(quote(nil, f.name, true), ':')
+		fe.omitEmpty = f.omitEmpty
+		fe.quoted = f.quoted
+
+		// Quoted values are encoded after following any pointers.
+		if ft := f.typ; fe.quoted && ft.Kind() == reflect.Pointer {
+			fe.enc = typeEncoder(ft.Elem())
+		} else {
+			fe.enc = typeEncoder(ft)
+		}
+	}
+
+	return func(e *encodeState, v reflect.Value) error {
+		e.buf = append(e.buf, '{')
+		first := true
+
+	next:
+		for i := range fes {
+			fe := &fes[i]
+
+			// Fields of embedded structs behind nil pointers are
+			// left out.
+			fv := v
+			for _, j := range fe.index {
+				if fv.Kind() == reflect.Pointer {
+					if fv.IsNil() {
+						continue next
+					}
+					fv = fv.Elem()
+				}
+				fv = fv.Field(j)
+			}
+
+			if fe.omitEmpty && isEmptyValue(fv) {
+				continue
+			}
+
+			if !first {
+				e.buf = append(e.buf, ',')
+			}
+			first = false
+
+			if e.escapeHTML {
+				e.buf = append(e.buf, fe.keyHTML...)
+			} else {
+				e.buf = append(e.buf, fe.key...)
+			}
+
+			var err error
+			if fe.quoted {
+				err = e.quoted(fv, fe.enc)
+			} else {
+				err = fe.enc(e, fv)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		e.buf = append(e.buf, '}')
+		return nil
+	}
+}
+
+// quoted encodes a value inside a JSON string, as requested using the
+// "string" struct tag option.
+func (e *encodeState) quoted(v reflect.Value, enc encoderFunc) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			e.buf = append(e.buf, "null"...)
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	// Strings are encoded twice over, anything else is merely wrapped in
+	// quotes.
+	if v.Kind() == reflect.String && v.Type() != numberType {
+		e.buf = quote(e.buf, string(quote(nil, v.String(), e.escapeHTML)), e.escapeHTML)
+		return nil
+	}
+
+	e.buf = append(e.buf, '"')
+	if err := enc(e, v); err != nil {
+		return err
+	}
+	e.buf = append(e.buf, '"')
+
+	return nil
+}
+
+// isEmptyValue reports whether v is considered empty by the "omitempty"
+// struct tag option.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+func newMapEncoder(t reflect.Type) encoderFunc {
+	kt := t.Key()
+
+	switch kt.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		if !kt.Implements(textMarshalerType) {
+			return encodeUnsupported
+		}
+	}
+
+	elem := typeEncoder(t.Elem())
+
+	type entry struct {
+		key string
+		v   reflect.Value
+	}
+
+	return func(e *encodeState, v reflect.Value) error {
+		if v.IsNil() {
+			e.buf = append(e.buf, "null"...)
+			return nil
+		}
+
+		if err := e.enter(v); err != nil {
+			return err
+		}
+		defer e.leave(v)
+
+		entries := make([]entry, 0, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			k, err := mapKeyString(iter.Key())
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry{k, iter.Value()})
+		}
+
+		if e.sortKeys {
+			slices.SortFunc(entries, func(a, b entry) int {
+				return cmp.Compare(a.key, b.key)
+			})
+		}
+
+		e.buf = append(e.buf, '{')
+		for i, en := range entries {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
+			}
+			e.buf = quote(e.buf, en.key, e.escapeHTML)
+			e.buf = append(e.buf, ':')
+
+			if err := elem(e, en.v); err != nil {
+				return err
+			}
+		}
+		e.buf = append(e.buf, '}')
+
+		return nil
+	}
+}
+
+// mapKeyString converts a map key into an object key.
+func mapKeyString(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if k.Kind() == reflect.Pointer && k.IsNil() {
+			return "", nil
+		}
+		b, err := tm.MarshalText()
+		if err != nil {
+			return "", &MarshalerError{k.Type(), err, "MarshalText"}
+		}
+		return string(b), nil
+	}
+
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	default:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+}
+
+func newSliceEncoder(t reflect.Type) encoderFunc {
+	array := newArrayEncoder(t)
+
+	return func(e *encodeState, v reflect.Value) error {
+		if v.IsNil() {
+			e.buf = append(e.buf, "null"...)
+			return nil
+		}
+
+		if err := e.enter(v); err != nil {
+			return err
+		}
+		err := array(e, v)
+		e.leave(v)
+
+		return err
+	}
+}
+
+func newArrayEncoder(t reflect.Type) encoderFunc {
+	elem := typeEncoder(t.Elem())
+
+	return func(e *encodeState, v reflect.Value) error {
+		e.buf = append(e.buf, '[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
+			}
+			if err := elem(e, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		e.buf = append(e.buf, ']')
+
+		return nil
+	}
+}
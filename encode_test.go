@@ -0,0 +1,259 @@
+package jo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func ExampleMarshal() {
+	type item struct {
+		Name  string   `json:"name"`
+		Price float64  `json:"price"`
+		Tags  []string `json:"tags,omitempty"`
+	}
+
+	b, err := Marshal([]item{{"tea", 3.5, []string{"hot"}}, {"<cake>", 1e21, nil}})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(b))
+	// Output:
+	// [{"name":"tea","price":3.5,"tags":["hot"]},{"name":"\u003ccake\u003e","price":1e+21}]
+}
+
+func ExampleEncoder() {
+	enc := NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+
+	enc.Encode(map[string]any{"b": "<b>", "a": []int{1, 2}})
+	enc.Encode(nil)
+	// Output:
+	// {"a":[1,2],"b":"<b>"}
+	// null
+}
+
+type encodeText struct {
+	s string
+}
+
+func (t encodeText) MarshalText() ([]byte, error) {
+	if t.s == "fail" {
+		return nil, errors.New("no")
+	}
+	return []byte("text:" + t.s), nil
+}
+
+type encodeJSON struct {
+	raw string
+}
+
+func (j *encodeJSON) MarshalJSON() ([]byte, error) {
+	return []byte(j.raw), nil
+}
+
+type encodeOuter struct {
+	DecodeEmbedded
+	*decodeHidden
+	*encodeInner
+
+	Name     string             `json:"name"`
+	Count    int64              `json:"count,string"`
+	Label    string             `json:"label,string"`
+	Ptr      *int               `json:"ptr,string"`
+	Ratio    float32            `json:"ratio"`
+	Flag     bool               `json:"flag,omitempty"`
+	Ignored  string             `json:"-"`
+	Dash     string             `json:"-,"`
+	Empty    []int              `json:"empty,omitempty"`
+	Nil      []int              `json:"nil"`
+	Map      map[string]int     `json:"map"`
+	IntMap   map[int8]string    `json:"int_map"`
+	TextMap  map[encodeText]int `json:"text_map"`
+	Any      any                `json:"any"`
+	Raw      json.RawMessage    `json:"raw"`
+	Time     time.Time          `json:"time"`
+	Num      json.Number        `json:"num"`
+	Bytes    []byte             `json:"bytes"`
+	Arr      [3]byte            `json:"arr"`
+	Text     encodeText         `json:"text"`
+	TextPtr  *encodeText        `json:"text_ptr"`
+	JSON     encodeJSON         `json:"json"`
+	Nested   map[string][]*int  `json:"nested"`
+	Zero     struct{}           `json:"zero,omitempty"`
+	IP       net.IP             `json:"ip"`
+	Big      *big.Int           `json:"big"`
+	Untagged string
+	private  string
+}
+
+type encodeInner struct {
+	Name  string `json:"name"`
+	Inner int    `json:"inner"`
+}
+
+type encodeCycle struct {
+	Next *encodeCycle
+}
+
+var marshalTests = []any{
+	nil,
+	true,
+	-12,
+	uint8(200),
+	1.5,
+	float32(0.1),
+	float32(1e21),
+	1e-7,
+	"plain <html> &   \xff",
+	[]string{},
+	[]string(nil),
+	[]byte("hello"),
+	[2]int{1, 2},
+	map[string]any{"z": 1, "a": []any{nil, true, "x"}, "<": map[string]int{}},
+	map[int]bool{10: true, -2: false, 3: true},
+	map[uint]string{1: "a"},
+	&encodeOuter{},
+	encodeOuter{
+		DecodeEmbedded: DecodeEmbedded{"e", 2},
+		decodeHidden:   &decodeHidden{"h"},
+		encodeInner:    &encodeInner{"shadowed", 7},
+		Name:           "name",
+		Count:          12,
+		Label:          "l\"x",
+		Ptr:            new(int),
+		Ratio:          0.3,
+		Flag:           true,
+		Ignored:        "ignored",
+		Dash:           "dash",
+		Empty:          []int{1},
+		Map:            map[string]int{"y": 2, "x": 1},
+		IntMap:         map[int8]string{-1: "a", 2: "b"},
+		TextMap:        map[encodeText]int{{"b"}: 1, {"a"}: 2},
+		Any:            map[string]any{"k": []any{1.5, "two"}},
+		Raw:            json.RawMessage(` { "a" : [ 1 , 2 ] } `),
+		Time:           time.Date(2010, 8, 2, 21, 27, 44, 0, time.UTC),
+		Num:            "1.000000000000000001",
+		Bytes:          []byte{0, 1, 2},
+		Arr:            [3]byte{1, 2, 3},
+		Text:           encodeText{"t"},
+		TextPtr:        &encodeText{"p"},
+		JSON:           encodeJSON{`"ignored unless addressable"`},
+		Nested:         map[string][]*int{"a": {nil, new(int)}},
+		IP:             net.IPv4(127, 0, 0, 1),
+		Big:            big.NewInt(1 << 62),
+		Untagged:       "u",
+		private:        "p",
+	},
+	&struct{ JSON encodeJSON }{encodeJSON{` [ 1 , "<" ] `}},
+	&decodeRecursive{Value: 1, Next: &decodeRecursive{Value: 2}, Kids: []decodeRecursive{{Value: 3}}},
+}
+
+func TestMarshalDifferential(t *testing.T) {
+	for _, v := range marshalTests {
+		want, wantErr := json.Marshal(v)
+		got, err := Marshal(v)
+
+		if (err == nil) != (wantErr == nil) || !bytes.Equal(got, want) {
+			t.Errorf("Marshal(%T):", v)
+			t.Errorf("  got  %s, %v", got, err)
+			t.Errorf("  want %s, %v", want, wantErr)
+		}
+	}
+}
+
+func TestEncoderDifferential(t *testing.T) {
+	for _, v := range marshalTests {
+		for _, escapeHTML := range []bool{true, false} {
+			var buf, want bytes.Buffer
+
+			enc := NewEncoder(&buf)
+			enc.SetEscapeHTML(escapeHTML)
+			err := enc.Encode(v)
+
+			wenc := json.NewEncoder(&want)
+			wenc.SetEscapeHTML(escapeHTML)
+			wantErr := wenc.Encode(v)
+
+			if (err == nil) != (wantErr == nil) || buf.String() != want.String() {
+				t.Errorf("Encode(%T) with escapeHTML %v:", v, escapeHTML)
+				t.Errorf("  got  %s, %v", buf.String(), err)
+				t.Errorf("  want %s, %v", want.String(), wantErr)
+			}
+		}
+	}
+}
+
+func TestEncoderUnsortedKeys(t *testing.T) {
+	m := map[string]int{}
+	for i := 0; i < 100; i++ {
+		m[fmt.Sprint(i)] = i
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.SetSortMapKeys(false)
+
+	if err := enc.Encode(m); err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]int
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil || !reflect.DeepEqual(got, m) {
+		t.Errorf("got %s, %v", buf.String(), err)
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	cycle := &encodeCycle{}
+	cycle.Next = cycle
+
+	tests := []struct {
+		v   any
+		err any
+		msg string
+	}{
+		{math.NaN(), new(*UnsupportedValueError), "jo: unsupported value: NaN"},
+		{[]float32{float32(math.Inf(-1))}, new(*UnsupportedValueError), "jo: unsupported value: -Inf"},
+		{json.Number("1x"), new(*UnsupportedValueError), `jo: unsupported value: invalid number literal "1x"`},
+		{cycle, new(*UnsupportedValueError), "jo: unsupported value: encountered a cycle via *jo.encodeCycle"},
+		{make(chan int), new(*UnsupportedTypeError), "jo: unsupported type: chan int"},
+		{map[[2]int]int{}, new(*UnsupportedTypeError), "jo: unsupported type: map[[2]int]int"},
+		{&encodeJSON{"[1,"}, new(*MarshalerError), "jo: error calling MarshalJSON for type *jo.encodeJSON: unexpected end of JSON input"},
+		{encodeText{"fail"}, new(*MarshalerError), "jo: error calling MarshalText for type jo.encodeText: no"},
+	}
+
+	for _, test := range tests {
+		_, err := Marshal(test.v)
+		if !errors.As(err, test.err) || err.Error() != test.msg {
+			t.Errorf("Marshal(%T): got error %v, want %q", test.v, err, test.msg)
+		}
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	var v any
+	if err := json.Unmarshal([]byte(sample), &v); err != nil {
+		b.Fatal(err)
+	}
+
+	out, _ := Marshal(v)
+
+	b.SetBytes(int64(len(out)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := Marshal(v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
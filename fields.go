@@ -0,0 +1,240 @@
+package jo
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// A field describes a struct field as seen by encoding/json.
+type field struct {
+	// JSON name and Go name.
+	name   string
+	goName string
+
+	// Index sequence for reflect.Value.FieldByIndex.
+	index []int
+	typ   reflect.Type
+
+	// Whether the name came from a struct tag, and the tag options.
+	tagged    bool
+	omitEmpty bool
+	quoted    bool
+}
+
+// structFields holds the fields of a struct type, in declaration order.
+type structFields struct {
+	list   []field
+	byName map[string]int
+}
+
+// lookup returns the index of the field matching a decoded key, preferring
+// an exact match over a case-insensitive one. It returns -1 if no field
+// matches.
+func (sf *structFields) lookup(key string) int {
+	if i, ok := sf.byName[key]; ok {
+		return i
+	}
+	for i := range sf.list {
+		if strings.EqualFold(sf.list[i].name, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+var fieldCache sync.Map // map[reflect.Type]*structFields
+
+// cachedFields returns the fields of struct type t.
+func cachedFields(t reflect.Type) *structFields {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.(*structFields)
+	}
+	f, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return f.(*structFields)
+}
+
+// typeFields returns the fields which encoding/json would recognize for the
+// struct type t, applying the same rules for embedded structs: fields of
+// embedded structs are promoted, shallower fields hide deeper ones, and at
+// equal depth a tagged field hides untagged ones while other conflicts
+// cancel out.
+func typeFields(t reflect.Type) *structFields {
+	var current []field
+	var next = []field{{typ: t}}
+
+	// Number of times each struct type has been seen at the current and
+	// next level of embedding.
+	var count, nextCount map[reflect.Type]int
+
+	var visited = map[reflect.Type]bool{}
+	var fields []field
+
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[reflect.Type]int{}
+
+		for _, f := range current {
+			if visited[f.typ] {
+				continue
+			}
+			visited[f.typ] = true
+
+			for i := 0; i < f.typ.NumField(); i++ {
+				sf := f.typ.Field(i)
+
+				if sf.Anonymous {
+					t := sf.Type
+					if t.Kind() == reflect.Pointer {
+						t = t.Elem()
+					}
+					if !sf.IsExported() && t.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+
+				name, opts, _ := strings.Cut(tag, ",")
+				if !isValidTag(name) {
+					name = ""
+				}
+
+				index := make([]int, len(f.index)+1)
+				copy(index, f.index)
+				index[len(f.index)] = i
+
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+
+				// Embedded structs without a name of their own have their
+				// fields promoted at the next level.
+				if name == "" && sf.Anonymous && ft.Kind() == reflect.Struct {
+					nextCount[ft]++
+					if nextCount[ft] == 1 {
+						next = append(next, field{name: ft.Name(), index: index, typ: ft})
+					}
+					continue
+				}
+
+				quoted := false
+				if hasOption(opts, "string") {
+					switch ft.Kind() {
+					case reflect.Bool,
+						reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+						reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+						reflect.Float32, reflect.Float64,
+						reflect.String:
+						quoted = true
+					}
+				}
+
+				fields = append(fields, field{
+					name:      name,
+					goName:    sf.Name,
+					index:     index,
+					typ:       sf.Type,
+					tagged:    name != "",
+					omitEmpty: hasOption(opts, "omitempty"),
+					quoted:    quoted,
+				})
+				if name == "" {
+					fields[len(fields)-1].name = sf.Name
+				}
+
+				// A struct type embedded more than once at the same level
+				// produces duplicates, which cancel out below.
+				if count[f.typ] > 1 {
+					fields = append(fields, fields[len(fields)-1])
+				}
+			}
+		}
+	}
+
+	// Sort by name, breaking ties by depth, then by whether the field
+	// was tagged, and finally by index sequence.
+	slices.SortFunc(fields, func(a, b field) int {
+		if c := strings.Compare(a.name, b.name); c != 0 {
+			return c
+		}
+		if c := len(a.index) - len(b.index); c != 0 {
+			return c
+		}
+		if a.tagged != b.tagged {
+			if a.tagged {
+				return -1
+			}
+			return +1
+		}
+		return slices.Compare(a.index, b.index)
+	})
+
+	// Keep only the dominant field for each name.
+	out := fields[:0]
+	for i := 0; i < len(fields); {
+		j := i + 1
+		for j < len(fields) && fields[j].name == fields[i].name {
+			j++
+		}
+
+		// The first field of the group is the shallowest, and tagged
+		// ones sort first among equals. It only dominates if no other
+		// field is equally deep and equally tagged.
+		if j == i+1 || len(fields[i+1].index) > len(fields[i].index) || fields[i].tagged && !fields[i+1].tagged {
+			out = append(out, fields[i])
+		}
+
+		i = j
+	}
+
+	slices.SortFunc(out, func(a, b field) int {
+		return slices.Compare(a.index, b.index)
+	})
+
+	sf := &structFields{list: out, byName: make(map[string]int, len(out))}
+	for i, f := range out {
+		sf.byName[f.name] = i
+	}
+
+	return sf
+}
+
+// hasOption reports whether a comma-separated list of tag options contains
+// the given option.
+func hasOption(opts, name string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isValidTag reports whether a struct tag name is acceptable to
+// encoding/json.
+func isValidTag(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", c):
+			// Backslash and quote chars are reserved, but otherwise any
+			// punctuation chars are allowed in a tag name.
+		case !unicode.IsLetter(c) && !unicode.IsDigit(c):
+			return false
+		}
+	}
+	return true
+}
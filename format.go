@@ -0,0 +1,351 @@
+package jo
+
+import (
+	"io"
+)
+
+// Compact appends the JSON value in src to dst with all insignificant
+// whitespace removed. The output is identical to that of encoding/json's
+// Compact. If src isn't valid JSON, dst is returned unchanged along with a
+// *SyntaxError.
+func Compact(dst, src []byte) ([]byte, error) {
+	return compact(dst, src, false)
+}
+
+// Indent appends an indented form of the JSON value in src to dst. Each
+// element of an object or array begins on a new line, starting with prefix
+// followed by one or more copies of indent according to its nesting depth.
+// The output is identical to that of encoding/json's Indent, which means
+// the first line isn't prefixed, and trailing whitespace is kept. If src
+// isn't valid JSON, dst is returned unchanged along with a *SyntaxError.
+func Indent(dst, src []byte, prefix, indent string) ([]byte, error) {
+	f := newFormatter(prefix, indent)
+	f.dst = dst
+
+	for _, c := range src {
+		if err := f.format(c); err != nil {
+			return dst, err
+		}
+	}
+	if err := f.end(); err != nil {
+		return dst, err
+	}
+
+	return f.dst, nil
+}
+
+// A Reformatter rewrites the JSON value written to it, validating it along
+// the way, and passes the result on to an io.Writer. By default its output
+// is compact; see SetIndent and SetShortArrays for alternatives.
+//
+// Input can be fed to a Reformatter using Write, or by copying it from an
+// io.Reader using io.Copy. Once all of it has been written, Close checks
+// that the value was complete and flushes any buffered output.
+type Reformatter struct {
+	w   io.Writer
+	f   formatter
+	err error
+}
+
+// NewReformatter returns a Reformatter writing to w.
+func NewReformatter(w io.Writer) *Reformatter {
+	r := &Reformatter{w: w, f: *newFormatter("", "")}
+	r.f.compact = true
+	return r
+}
+
+// SetIndent makes the Reformatter indent its output the same way Indent
+// does. It must be called before anything is written.
+func (r *Reformatter) SetIndent(prefix, indent string) {
+	r.f.compact = false
+	r.f.prefix = prefix
+	r.f.indent = indent
+}
+
+// SetShortArrays makes the Reformatter keep arrays on a single line, as long
+// as they contain no objects or arrays and fit within a line of maxWidth
+// bytes, including indentation. It only has an effect on indented output,
+// and must be called before anything is written.
+func (r *Reformatter) SetShortArrays(maxWidth int) {
+	r.f.maxWidth = maxWidth
+}
+
+// Write reformats the bytes in p. A *SyntaxError is returned as soon as
+// invalid input is detected, in which case only the input preceding the
+// error has been consumed.
+func (r *Reformatter) Write(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	for i, c := range p {
+		if r.err = r.f.format(c); r.err != nil {
+			return i, r.err
+		}
+	}
+
+	// Tentative output can't be flushed, as it may still be rewritten.
+	if r.f.short < 0 && len(r.f.dst) >= 4096 {
+		if r.err = r.flush(); r.err != nil {
+			return len(p), r.err
+		}
+	}
+
+	return len(p), nil
+}
+
+// Close checks that a complete value has been written, and flushes any
+// buffered output. It doesn't close the underlying io.Writer.
+func (r *Reformatter) Close() error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.err = r.f.end(); r.err != nil {
+		return r.err
+	}
+	r.err = r.flush()
+	return r.err
+}
+
+// flush writes out all buffered output.
+func (r *Reformatter) flush() error {
+	_, err := r.w.Write(r.f.dst)
+	r.f.lineStart -= len(r.f.dst)
+	r.f.dst = r.f.dst[:0]
+	return err
+}
+
+// A formatter rewrites JSON input one byte at a time, appending the output
+// to dst.
+type formatter struct {
+	s   Scanner
+	dst []byte
+
+	// Input offset of the next byte, for error reporting.
+	off int64
+
+	compact        bool
+	prefix, indent string
+
+	// Nesting depth of the output. While needIndent is set, the opening
+	// delimiter of an object or array has been written, but the depth
+	// hasn't been increased yet, pending the first element.
+	depth      int
+	needIndent bool
+
+	// Whether a key or string is in progress, and whether a value has
+	// been started at all.
+	inString bool
+	started  bool
+
+	// Output index of the current line's start.
+	lineStart int
+
+	// Maximum line width for arrays kept on a single line, or zero if all
+	// arrays are to be indented. While an array is being written out on a
+	// single line, short holds the output index of its opening bracket
+	// and elems the output indices of its elements; otherwise short is -1.
+	maxWidth int
+	short    int
+	elems    []int
+}
+
+func newFormatter(prefix, indent string) *formatter {
+	f := &formatter{prefix: prefix, indent: indent, short: -1}
+	f.s.Reset()
+	return f
+}
+
+// format processes a single byte of input.
+func (f *formatter) format(c byte) error {
+	ev := f.s.Scan(c)
+	if ev == Error {
+		return f.s.syntaxError(f.off)
+	}
+	f.off++
+
+	if ev&(StringEnd|KeyEnd) != 0 {
+		f.inString = false
+	}
+
+	if f.inString {
+		f.dst = append(f.dst, c)
+		f.checkWidth()
+		return nil
+	}
+
+	if ev&Space != 0 {
+		// Like encoding/json, keep whitespace following the top-level
+		// value when indenting.
+		if !f.compact && f.started && f.depth == 0 && !f.needIndent {
+			f.dst = append(f.dst, c)
+		}
+		return nil
+	}
+	f.started = true
+
+	if f.needIndent && c != '}' && c != ']' {
+		f.needIndent = false
+		f.depth++
+		if f.short >= 0 {
+			f.elems = append(f.elems, len(f.dst))
+		} else {
+			f.newline()
+		}
+	}
+
+	switch c {
+	case '{', '[':
+		if f.short >= 0 {
+			f.expand()
+		}
+		if c == '[' && f.maxWidth > 0 && !f.compact {
+			f.short = len(f.dst)
+			f.elems = f.elems[:0]
+		}
+		f.needIndent = true
+		f.dst = append(f.dst, c)
+
+	case ',':
+		f.dst = append(f.dst, c)
+		if f.short >= 0 {
+			f.dst = append(f.dst, ' ')
+			f.elems = append(f.elems, len(f.dst))
+		} else {
+			f.newline()
+		}
+
+	case ':':
+		f.dst = append(f.dst, c)
+		if !f.compact {
+			f.dst = append(f.dst, ' ')
+		}
+
+	case '}', ']':
+		// Whether an array fits on a single line depends on its closing
+		// bracket too.
+		if f.short >= 0 && len(f.dst)+1-f.lineStart > f.maxWidth {
+			f.expand()
+		}
+
+		if f.needIndent {
+			f.needIndent = false
+		} else {
+			f.depth--
+			if f.short < 0 {
+				f.newline()
+			}
+		}
+		f.dst = append(f.dst, c)
+		f.short = -1
+
+	default:
+		f.dst = append(f.dst, c)
+	}
+
+	if ev&(StringStart|KeyStart) != 0 {
+		f.inString = true
+	}
+
+	f.checkWidth()
+	return nil
+}
+
+// end handles the end of input.
+func (f *formatter) end() error {
+	if f.s.End() == Error {
+		return f.s.syntaxError(f.off)
+	}
+	return nil
+}
+
+// newline starts a new line at the current depth, unless the output is
+// compact.
+func (f *formatter) newline() {
+	if f.compact {
+		return
+	}
+
+	f.dst = append(f.dst, '\n')
+	f.lineStart = len(f.dst)
+
+	f.dst = append(f.dst, f.prefix...)
+	for i := 0; i < f.depth; i++ {
+		f.dst = append(f.dst, f.indent...)
+	}
+}
+
+// checkWidth gives up on keeping the current array on a single line once it
+// has grown too wide.
+func (f *formatter) checkWidth() {
+	if f.short >= 0 && len(f.dst)-f.lineStart > f.maxWidth {
+		f.expand()
+	}
+}
+
+// expand rewrites the array currently kept on a single line so that each
+// element starts on a line of its own.
+func (f *formatter) expand() {
+	short := f.short
+	line := append([]byte(nil), f.dst[short:]...)
+
+	f.dst = f.dst[:short+1]
+	f.short = -1
+
+	for i, start := range f.elems {
+		end := len(line)
+		if i+1 < len(f.elems) {
+			// Leave out the ", " separating elements.
+			end = f.elems[i+1] - short - 2
+		}
+
+		f.newline()
+		f.dst = append(f.dst, line[start-short:end]...)
+		if i+1 < len(f.elems) {
+			f.dst = append(f.dst, ',')
+		}
+	}
+}
+
+// compact appends the JSON value in src to dst with all insignificant
+// whitespace removed, validating it along the way. With escapeHTML set, the
+// characters '<', '>' and '&' as well as U+2028 and U+2029 are escaped.
+func compact(dst, src []byte, escapeHTML bool) ([]byte, error) {
+	const hex = "0123456789abcdef"
+
+	var s Scanner
+	s.Reset()
+
+	n := len(dst)
+
+	for i, c := range src {
+		ev := s.Scan(c)
+		if ev == Error {
+			return dst[:n], s.syntaxError(int64(i))
+		}
+		if ev&Space != 0 {
+			continue
+		}
+
+		if escapeHTML {
+			if c == '<' || c == '>' || c == '&' {
+				dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+				continue
+			}
+
+			// Both U+2028 and U+2029 are encoded as E2 80 A8/A9 in UTF-8.
+			if c&^1 == 0xA8 && i >= 2 && src[i-2] == 0xE2 && src[i-1] == 0x80 {
+				dst = append(dst[:len(dst)-2], '\\', 'u', '2', '0', '2', hex[c&0xF])
+				continue
+			}
+		}
+
+		dst = append(dst, c)
+	}
+
+	if s.End() == Error {
+		return dst[:n], s.syntaxError(int64(len(src)))
+	}
+
+	return dst, nil
+}
@@ -0,0 +1,248 @@
+package jo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func ExampleIndent() {
+	out, err := Indent(nil, []byte(`{"a":[1,2],"b":{}}`), "", "\t")
+	if err != nil {
+		panic(err)
+	}
+	os.Stdout.Write(out)
+	// Output:
+	// {
+	// 	"a": [
+	// 		1,
+	// 		2
+	// 	],
+	// 	"b": {}
+	// }
+}
+
+func ExampleReformatter() {
+	r := NewReformatter(os.Stdout)
+	r.SetIndent("", "  ")
+	r.SetShortArrays(40)
+
+	io.Copy(r, strings.NewReader(`{"point": [1.5, -2], "rows": [[1, 2, 3], ["a very long string", "another long string"]]}`))
+	if err := r.Close(); err != nil {
+		panic(err)
+	}
+	// Output:
+	// {
+	//   "point": [1.5, -2],
+	//   "rows": [
+	//     [1, 2, 3],
+	//     [
+	//       "a very long string",
+	//       "another long string"
+	//     ]
+	//   ]
+	// }
+}
+
+var formatTests = []string{
+	`null`,
+	` -1.5e3 `,
+	"\t\"x y\" \n",
+	`[]`,
+	`{ }`,
+	`[ [ ] , { } ]`,
+	` [1, {"a":[], "b" : {"c":"d , e : f"}}, {}, "é\"\\"] ` + "\n\t",
+	`{"a":{"b":{"c":[1,2,[3,[4]]]}}}`,
+	sample,
+}
+
+func TestCompactDifferential(t *testing.T) {
+	for _, in := range formatTests {
+		var want bytes.Buffer
+		json.Compact(&want, []byte(in))
+
+		got, err := Compact([]byte("prefix"), []byte(in))
+		if err != nil || string(got) != "prefix"+want.String() {
+			t.Errorf("Compact(%#q):", in)
+			t.Errorf("  got  %#q, %v", got, err)
+			t.Errorf("  want %#q", want.String())
+		}
+	}
+}
+
+func TestIndentDifferential(t *testing.T) {
+	for _, in := range formatTests {
+		for _, indent := range [][2]string{{"", "  "}, {">", "\t"}, {"", ""}} {
+			var want bytes.Buffer
+			json.Indent(&want, []byte(in), indent[0], indent[1])
+
+			got, err := Indent([]byte("prefix"), []byte(in), indent[0], indent[1])
+			if err != nil || string(got) != "prefix"+want.String() {
+				t.Errorf("Indent(%#q, %q, %q):", in, indent[0], indent[1])
+				t.Errorf("  got  %#q, %v", got, err)
+				t.Errorf("  want %#q", want.String())
+			}
+		}
+	}
+}
+
+func TestFormatErrors(t *testing.T) {
+	for _, test := range []struct {
+		in     string
+		offset int64
+	}{
+		{``, 0},
+		{` [1,]`, 4},
+		{`{"a" 1}`, 5},
+		{`[1] [2]`, 4},
+		{`{"a": tru}`, 9},
+		{`[1, 2`, 5},
+	} {
+		for name, fn := range map[string]func() ([]byte, error){
+			"Compact": func() ([]byte, error) { return Compact([]byte("x"), []byte(test.in)) },
+			"Indent":  func() ([]byte, error) { return Indent([]byte("x"), []byte(test.in), "", "  ") },
+		} {
+			out, err := fn()
+
+			var serr *SyntaxError
+			if !errors.As(err, &serr) || serr.Offset != test.offset || string(out) != "x" {
+				t.Errorf("%s(%#q): got %#q, %v, want error at offset %d", name, test.in, out, err, test.offset)
+			}
+		}
+	}
+}
+
+func TestReformatter(t *testing.T) {
+	for _, in := range formatTests {
+		for _, indent := range []bool{false, true} {
+			var got bytes.Buffer
+
+			r := NewReformatter(&got)
+			if indent {
+				r.SetIndent(">", "  ")
+			}
+
+			_, err := io.Copy(r, iotest.OneByteReader(strings.NewReader(in)))
+			if err == nil {
+				err = r.Close()
+			}
+
+			var want []byte
+			if indent {
+				want, _ = Indent(nil, []byte(in), ">", "  ")
+			} else {
+				want, _ = Compact(nil, []byte(in))
+			}
+
+			if err != nil || got.String() != string(want) {
+				t.Errorf("Reformatter(%#q) with indent %v:", in, indent)
+				t.Errorf("  got  %#q, %v", got.String(), err)
+				t.Errorf("  want %#q", want)
+			}
+		}
+	}
+}
+
+func TestReformatterShortArrays(t *testing.T) {
+	for _, test := range []struct {
+		in       string
+		maxWidth int
+		out      string
+	}{
+		{`[1,2,3]`, 10, "[1, 2, 3]"},
+		{`[1,2,3]`, 8, "[\n  1,\n  2,\n  3\n]"},
+		{`[]`, 1, "[]"},
+		{`[[]]`, 10, "[\n  []\n]"},
+		{`{"a":[true,null]}`, 20, "{\n  \"a\": [true, null]\n}"},
+		{`{"a":[true,null]}`, 18, "{\n  \"a\": [\n    true,\n    null\n  ]\n}"},
+		{`[1,{"b":2},3]`, 80, "[\n  1,\n  {\n    \"b\": 2\n  },\n  3\n]"},
+		{`[{"b":2}]`, 80, "[\n  {\n    \"b\": 2\n  }\n]"},
+		{`[[1,2],["x, y"]]`, 80, "[\n  [1, 2],\n  [\"x, y\"]\n]"},
+		{`["abc","defgh"]`, 10, "[\n  \"abc\",\n  \"defgh\"\n]"},
+	} {
+		var got bytes.Buffer
+
+		r := NewReformatter(&got)
+		r.SetIndent("", "  ")
+		r.SetShortArrays(test.maxWidth)
+
+		_, err := r.Write([]byte(test.in))
+		if err == nil {
+			err = r.Close()
+		}
+
+		if err != nil || got.String() != test.out {
+			t.Errorf("Reformatter(%#q) with max width %d:", test.in, test.maxWidth)
+			t.Errorf("  got  %#q, %v", got.String(), err)
+			t.Errorf("  want %#q", test.out)
+		}
+	}
+}
+
+func TestReformatterErrors(t *testing.T) {
+	var got bytes.Buffer
+	r := NewReformatter(&got)
+
+	n, err := r.Write([]byte(`[1, 2}`))
+
+	var serr *SyntaxError
+	if !errors.As(err, &serr) || serr.Offset != 5 || n != 5 {
+		t.Errorf("got %d, %v, want error at offset 5", n, err)
+	}
+	if err2 := r.Close(); err2 != err {
+		t.Errorf("got error %v from Close, want %v", err2, err)
+	}
+
+	r = NewReformatter(&got)
+	r.Write([]byte(`{"a":`))
+	if err := r.Close(); err == nil {
+		t.Errorf("got no error from Close after incomplete input")
+	}
+}
+
+func TestCompact(t *testing.T) {
+	for _, in := range []string{
+		`null`, " \t[ 1 , { \"a b\" : \"<&>\" } , \" \" ]\n", `{}`,
+	} {
+		for _, escapeHTML := range []bool{true, false} {
+			got, err := compact([]byte("prefix"), []byte(in), escapeHTML)
+
+			var want bytes.Buffer
+			want.WriteString("prefix")
+			json.Compact(&want, []byte(in))
+			if escapeHTML {
+				var b bytes.Buffer
+				json.HTMLEscape(&b, want.Bytes())
+				want = b
+			}
+
+			if err != nil || string(got) != want.String() {
+				t.Errorf("compact(%#q, %v): got %#q, %v, want %#q", in, escapeHTML, got, err, want.String())
+			}
+		}
+	}
+
+	for _, in := range []string{``, `[1,]`, `{} {}`, `"a`} {
+		if got, err := compact([]byte("prefix"), []byte(in), false); err == nil || string(got) != "prefix" {
+			t.Errorf("compact(%#q): got %#q, %v, want error", in, got, err)
+		}
+	}
+}
+
+func BenchmarkIndent(b *testing.B) {
+	var data = []byte(sample)
+	var dst []byte
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		dst, _ = Indent(dst[:0], data, "", "  ")
+	}
+}
@@ -0,0 +1,239 @@
+package jo
+
+import (
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// The declarations in this file support code generated by cmd/jogen, which
+// decodes straight from a Tokenizer without relying on reflection. They
+// report errors the same way Unmarshal does.
+
+// DecodeBool decodes a boolean token into *p. A null token leaves *p
+// untouched.
+func DecodeBool[T ~bool](tok Token, p *T) error {
+	switch tok.Kind {
+	case NullStart:
+	case BoolStart:
+		*p = tok.Raw[0] == 't'
+	default:
+		return Mismatch[T](tok)
+	}
+	return nil
+}
+
+// DecodeString decodes a string token into *p. A null token leaves *p
+// untouched.
+func DecodeString[T ~string](tok Token, p *T) error {
+	switch tok.Kind {
+	case NullStart:
+	case StringStart:
+		*p = T(unquote(nil, tok.Raw))
+	default:
+		return Mismatch[T](tok)
+	}
+	return nil
+}
+
+// DecodeInt decodes a number token into *p. A null token leaves *p
+// untouched.
+func DecodeInt[T ~int | ~int8 | ~int16 | ~int32 | ~int64](tok Token, p *T) error {
+	switch tok.Kind {
+	case NullStart:
+	case NumberStart:
+		n, err := strconv.ParseInt(string(tok.Raw), 10, 64)
+		if err != nil || int64(T(n)) != n {
+			return Mismatch[T](tok)
+		}
+		*p = T(n)
+	default:
+		return Mismatch[T](tok)
+	}
+	return nil
+}
+
+// DecodeUint decodes a number token into *p. A null token leaves *p
+// untouched.
+func DecodeUint[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr](tok Token, p *T) error {
+	switch tok.Kind {
+	case NullStart:
+	case NumberStart:
+		n, err := strconv.ParseUint(string(tok.Raw), 10, 64)
+		if err != nil || uint64(T(n)) != n {
+			return Mismatch[T](tok)
+		}
+		*p = T(n)
+	default:
+		return Mismatch[T](tok)
+	}
+	return nil
+}
+
+// DecodeFloat decodes a number token into *p. A null token leaves *p
+// untouched.
+func DecodeFloat[T ~float32 | ~float64](tok Token, p *T) error {
+	switch tok.Kind {
+	case NullStart:
+	case NumberStart:
+		// Only a float32 loses the last bit of 2^24+1.
+		bits := 64
+		if float64(T(1<<24+1)) != 1<<24+1 {
+			bits = 32
+		}
+
+		f, err := strconv.ParseFloat(string(tok.Raw), bits)
+		if err != nil {
+			return Mismatch[T](tok)
+		}
+		*p = T(f)
+	default:
+		return Mismatch[T](tok)
+	}
+	return nil
+}
+
+// DecodeQuoted decodes the scalar value found inside a string token by
+// passing it to fn, as requested by the "string" struct tag option. A null
+// token is ignored.
+func DecodeQuoted[T any](tok Token, fn func(Token) error) error {
+	if tok.Kind == NullStart {
+		return nil
+	}
+	if tok.Kind != StringStart {
+		return Mismatch[T](tok)
+	}
+
+	inner := unquote(nil, tok.Raw)
+	t := NewBytesTokenizer(inner)
+
+	itok, err := t.Next()
+	if err != nil || itok.Kind == ObjectStart || itok.Kind == ArrayStart {
+		return mismatch[T](tok, errInvalidQuoted)
+	}
+	if err := fn(itok); err != nil {
+		return mismatch[T](tok, errInvalidQuoted)
+	}
+	if _, err := t.Next(); err != io.EOF {
+		return mismatch[T](tok, errInvalidQuoted)
+	}
+
+	return nil
+}
+
+// Mismatch returns an *UnmarshalError reporting that the value starting with
+// tok can't be stored in a Go value of type T.
+func Mismatch[T any](tok Token) error {
+	return mismatch[T](tok, nil)
+}
+
+func mismatch[T any](tok Token, err error) error {
+	desc := kindName(tok.Kind)
+	if tok.Kind == NumberStart {
+		desc += " " + string(tok.Raw)
+	}
+
+	return &UnmarshalError{
+		Value:  desc,
+		Type:   reflect.TypeFor[T](),
+		Offset: tok.Offset,
+		Err:    err,
+	}
+}
+
+// MatchKey returns the index of the name matching a quoted key, preferring
+// an exact match over a case-insensitive one, or -1 if none matches.
+func MatchKey(raw []byte, names []string) int {
+	key := Unquote(raw)
+
+	for i, name := range names {
+		if name == key {
+			return i
+		}
+	}
+	for i, name := range names {
+		if strings.EqualFold(name, key) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// AtField prepends a struct field to the Go path of an *UnmarshalError.
+// Other errors are returned as they are.
+func AtField(err error, name string) error {
+	if e, ok := err.(*UnmarshalError); ok {
+		e.Path = name + dotted(e.Path)
+	}
+	return err
+}
+
+// AtIndex prepends a slice or array index to the Go path of an
+// *UnmarshalError. Other errors are returned as they are.
+func AtIndex(err error, i int) error {
+	if e, ok := err.(*UnmarshalError); ok {
+		e.Path = "[" + strconv.Itoa(i) + "]" + dotted(e.Path)
+	}
+	return err
+}
+
+// AtKey prepends a map key to the Go path of an *UnmarshalError. Other
+// errors are returned as they are.
+func AtKey(err error, key string) error {
+	if e, ok := err.(*UnmarshalError); ok {
+		e.Path = "[" + strconv.Quote(key) + "]" + dotted(e.Path)
+	}
+	return err
+}
+
+// dotted prefixes a Go path starting with a field name with a dot.
+func dotted(path string) string {
+	if path != "" && path[0] != '[' {
+		return "." + path
+	}
+	return path
+}
+
+// DecodeWith passes the bytes of the value starting with tok to fn, which
+// would typically be the UnmarshalJSON method of a value of type T.
+func DecodeWith[T any](t *Tokenizer, tok Token, fn func([]byte) error) error {
+	raw, err := t.RawValue(tok)
+	if err != nil {
+		return err
+	}
+	if err := fn(raw); err != nil {
+		return mismatch[T](tok, err)
+	}
+	return nil
+}
+
+// DecodeValue decodes the value starting with tok into the Go value pointed
+// to by p using Unmarshal, for types which generated code can't handle.
+func DecodeValue(t *Tokenizer, tok Token, p any) error {
+	raw, err := t.RawValue(tok)
+	if err != nil {
+		return err
+	}
+	return Value{Kind: tok.Kind, Raw: raw, Offset: tok.Offset}.Decode(p)
+}
+
+// UnmarshalWith decodes the JSON document in data using fn, making sure that
+// nothing but whitespace follows the decoded value.
+func UnmarshalWith(data []byte, fn func(*Tokenizer, Token) error) error {
+	t := NewBytesTokenizer(data)
+
+	tok, err := t.Next()
+	if err != nil {
+		return err
+	}
+	if err := fn(t, tok); err != nil {
+		return err
+	}
+	if _, err := t.Next(); err != io.EOF {
+		return err
+	}
+
+	return nil
+}
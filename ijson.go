@@ -0,0 +1,126 @@
+package jo
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strconv"
+)
+
+// IJSONLimits are the limits enforced by UseIJSON. They're generous enough
+// for most documents exchanged between programs, while keeping hostile
+// input from consuming unbounded resources.
+var IJSONLimits = Limits{
+	MaxDepth:        256,
+	MaxStringLength: 16 << 20,
+	MaxNumberDigits: 1024,
+}
+
+// An IJSONError reports input which is valid JSON, but not I-JSON.
+type IJSONError struct {
+	msg string
+
+	// Offset is the input offset of the offending byte.
+	Offset int64
+}
+
+// Error returns a description of the problem.
+func (e *IJSONError) Error() string {
+	return e.msg
+}
+
+// UseIJSON restricts t to input conforming to the I-JSON profile (RFC 7493),
+// which requires that:
+//
+//   - strings and keys are valid UTF-8, without unpaired surrogate escapes,
+//   - keys are unique within their object, and
+//   - numbers are within the range of IEEE 754 double precision, neither
+//     overflowing to infinity nor underflowing to zero.
+//
+// Violations are reported as an *IJSONError or *DuplicateKeyError. UseIJSON
+// also sets IJSONLimits, which may be replaced by calling SetLimits later.
+//
+// I-JSON recommends against integers which can't be represented exactly as
+// doubles, but doesn't forbid them; see WarnImpreciseIntegers.
+func (t *Tokenizer) UseIJSON() {
+	t.ijson = true
+	t.DisallowDuplicateKeys()
+	t.SetLimits(IJSONLimits)
+}
+
+// UseIJSON restricts d to input conforming to the I-JSON profile, as with
+// Tokenizer.UseIJSON.
+func (d *Decoder) UseIJSON() {
+	d.t.UseIJSON()
+}
+
+// WarnImpreciseIntegers causes t to call fn with each integer outside the
+// range [-(2^53)+1, 2^53-1], which readers of I-JSON aren't guaranteed to
+// represent exactly. Calling it with a nil fn removes any previous function.
+func (t *Tokenizer) WarnImpreciseIntegers(fn func(tok Token)) {
+	t.warn = fn
+}
+
+// ValidateIJSON checks that data holds a single JSON value conforming to the
+// I-JSON profile, as described for Tokenizer.UseIJSON.
+func ValidateIJSON(data []byte) error {
+	t := NewBytesTokenizer(data)
+	t.UseIJSON()
+
+	for {
+		if _, err := t.Next(); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// checkIJSON checks that a token conforms to the I-JSON profile.
+func checkIJSON(tok Token) error {
+	switch tok.Kind {
+	case StringStart, KeyStart:
+		if i, msg := checkString(tok.Raw); msg != "" {
+			return &IJSONError{msg, tok.Offset + int64(i)}
+		}
+
+	case NumberStart:
+		// Rounding is fine, but not to infinity or zero.
+		f, _ := strconv.ParseFloat(string(tok.Raw), 64)
+		if math.IsInf(f, 0) || f == 0 && !isZero(tok.Raw) {
+			return &IJSONError{"number " + string(tok.Raw) + " is out of range for IEEE 754 double precision", tok.Offset}
+		}
+	}
+
+	return nil
+}
+
+// isImpreciseInteger reports whether raw is an integer literal whose
+// magnitude is at least 2^53.
+func isImpreciseInteger(raw []byte) bool {
+	if bytes.ContainsAny(raw, ".eE") {
+		return false
+	}
+
+	digits := bytes.TrimPrefix(raw, []byte{'-'})
+	if len(digits) < 16 {
+		return false
+	}
+	if len(digits) > 16 {
+		return true
+	}
+	return string(digits) >= "9007199254740992"
+}
+
+// isZero reports whether a number literal denotes zero.
+func isZero(raw []byte) bool {
+	for _, c := range raw {
+		if c == 'e' || c == 'E' {
+			break
+		}
+		if '1' <= c && c <= '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package jo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func ExampleValidateIJSON() {
+	for _, in := range []string{
+		`{"name": "Tom", "id": 1e3}`,
+		`{"name": "\ud83d"}`,
+		`{"name": "Tom", "name": "Tim"}`,
+		`{"id": 1e400}`,
+	} {
+		fmt.Println(ValidateIJSON([]byte(in)))
+	}
+	// Output:
+	// <nil>
+	// unpaired surrogate escape \ud83d in string literal
+	// duplicate key "name" at $.name
+	// number 1e400 is out of range for IEEE 754 double precision
+}
+
+var ijsonTests = []struct {
+	in string
+
+	// Offset of the violation, or -1 if there is none.
+	offset int64
+}{
+	{`["abc", "é😀", "é😀", 1.7976931348623157e308, 4.9e-324, -0.0e-400]`, -1},
+	{`{"a": 1, "b": {"a": 2}}`, -1},
+	{"[\"ab\xffc\"]", 4},
+	{"{\"\xc3\": 1}", 2},
+	{"[\"\xed\xa0\x80\"]", 2},
+	{`["x", "\ude00"]`, 7},
+	{`["\ud83dx"]`, 2},
+	{`["\ud83dA"]`, 2},
+	{`["\ud83d😀"]`, 2},
+	{`{"\ud83d": 1}`, 2},
+	{`[1e309]`, 1},
+	{`[-2e308]`, 1},
+	{`[0, -1e-400]`, 4},
+	{`[0.00001e-320]`, 1},
+	{`{"a": 1, "a": 2}`, 9},
+}
+
+func TestValidateIJSON(t *testing.T) {
+	for _, test := range ijsonTests {
+		err := ValidateIJSON([]byte(test.in))
+
+		if test.offset < 0 {
+			if err != nil {
+				t.Errorf("%#q: unexpected error: %s", test.in, err)
+			}
+			continue
+		}
+
+		var off int64 = -1
+		switch err := err.(type) {
+		case *IJSONError:
+			off = err.Offset
+		case *DuplicateKeyError:
+			off = err.Offset
+		default:
+			t.Errorf("%#q: got error %v, want *IJSONError", test.in, err)
+			continue
+		}
+
+		if off != test.offset {
+			t.Errorf("%#q: got error at offset %d, want %d", test.in, off, test.offset)
+		}
+	}
+}
+
+func TestUseIJSONLimits(t *testing.T) {
+	in := strings.Repeat("[", IJSONLimits.MaxDepth+1) + strings.Repeat("]", IJSONLimits.MaxDepth+1)
+
+	var lerr *LimitError
+	if err := ValidateIJSON([]byte(in)); !errors.As(err, &lerr) || lerr.Limit != DepthLimit {
+		t.Errorf("got error %v, want DepthLimit", err)
+	}
+}
+
+func TestWarnImpreciseIntegers(t *testing.T) {
+	in := `[9007199254740991, 9007199254740992, -9007199254740993, 12345678901234567890, 9007199254740993.0, 1e300]`
+
+	var got []string
+	tz := NewBytesTokenizer([]byte(in))
+	tz.UseIJSON()
+	tz.WarnImpreciseIntegers(func(tok Token) {
+		got = append(got, fmt.Sprintf("%s at %d", tok.Raw, tok.Offset))
+	})
+
+	if _, err := readTokens(tz); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := []string{
+		"9007199254740992 at 19",
+		"-9007199254740993 at 37",
+		"12345678901234567890 at 56",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got  %q", got)
+		t.Errorf("want %q", want)
+	}
+}
@@ -0,0 +1,161 @@
+package jo
+
+import (
+	"errors"
+	"io"
+	"iter"
+	"strconv"
+)
+
+// A Value refers to a single JSON value which has been validated, but not
+// decoded.
+type Value struct {
+	// Kind is the Start event of the value, or Error if the value
+	// could not be scanned.
+	Kind Event
+
+	// Raw holds the value's bytes exactly as they appeared in the input.
+	Raw []byte
+
+	// Offset is the input offset of the value's first byte.
+	Offset int64
+
+	err error
+}
+
+// Err returns the error which prevented a value from being scanned, if any.
+func (v Value) Err() error {
+	return v.err
+}
+
+// Text decodes a string value.
+func (v Value) Text() (string, error) {
+	if v.Kind != StringStart {
+		return "", v.mismatch("string")
+	}
+	return string(unquote(nil, v.Raw)), nil
+}
+
+// Int64 decodes a number value as a signed integer.
+func (v Value) Int64() (int64, error) {
+	if v.Kind != NumberStart {
+		return 0, v.mismatch("number")
+	}
+	return strconv.ParseInt(string(v.Raw), 10, 64)
+}
+
+// Float64 decodes a number value as a floating point number.
+func (v Value) Float64() (float64, error) {
+	if v.Kind != NumberStart {
+		return 0, v.mismatch("number")
+	}
+	return strconv.ParseFloat(string(v.Raw), 64)
+}
+
+// Bool decodes a boolean value.
+func (v Value) Bool() (bool, error) {
+	if v.Kind != BoolStart {
+		return false, v.mismatch("boolean")
+	}
+	return v.Raw[0] == 't', nil
+}
+
+// mismatch generates an error for a value of the wrong kind.
+func (v Value) mismatch(want string) error {
+	if v.Kind == Error {
+		return v.err
+	}
+	return errors.New("jo: " + kindName(v.Kind) + " value is not a " + want)
+}
+
+// kindName returns a short description of the value kind represented by a
+// Start event.
+func kindName(kind Event) string {
+	switch kind {
+	case ObjectStart:
+		return "object"
+	case ArrayStart:
+		return "array"
+	case StringStart:
+		return "string"
+	case NumberStart:
+		return "number"
+	case BoolStart:
+		return "boolean"
+	case NullStart:
+		return "null"
+	}
+	return "invalid"
+}
+
+// Tokens returns an iterator over the tokens of the JSON value read from r.
+// Iteration stops after the first error.
+func Tokens(r io.Reader) iter.Seq2[Token, error] {
+	return func(yield func(Token, error) bool) {
+		t := NewTokenizer(r)
+
+		for {
+			tok, err := t.Next()
+			if err == io.EOF {
+				return
+			}
+			if !yield(tok, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Elements returns an iterator over the elements of the top-level array in
+// data. Elements are validated, but not decoded, as the iteration proceeds.
+//
+// If data turns out to be malformed, or isn't an array, the iteration ends
+// with a Value whose Kind is Error.
+func Elements(data []byte) iter.Seq[Value] {
+	return func(yield func(Value) bool) {
+		var s = NewScanner()
+		var depth = 0
+		var start = -1
+		var kind Event
+
+		for i, c := range data {
+			ev := s.Scan(c)
+
+			if ev == Error {
+				yield(Value{Kind: Error, Offset: int64(i), err: s.syntaxError(int64(i))})
+				return
+			}
+
+			if ev&(ObjectEnd|ArrayEnd) != 0 {
+				depth--
+			}
+			if ev&(End&^KeyEnd) != 0 && depth == 1 && start >= 0 {
+				if !yield(Value{Kind: kind, Raw: data[start:i], Offset: int64(start)}) {
+					return
+				}
+				start = -1
+			}
+
+			if ev&Start == 0 {
+				continue
+			}
+
+			if depth == 0 && ev != ArrayStart {
+				yield(Value{Kind: Error, Offset: int64(i), err: &SyntaxError{msg: "top-level value is not an array", Offset: int64(i)}})
+				return
+			}
+
+			if depth == 1 {
+				start = i
+				kind = ev
+			}
+			if ev&(ObjectStart|ArrayStart) != 0 {
+				depth++
+			}
+		}
+
+		if s.End() == Error {
+			yield(Value{Kind: Error, Offset: int64(len(data)), err: s.syntaxError(int64(len(data)))})
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package jo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func ExampleTokens() {
+	for tok, err := range Tokens(strings.NewReader(`{"a": [1, true]}`)) {
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+		fmt.Printf("%s %s\n", tok.Kind, tok.Raw)
+	}
+	// Output:
+	// ObjectStart {
+	// KeyStart "a"
+	// ArrayStart [
+	// NumberStart 1
+	// BoolStart true
+	// ArrayEnd ]
+	// ObjectEnd }
+}
+
+func ExampleElements() {
+	for v := range Elements([]byte(`[{"id": 1}, "two", 3]`)) {
+		fmt.Printf("%s %s\n", v.Kind, v.Raw)
+	}
+	// Output:
+	// ObjectStart {"id": 1}
+	// StringStart "two"
+	// NumberStart 3
+}
+
+var elementsTests = []struct {
+	in  string
+	out []string
+	err bool
+}{
+	{` [ ] `, nil, false},
+	{`[1,[2,[3]], {"a":[]} ,null]`, []string{`1`, `[2,[3]]`, `{"a":[]}`, `null`}, false},
+	{`["a\"",true,false]`, []string{`"a\""`, `true`, `false`}, false},
+	{`[1,2,}`, []string{`1`, `2`}, true},
+	{`[1,2] x`, []string{`1`, `2`}, true},
+	{`{"a":1}`, nil, true},
+	{`[`, nil, true},
+}
+
+func TestElements(t *testing.T) {
+	for _, test := range elementsTests {
+		var out []string
+		var err error
+
+		for v := range Elements([]byte(test.in)) {
+			if v.Kind == Error {
+				err = v.Err()
+				break
+			}
+			out = append(out, string(v.Raw))
+		}
+
+		if (err != nil) != test.err {
+			t.Errorf("Elements(%#q): got error %v", test.in, err)
+		}
+
+		if strings.Join(out, " ") != strings.Join(test.out, " ") {
+			t.Errorf("Elements(%#q):", test.in)
+			t.Errorf("  got  %q", out)
+			t.Errorf("  want %q", test.out)
+		}
+	}
+}
+
+func TestValueDecoding(t *testing.T) {
+	var vs []Value
+	for v := range Elements([]byte(`["aé😀\n", -12, 1.5, true]`)) {
+		vs = append(vs, v)
+	}
+
+	if s, err := vs[0].Text(); err != nil || s != "aé\U0001F600\n" {
+		t.Errorf("Text: got %q, %v", s, err)
+	}
+	if n, err := vs[1].Int64(); err != nil || n != -12 {
+		t.Errorf("Int64: got %d, %v", n, err)
+	}
+	if f, err := vs[2].Float64(); err != nil || f != 1.5 {
+		t.Errorf("Float64: got %g, %v", f, err)
+	}
+	if b, err := vs[3].Bool(); err != nil || !b {
+		t.Errorf("Bool: got %v, %v", b, err)
+	}
+	if _, err := vs[3].Text(); err == nil {
+		t.Errorf("Text: expected error for boolean value")
+	}
+}
@@ -127,6 +127,10 @@ type Scanner struct {
 
 	// Persisted syntax error.
 	err error
+
+	// Resource limits, if any have been set, in which case state is
+	// always limited and the actual state is kept by the limiter.
+	lim *limiter
 }
 
 // NewScanner initializes a new Scanner.
@@ -136,11 +140,40 @@ func NewScanner() *Scanner {
 	return s
 }
 
-// Reset restores a Scanner to its initial state.
+// Reset restores a Scanner to its initial state. Any limits remain in place.
 func (s *Scanner) Reset() {
+	s.restart()
+	if s.lim != nil {
+		s.lim.input = 0
+	}
+}
+
+// restart prepares s for another top-level value, without resetting the
+// count of input bytes limited by MaxInputLength.
+func (s *Scanner) restart() {
 	s.state = beforeValue
 	s.stack = append(s.stack[:0], afterTopValue)
 	s.err = nil
+	if s.lim != nil {
+		s.lim.restart(s)
+	}
+}
+
+// copyFrom makes s an independent copy of t, reusing s's stack.
+func (s *Scanner) copyFrom(t *Scanner) {
+	s.state = t.state
+	s.stack = append(s.stack[:0], t.stack...)
+	s.end = t.end
+	s.err = t.err
+
+	if t.lim == nil {
+		s.lim = nil
+	} else {
+		if s.lim == nil {
+			s.lim = new(limiter)
+		}
+		s.lim.copyFrom(t.lim)
+	}
 }
 
 // Scan accepts a byte of input and returns an Event.
@@ -151,6 +184,11 @@ func (s *Scanner) Scan(c byte) Event {
 // End signals the Scanner that the end of input has been reached. It returns
 // an event just as Scan does.
 func (s *Scanner) End() Event {
+	// The whitespace fed to the state function isn't part of the input.
+	if s.lim != nil {
+		s.lim.input--
+	}
+
 	// Feeding the state function whitespace may trigger NumberEnd events.
 	// Note the mask operation to filter out the actual Space bit.
 	ev := s.state(s, ' ') & (^Space)
@@ -171,6 +209,23 @@ func (s *Scanner) LastError() error {
 	return s.err
 }
 
+// Validate checks that data holds a single JSON value. If it doesn't, the
+// returned *SyntaxError describes the first problem found.
+func Validate(data []byte) error {
+	s := NewScanner()
+
+	for i, c := range data {
+		if s.Scan(c) == Error {
+			return s.syntaxError(int64(i))
+		}
+	}
+	if s.End() == Error {
+		return s.syntaxError(int64(len(data)))
+	}
+
+	return nil
+}
+
 // errorf generates and persists an error.
 func (s *Scanner) errorf(str string, args ...interface{}) Event {
 	s.state = afterError
@@ -178,6 +233,12 @@ func (s *Scanner) errorf(str string, args ...interface{}) Event {
 	return Error
 }
 
+// syntaxError wraps the persisted error in a *SyntaxError for the given
+// input offset.
+func (s *Scanner) syntaxError(off int64) *SyntaxError {
+	return &SyntaxError{msg: s.err.Error(), Offset: off, err: s.err}
+}
+
 // push pushes a state function onto the stack.
 func (s *Scanner) push(fn func(*Scanner, byte) Event) {
 	s.stack = append(s.stack, fn)
@@ -234,7 +295,28 @@ func beforeValue(s *Scanner, c byte) Event {
 		return NullStart
 	}
 
-	return s.errorf(`invalid character %q in place of value start`)
+	return s.valueError(c, "value")
+}
+
+// valueError reports an invalid character where a value should have started,
+// explaining the most common mistakes.
+func (s *Scanner) valueError(c byte, want string) Event {
+	switch c {
+	case '\'':
+		return s.errorf(`invalid character %q: expected %s; strings must be enclosed in double quotes`, c, want)
+	case 'N', 'I':
+		return s.errorf(`invalid character %q: expected %s; NaN and Infinity are not valid JSON numbers`, c, want)
+	}
+	return s.errorf(`invalid character %q: expected %s`, c, want)
+}
+
+// keyError reports an invalid character where an object key should have
+// started, explaining the most common mistakes.
+func (s *Scanner) keyError(c byte, want string) Event {
+	if c == '\'' || c == '_' || c == '$' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' {
+		return s.errorf(`invalid character %q: expected %s; object keys must be strings enclosed in double quotes`, c, want)
+	}
+	return s.errorf(`invalid character %q: expected %s`, c, want)
 }
 
 func beforeFirstObjectKey(s *Scanner, c byte) Event {
@@ -249,7 +331,7 @@ func beforeFirstObjectKey(s *Scanner, c byte) Event {
 		return s.delay(ObjectEnd)
 	}
 
-	return s.errorf(`invalid character %q in object`, c)
+	return s.keyError(c, "object key or '}'")
 }
 
 func afterObjectKey(s *Scanner, c byte) Event {
@@ -261,7 +343,7 @@ func afterObjectKey(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q after object key`, c)
+	return s.errorf(`invalid character %q: expected ':' after object key`, c)
 }
 
 func afterObjectValue(s *Scanner, c byte) Event {
@@ -274,7 +356,7 @@ func afterObjectValue(s *Scanner, c byte) Event {
 		return s.delay(ObjectEnd)
 	}
 
-	return s.errorf(`invalid character %q after object value`, c)
+	return s.errorf(`invalid character %q: expected ',' or '}' after object value`, c)
 }
 
 func afterObjectComma(s *Scanner, c byte) Event {
@@ -287,7 +369,10 @@ func afterObjectComma(s *Scanner, c byte) Event {
 		return KeyStart
 	}
 
-	return s.errorf(`invalid character %q in place of object key`, c)
+	if c == '}' {
+		return s.errorf(`invalid character '}': expected object key after ','; trailing commas are not allowed`)
+	}
+	return s.keyError(c, "object key after ','")
 }
 
 func beforeFirstArrayElement(s *Scanner, c byte) Event {
@@ -298,21 +383,35 @@ func beforeFirstArrayElement(s *Scanner, c byte) Event {
 	}
 
 	s.push(afterArrayElement)
-	return beforeValue(s, c)
+	if ev := beforeValue(s, c); ev != Error {
+		return ev
+	}
+
+	return s.valueError(c, "value or ']'")
 }
 
 func afterArrayElement(s *Scanner, c byte) Event {
 	if table[c]&isSpace != 0 {
 		return Space
 	} else if c == ',' {
-		s.state = beforeValue
-		s.push(afterArrayElement)
+		s.state = afterArrayComma
 		return None
 	} else if c == ']' {
 		return s.delay(ArrayEnd)
 	}
 
-	return s.errorf(`invalid character %q after array element`, c)
+	return s.errorf(`invalid character %q: expected ',' or ']' after array element`, c)
+}
+
+func afterArrayComma(s *Scanner, c byte) Event {
+	if table[c]&isSpace != 0 {
+		return Space
+	} else if c == ']' {
+		return s.errorf(`invalid character ']': expected value after ','; trailing commas are not allowed`)
+	}
+
+	s.push(afterArrayElement)
+	return beforeValue(s, c)
 }
 
 func afterQuote(s *Scanner, c byte) Event {
@@ -328,7 +427,7 @@ func afterQuote(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q in string literal`, c)
+	return s.errorf(`invalid character %q in string literal; control characters must be escaped`, c)
 }
 
 func afterEsc(s *Scanner, c byte) Event {
@@ -340,7 +439,7 @@ func afterEsc(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q in character escape`, c)
+	return s.errorf(`invalid character %q in character escape: expected one of '"', '\', '/', 'b', 'f', 'n', 'r', 't' or 'u'`, c)
 }
 
 func afterEscU(s *Scanner, c byte) Event {
@@ -349,7 +448,7 @@ func afterEscU(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q in hexadecimal character escape`, c)
+	return s.errorf(`invalid character %q in \u escape: expected hexadecimal digit`, c)
 }
 
 func afterEscU1(s *Scanner, c byte) Event {
@@ -358,7 +457,7 @@ func afterEscU1(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q in hexadecimal character escape`, c)
+	return s.errorf(`invalid character %q in \u escape: expected hexadecimal digit`, c)
 }
 
 func afterEscU12(s *Scanner, c byte) Event {
@@ -367,7 +466,7 @@ func afterEscU12(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q in hexadecimal character escape`, c)
+	return s.errorf(`invalid character %q in \u escape: expected hexadecimal digit`, c)
 }
 
 func afterEscU123(s *Scanner, c byte) Event {
@@ -376,7 +475,7 @@ func afterEscU123(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q in hexadecimal character escape`, c)
+	return s.errorf(`invalid character %q in \u escape: expected hexadecimal digit`, c)
 }
 
 func afterMinus(s *Scanner, c byte) Event {
@@ -388,7 +487,10 @@ func afterMinus(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q after "-"`, c)
+	if c == 'I' {
+		return s.errorf(`invalid character 'I': expected digit after '-'; NaN and Infinity are not valid JSON numbers`)
+	}
+	return s.errorf(`invalid character %q: expected digit after '-'`, c)
 }
 
 func afterZero(s *Scanner, c byte) Event {
@@ -417,7 +519,7 @@ func afterDot(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q after decimal point in numeric literal`, c)
+	return s.errorf(`invalid character %q: expected digit after decimal point`, c)
 }
 
 func afterDotDigit(s *Scanner, c byte) Event {
@@ -440,7 +542,7 @@ func afterE(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q in exponent of numeric literal`, c)
+	return s.errorf(`invalid character %q: expected digit, '+' or '-' in exponent`, c)
 }
 
 func afterESign(s *Scanner, c byte) Event {
@@ -449,7 +551,7 @@ func afterESign(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q in exponent of numeric literal`, c)
+	return s.errorf(`invalid character %q: expected digit in exponent`, c)
 }
 
 func afterEDigit(s *Scanner, c byte) Event {
@@ -466,7 +568,7 @@ func afterT(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q after "t"`, c)
+	return s.errorf(`invalid character %q: expected 'r' in literal true`, c)
 }
 
 func afterTr(s *Scanner, c byte) Event {
@@ -475,7 +577,7 @@ func afterTr(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q after "tr"`, c)
+	return s.errorf(`invalid character %q: expected 'u' in literal true`, c)
 }
 
 func afterTru(s *Scanner, c byte) Event {
@@ -483,7 +585,7 @@ func afterTru(s *Scanner, c byte) Event {
 		return s.delay(BoolEnd)
 	}
 
-	return s.errorf(`invalid character %q after "tru"`, c)
+	return s.errorf(`invalid character %q: expected 'e' in literal true`, c)
 }
 
 func afterF(s *Scanner, c byte) Event {
@@ -492,7 +594,7 @@ func afterF(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q after "f"`, c)
+	return s.errorf(`invalid character %q: expected 'a' in literal false`, c)
 }
 
 func afterFa(s *Scanner, c byte) Event {
@@ -501,7 +603,7 @@ func afterFa(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q after "fa"`, c)
+	return s.errorf(`invalid character %q: expected 'l' in literal false`, c)
 }
 
 func afterFal(s *Scanner, c byte) Event {
@@ -510,7 +612,7 @@ func afterFal(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q after "fal"`, c)
+	return s.errorf(`invalid character %q: expected 's' in literal false`, c)
 }
 
 func afterFals(s *Scanner, c byte) Event {
@@ -518,7 +620,7 @@ func afterFals(s *Scanner, c byte) Event {
 		return s.delay(BoolEnd)
 	}
 
-	return s.errorf(`invalid character %q after "fals"`, c)
+	return s.errorf(`invalid character %q: expected 'e' in literal false`, c)
 }
 
 func afterN(s *Scanner, c byte) Event {
@@ -527,7 +629,7 @@ func afterN(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q after "n"`, c)
+	return s.errorf(`invalid character %q: expected 'u' in literal null`, c)
 }
 
 func afterNu(s *Scanner, c byte) Event {
@@ -536,7 +638,7 @@ func afterNu(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q after "nu"`, c)
+	return s.errorf(`invalid character %q: expected 'l' in literal null`, c)
 }
 
 func afterNul(s *Scanner, c byte) Event {
@@ -544,11 +646,14 @@ func afterNul(s *Scanner, c byte) Event {
 		return s.delay(NullEnd)
 	}
 
-	return s.errorf(`invalid character %q after "nul"`, c)
+	return s.errorf(`invalid character %q: expected 'l' in literal null`, c)
 }
 
 func delayed(s *Scanner, c byte) Event {
-	return s.next(c) | s.end
+	// The next state function may itself schedule an end event, so s.end
+	// has to be read before invoking it.
+	ev := s.end
+	return s.next(c) | ev
 }
 
 func afterTopValue(s *Scanner, c byte) Event {
@@ -556,7 +661,7 @@ func afterTopValue(s *Scanner, c byte) Event {
 		return Space
 	}
 
-	return s.errorf(`invalid character %q after top-level value`, c)
+	return s.errorf(`invalid character %q: expected end of input after top-level value`, c)
 }
 
 func afterError(s *Scanner, c byte) Event {
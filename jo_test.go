@@ -132,6 +132,33 @@ var scannerTests = []struct {
 			None,             // EOF
 		},
 	},
+	{
+		`[null]`,
+		[]Event{
+			ArrayStart, // '['
+			NullStart,  // 'n'
+			None,       // 'u'
+			None,       // 'l'
+			None,       // 'l'
+			NullEnd,    // ']'
+			ArrayEnd,   // EOF
+		},
+	},
+	{
+		`{"a":"b"}`,
+		[]Event{
+			ObjectStart, // '{'
+			KeyStart,    // '"'
+			None,        // 'a'
+			None,        // '"'
+			KeyEnd,      // ':'
+			StringStart, // '"'
+			None,        // 'b'
+			None,        // '"'
+			StringEnd,   // '}'
+			ObjectEnd,   // EOF
+		},
+	},
 	{
 		`[[],[[]]]`,
 		[]Event{
@@ -675,6 +702,76 @@ func TestScannerErrors(t *testing.T) {
 	}
 }
 
+var errorMessageTests = []struct {
+	in, msg string
+}{
+	{`x`, `invalid character 'x': expected value`},
+	{`{"a":1 "b":2}`, `invalid character '"': expected ',' or '}' after object value`},
+	{`{"a" 1}`, `invalid character '1': expected ':' after object key`},
+	{`[1 2]`, `invalid character '2': expected ',' or ']' after array element`},
+	{`[x]`, `invalid character 'x': expected value or ']'`},
+	{`{"a":}`, `invalid character '}': expected value`},
+	{`[1]]`, `invalid character ']': expected end of input after top-level value`},
+	{`-x`, `invalid character 'x': expected digit after '-'`},
+	{`1.x`, `invalid character 'x': expected digit after decimal point`},
+	{`1ex`, `invalid character 'x': expected digit, '+' or '-' in exponent`},
+	{`1e+x`, `invalid character 'x': expected digit in exponent`},
+	{`trux`, `invalid character 'x': expected 'e' in literal true`},
+	{`fx`, `invalid character 'x': expected 'a' in literal false`},
+	{`nulx`, `invalid character 'x': expected 'l' in literal null`},
+	{`"\x"`, `invalid character 'x' in character escape: expected one of '"', '\', '/', 'b', 'f', 'n', 'r', 't' or 'u'`},
+	{`"\u12x4"`, `invalid character 'x' in \u escape: expected hexadecimal digit`},
+	{"\"a\tb\"", `invalid character '\t' in string literal; control characters must be escaped`},
+	{`[1`, `unexpected end of JSON input`},
+
+	// Common mistakes.
+	{`{"a":1,}`, `invalid character '}': expected object key after ','; trailing commas are not allowed`},
+	{`[1, 2, ]`, `invalid character ']': expected value after ','; trailing commas are not allowed`},
+	{`['a']`, `invalid character '\'': expected value or ']'; strings must be enclosed in double quotes`},
+	{`{"a":'b'}`, `invalid character '\'': expected value; strings must be enclosed in double quotes`},
+	{`{'a':1}`, `invalid character '\'': expected object key or '}'; object keys must be strings enclosed in double quotes`},
+	{`{a:1}`, `invalid character 'a': expected object key or '}'; object keys must be strings enclosed in double quotes`},
+	{`{"a":1, b:2}`, `invalid character 'b': expected object key after ','; object keys must be strings enclosed in double quotes`},
+	{`{"a":NaN}`, `invalid character 'N': expected value; NaN and Infinity are not valid JSON numbers`},
+	{`[1, Infinity]`, `invalid character 'I': expected value; NaN and Infinity are not valid JSON numbers`},
+	{`-Infinity`, `invalid character 'I': expected digit after '-'; NaN and Infinity are not valid JSON numbers`},
+}
+
+func TestErrorMessages(t *testing.T) {
+	for _, test := range errorMessageTests {
+		err := Validate([]byte(test.in))
+		if err == nil || err.Error() != test.msg {
+			t.Errorf("Validate(%#q):", test.in)
+			t.Errorf("  got  %v", err)
+			t.Errorf("  want %s", test.msg)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, test := range []struct {
+		in     string
+		offset int64
+	}{
+		{` {"a": [1, true, null]} `, -1},
+		{`0`, -1},
+		{``, 0},
+		{`{"a" 1}`, 5},
+		{`[1, 2`, 5},
+		{`1 2`, 2},
+	} {
+		err := Validate([]byte(test.in))
+
+		if test.offset < 0 {
+			if err != nil {
+				t.Errorf("Validate(%#q): got error %v", test.in, err)
+			}
+		} else if serr, ok := err.(*SyntaxError); !ok || serr.Offset != test.offset {
+			t.Errorf("Validate(%#q): got error %v, want *SyntaxError at offset %d", test.in, err, test.offset)
+		}
+	}
+}
+
 var sample = `
 {
    "data": [
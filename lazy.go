@@ -0,0 +1,282 @@
+package jo
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// A LazyValue is a JSON value which is only parsed as far as it has been
+// accessed. Initially only its byte range is known; the members or elements
+// of an object or array are parsed the first time any of them is accessed,
+// one level at a time.
+//
+// Nested objects and arrays are skipped over by matching brackets, without
+// validating their contents until they are accessed in turn. Malformed input
+// therefore only results in an error once the offending part is reached.
+//
+// A LazyValue is not safe for concurrent use.
+type LazyValue struct {
+	Value
+
+	// Quoted key, for object members.
+	key []byte
+
+	// Elements of an array, or members of an object, once parsed.
+	kids   []LazyValue
+	parsed bool
+}
+
+// NewLazyValue returns a LazyValue for the JSON document in data.
+func NewLazyValue(data []byte) (*LazyValue, error) {
+	var s = NewScanner()
+	var v = &LazyValue{}
+
+	for i := 0; i <= len(data); i++ {
+		var ev Event
+		if i < len(data) {
+			ev = s.Scan(data[i])
+		} else {
+			ev = s.End()
+		}
+
+		if ev == Error {
+			return nil, s.syntaxError(int64(i))
+		}
+
+		if ev&(ObjectStart|ArrayStart) != 0 {
+			j := skip(data, i)
+			if j < 0 {
+				return nil, &SyntaxError{msg: "unexpected end of JSON input", Offset: int64(len(data))}
+			}
+
+			v.Kind = ev
+			v.Raw = data[i:j]
+			v.Offset = int64(i)
+
+			// Only let the Scanner see the closing bracket.
+			i = j - 2
+		} else if ev&Start != 0 {
+			v.Kind = ev
+			v.Offset = int64(i)
+		} else if ev&End != 0 && v.Raw == nil {
+			v.Raw = data[v.Offset:i]
+		}
+	}
+
+	return v, nil
+}
+
+// Len returns the number of elements in an array, or members in an object.
+func (v *LazyValue) Len() (int, error) {
+	if err := v.parse(); err != nil {
+		return 0, err
+	}
+	return len(v.kids), nil
+}
+
+// Children returns the elements of an array, or members of an object.
+func (v *LazyValue) Children() ([]LazyValue, error) {
+	if err := v.parse(); err != nil {
+		return nil, err
+	}
+	return v.kids, nil
+}
+
+// Index returns the i-th element of an array, or the value of the i-th
+// member of an object. It returns nil if i is out of range.
+func (v *LazyValue) Index(i int) (*LazyValue, error) {
+	if err := v.parse(); err != nil {
+		return nil, err
+	}
+	if i < 0 || i >= len(v.kids) {
+		return nil, nil
+	}
+	return &v.kids[i], nil
+}
+
+// Get returns the value of the first object member with the given key, or
+// nil if there is no such member.
+func (v *LazyValue) Get(key string) (*LazyValue, error) {
+	if err := v.parse(); err != nil {
+		return nil, err
+	}
+	for i := range v.kids {
+		if keyEquals(v.kids[i].key, key) {
+			return &v.kids[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Key returns the decoded key of an object member. It returns the empty
+// string for values which aren't object members.
+func (v *LazyValue) Key() string {
+	if v.key == nil {
+		return ""
+	}
+	return string(unquote(nil, v.key))
+}
+
+// Lookup resolves a JSON Pointer (RFC 6901) relative to v, parsing only the
+// containers along the way. It returns nil if the pointer is malformed or
+// doesn't refer to an existing value.
+func (v *LazyValue) Lookup(pointer string) (*LazyValue, error) {
+	if pointer == "" {
+		return v, nil
+	}
+	if pointer[0] != '/' {
+		return nil, nil
+	}
+
+	for _, tok := range strings.Split(pointer[1:], "/") {
+		tok = strings.ReplaceAll(tok, "~1", "/")
+		tok = strings.ReplaceAll(tok, "~0", "~")
+
+		var err error
+
+		switch v.Kind {
+		case ObjectStart:
+			v, err = v.Get(tok)
+		case ArrayStart:
+			i, aerr := strconv.Atoi(tok)
+			if aerr != nil || tok != strconv.Itoa(i) {
+				return nil, nil
+			}
+			v, err = v.Index(i)
+		default:
+			return nil, nil
+		}
+
+		if v == nil || err != nil {
+			return nil, err
+		}
+	}
+
+	return v, nil
+}
+
+// parse parses the members or elements of an object or array, unless that
+// has already been done. The Scanner validates this level of the value, but
+// is only fed the brackets of nested objects and arrays.
+func (v *LazyValue) parse() error {
+	if v.parsed || v.err != nil {
+		return v.err
+	}
+	if v.Kind != ObjectStart && v.Kind != ArrayStart {
+		v.parsed = true
+		return nil
+	}
+
+	var s = NewScanner()
+	var raw = v.Raw
+	var start, kstart int
+	var kind Event
+	var key []byte
+
+	for i := 0; i <= len(raw); i++ {
+		var ev Event
+
+		if i < len(raw) {
+			ev = s.Scan(raw[i])
+		} else {
+			ev = s.End()
+		}
+
+		if ev == Error {
+			v.err = s.syntaxError(v.Offset + int64(i))
+			return v.err
+		}
+
+		if ev&(ObjectStart|ArrayStart) != 0 && i > 0 {
+			j := skip(raw, i)
+			if j < 0 {
+				v.err = &SyntaxError{msg: "unexpected end of JSON input", Offset: v.Offset + int64(len(raw))}
+				return v.err
+			}
+
+			v.kids = append(v.kids, LazyValue{
+				Value: Value{Kind: ev, Raw: raw[i:j], Offset: v.Offset + int64(i)},
+				key:   key,
+			})
+			key = nil
+
+			// The closing bracket is scanned like any other byte, so that
+			// a mismatched one results in a syntax error.
+			i = j - 2
+			continue
+		}
+
+		switch ev & End {
+		case KeyEnd:
+			key = raw[kstart:i]
+		case StringEnd, NumberEnd, BoolEnd, NullEnd:
+			v.kids = append(v.kids, LazyValue{
+				Value: Value{Kind: kind, Raw: raw[start:i], Offset: v.Offset + int64(start)},
+				key:   key,
+			})
+			key = nil
+		}
+
+		switch ev & Start {
+		case KeyStart:
+			kstart = i
+		case StringStart, NumberStart, BoolStart, NullStart:
+			start = i
+			kind = ev & Start
+		}
+	}
+
+	v.parsed = true
+	return nil
+}
+
+// skip returns the index just past the object or array starting at data[i],
+// or -1 if it isn't terminated. Only brackets and strings are recognized,
+// nothing is validated.
+func skip(data []byte, i int) int {
+	depth := 0
+
+	for ; i < len(data); i++ {
+		switch data[i] {
+		case '"':
+			j := skipString(data, i)
+			if j < 0 {
+				return -1
+			}
+			i = j - 1
+		case '{', '[':
+			depth++
+		case '}', ']':
+			if depth--; depth == 0 {
+				return i + 1
+			}
+		}
+	}
+
+	return -1
+}
+
+// skipString returns the index just past the string starting at data[i], or
+// -1 if it isn't terminated.
+func skipString(data []byte, i int) int {
+	for j := i + 1; ; {
+		k := bytes.IndexByte(data[j:], '"')
+		if k < 0 {
+			return -1
+		}
+		j += k
+
+		// The quote is escaped if preceded by an odd number of
+		// backslashes.
+		n := 0
+		for j-1-n > i && data[j-1-n] == '\\' {
+			n++
+		}
+		if n%2 == 0 {
+			return j + 1
+		}
+
+		j++
+	}
+}
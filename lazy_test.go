@@ -0,0 +1,110 @@
+package jo
+
+import (
+	"fmt"
+	"testing"
+)
+
+func ExampleLazyValue() {
+	v, err := NewLazyValue([]byte(`{"big": [1, 2, 3], "user": {"name": "Tom"}}`))
+	if err != nil {
+		panic(err)
+	}
+
+	name, _ := v.Lookup("/user/name")
+	fmt.Printf("%s\n", name.Raw)
+	// Output:
+	// "Tom"
+}
+
+func TestLazyValue(t *testing.T) {
+	v, err := NewLazyValue([]byte(` {"a": [1, {"b": "}]\"\\"}], "c": {"d": null}, "e": 1.50} `))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if v.Kind != ObjectStart || v.Offset != 1 || v.parsed {
+		t.Fatalf("unexpected root: %s at %d, parsed=%v", v.Kind, v.Offset, v.parsed)
+	}
+
+	c, err := v.Get("c")
+	if err != nil || c == nil || string(c.Raw) != `{"d": null}` {
+		t.Fatalf("Get(c): got %v, %v", c, err)
+	}
+	if c.parsed {
+		t.Errorf("Get(c): sibling was parsed prematurely")
+	}
+
+	var lookupTests = []struct {
+		ptr    string
+		raw    string
+		offset int64
+	}{
+		{"/a/0", `1`, 8},
+		{"/a/1/b", `"}]\"\\"`, 17},
+		{"/c/d", `null`, 40},
+		{"/e", `1.50`, 52},
+		{"/a/2", ``, 0},
+		{"/x", ``, 0},
+	}
+
+	for _, test := range lookupTests {
+		n, err := v.Lookup(test.ptr)
+		if err != nil {
+			t.Errorf("Lookup(%q): unexpected error: %s", test.ptr, err)
+		} else if n == nil {
+			if test.raw != "" {
+				t.Errorf("Lookup(%q): got nil", test.ptr)
+			}
+		} else if string(n.Raw) != test.raw || n.Offset != test.offset {
+			t.Errorf("Lookup(%q): got %#q at %d, want %#q at %d", test.ptr, n.Raw, n.Offset, test.raw, test.offset)
+		}
+	}
+
+	if n, _ := v.Len(); n != 3 {
+		t.Errorf("Len: got %d, want 3", n)
+	}
+}
+
+var lazyErrorTests = []struct {
+	in     string
+	ptr    string
+	offset int64
+}{
+	{`{"a": [1,}`, "", 10},
+	{`[1, [2, }, 3]`, "/0", 8},
+	{`[1, [2, ], 3]`, "/1/0", 8},
+	{`[1, [2, }], 3]`, "", 10},
+	{`[1] x`, "", 4},
+	{`{"a": {]}`, "/a", 7},
+	{`{"a" 1}`, "/a", 5},
+}
+
+func TestLazyValueErrors(t *testing.T) {
+	for _, test := range lazyErrorTests {
+		v, err := NewLazyValue([]byte(test.in))
+		if err == nil {
+			_, err = v.Lookup(test.ptr)
+		}
+
+		serr, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("Lookup(%#q, %q): got error %v, want *SyntaxError", test.in, test.ptr, err)
+		} else if serr.Offset != test.offset {
+			t.Errorf("Lookup(%#q, %q): got error at offset %d, want %d", test.in, test.ptr, serr.Offset, test.offset)
+		}
+	}
+}
+
+func BenchmarkLazyValue(b *testing.B) {
+	var data = []byte(sample)
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		v, _ := NewLazyValue(data)
+		v.Lookup("/data/1/from/name")
+	}
+}
@@ -0,0 +1,287 @@
+package jo
+
+import (
+	"strconv"
+)
+
+// Limits bounds the resources a JSON value may consume, protecting programs
+// reading untrusted input from values which are too large or too deeply
+// nested. Fields left at zero impose no limit.
+//
+// Lengths of keys, strings, numbers and literals are measured in bytes as
+// they appear in the input, so escape sequences count in full.
+type Limits struct {
+	// MaxDepth is the deepest objects and arrays may be nested.
+	MaxDepth int
+
+	// MaxTokenLength is the longest a key or scalar value may be,
+	// including the quotes surrounding keys and strings.
+	MaxTokenLength int64
+
+	// MaxStringLength is the longest a key or string may be, excluding
+	// its quotes.
+	MaxStringLength int64
+
+	// MaxArrayLength is the largest number of elements an array may have,
+	// and MaxObjectLength the largest number of members an object may have.
+	MaxArrayLength  int64
+	MaxObjectLength int64
+
+	// MaxInputLength is the largest number of bytes which may be scanned,
+	// whitespace included. When reading a stream of values, it applies to
+	// the stream as a whole.
+	MaxInputLength int64
+
+	// MaxNumberDigits is the largest number of digits a number may have,
+	// counting those of its fraction and exponent.
+	MaxNumberDigits int
+}
+
+// A Limit identifies one of the fields of Limits.
+type Limit int
+
+const (
+	DepthLimit Limit = iota + 1
+	TokenLengthLimit
+	StringLengthLimit
+	ArrayLengthLimit
+	ObjectLengthLimit
+	InputLengthLimit
+	NumberDigitsLimit
+)
+
+// String returns the name of the Limits field the Limit refers to.
+func (l Limit) String() string {
+	switch l {
+	case DepthLimit:
+		return "MaxDepth"
+	case TokenLengthLimit:
+		return "MaxTokenLength"
+	case StringLengthLimit:
+		return "MaxStringLength"
+	case ArrayLengthLimit:
+		return "MaxArrayLength"
+	case ObjectLengthLimit:
+		return "MaxObjectLength"
+	case InputLengthLimit:
+		return "MaxInputLength"
+	case NumberDigitsLimit:
+		return "MaxNumberDigits"
+	}
+	return "Limit(" + strconv.Itoa(int(l)) + ")"
+}
+
+// A LimitError reports input exceeding one of a Scanner's Limits. Functions
+// which report a *SyntaxError wrap it, so it can be retrieved using errors.As.
+type LimitError struct {
+	// Limit is the limit which was exceeded, and Max its value.
+	Limit Limit
+	Max   int64
+}
+
+// Error returns a description of the exceeded limit.
+func (e *LimitError) Error() string {
+	n := strconv.FormatInt(e.Max, 10)
+
+	switch e.Limit {
+	case DepthLimit:
+		return "exceeded maximum nesting depth of " + n
+	case TokenLengthLimit:
+		return "token exceeds maximum length of " + n + " bytes"
+	case StringLengthLimit:
+		return "string exceeds maximum length of " + n + " bytes"
+	case ArrayLengthLimit:
+		return "array exceeds maximum length of " + n + " elements"
+	case ObjectLengthLimit:
+		return "object exceeds maximum length of " + n + " members"
+	case InputLengthLimit:
+		return "input exceeds maximum length of " + n + " bytes"
+	case NumberDigitsLimit:
+		return "number exceeds maximum of " + n + " digits"
+	}
+	return "exceeded " + e.Limit.String() + " of " + n
+}
+
+// SetLimits sets the limits enforced by s from now on. The zero Limits value
+// removes all limits.
+func (s *Scanner) SetLimits(l Limits) {
+	if l == (Limits{}) {
+		if s.lim != nil {
+			s.state = s.lim.state
+			s.lim = nil
+		}
+		return
+	}
+
+	if s.lim == nil {
+		s.lim = &limiter{state: s.state}
+		s.state = limited
+	}
+	s.lim.Limits = l
+}
+
+// SetLimits sets the limits enforced by t while reading the rest of its
+// input. Exceeding a limit results in a *SyntaxError wrapping a *LimitError.
+func (t *Tokenizer) SetLimits(l Limits) {
+	t.s.SetLimits(l)
+}
+
+// SetLimits sets the limits enforced by d while reading the rest of its
+// input, as with Tokenizer.SetLimits. MaxInputLength applies to the stream
+// as a whole, and the other limits to each value.
+func (d *Decoder) SetLimits(l Limits) {
+	d.t.SetLimits(l)
+}
+
+// A limiter tracks the state needed to enforce a Scanner's Limits, based on
+// the events produced for each byte.
+type limiter struct {
+	Limits
+
+	// The Scanner's actual state.
+	state func(*Scanner, byte) Event
+
+	// Number of bytes scanned.
+	input int64
+
+	// Open objects and arrays, innermost last, with their number of
+	// members or elements so far.
+	open []container
+
+	// Kind of the key or scalar value in progress, if any, its length so
+	// far, and its digits if it's a number. For keys and strings, esc is
+	// set while the byte following a backslash is pending, and closed
+	// once the closing quote has been seen.
+	kind   Event
+	length int64
+	digits int
+	esc    bool
+	closed bool
+}
+
+// A container is an open object or array.
+type container struct {
+	array bool
+	n     int64
+}
+
+// restart clears everything but the input count, after s has been put in
+// its initial state.
+func (l *limiter) restart(s *Scanner) {
+	l.state = s.state
+	s.state = limited
+	l.open = l.open[:0]
+	l.kind = None
+}
+
+// copyFrom makes l an independent copy of m, reusing l's slice.
+func (l *limiter) copyFrom(m *limiter) {
+	open := append(l.open[:0], m.open...)
+	*l = *m
+	l.open = open
+}
+
+// limited is the state of every Scanner with limits. It passes c on to the
+// actual state, then checks the resulting events against the limits.
+func limited(s *Scanner, c byte) Event {
+	l := s.lim
+
+	s.state = l.state
+	ev := s.state(s, c)
+	l.state = s.state
+	s.state = limited
+
+	if ev == Error {
+		return Error
+	}
+
+	if l.input++; l.MaxInputLength > 0 && l.input > l.MaxInputLength {
+		return s.limitError(InputLengthLimit, l.MaxInputLength)
+	}
+
+	// End events are signaled by the byte following the value.
+	if ev&(ObjectEnd|ArrayEnd) != 0 {
+		l.open = l.open[:len(l.open)-1]
+	}
+	if ev&End&^(ObjectEnd|ArrayEnd) != 0 {
+		l.kind = None
+	}
+
+	if ev&Start == 0 {
+		if l.kind != None {
+			return l.token(s, c, ev)
+		}
+		return ev
+	}
+
+	// Count the new value, or key, towards its container.
+	if n := len(l.open); n > 0 {
+		top := &l.open[n-1]
+		if top.array {
+			if top.n++; l.MaxArrayLength > 0 && top.n > l.MaxArrayLength {
+				return s.limitError(ArrayLengthLimit, l.MaxArrayLength)
+			}
+		} else if ev&KeyStart != 0 {
+			if top.n++; l.MaxObjectLength > 0 && top.n > l.MaxObjectLength {
+				return s.limitError(ObjectLengthLimit, l.MaxObjectLength)
+			}
+		}
+	}
+
+	switch kind := ev & Start; kind {
+	case ObjectStart, ArrayStart:
+		if l.MaxDepth > 0 && len(l.open) == l.MaxDepth {
+			return s.limitError(DepthLimit, int64(l.MaxDepth))
+		}
+		l.open = append(l.open, container{array: kind == ArrayStart})
+	default:
+		l.kind = kind
+		l.length = 0
+		l.digits = 0
+		l.esc = false
+		l.closed = false
+		return l.token(s, c, ev)
+	}
+
+	return ev
+}
+
+// token checks the key or scalar value in progress after its byte c.
+func (l *limiter) token(s *Scanner, c byte, ev Event) Event {
+	if l.length++; l.MaxTokenLength > 0 && l.length > l.MaxTokenLength {
+		return s.limitError(TokenLengthLimit, l.MaxTokenLength)
+	}
+
+	switch l.kind {
+	case KeyStart, StringStart:
+		if l.length == 1 {
+			break
+		}
+		if l.esc {
+			l.esc = false
+		} else if c == '\\' {
+			l.esc = true
+		} else if c == '"' {
+			l.closed = true
+		}
+		if !l.closed && l.MaxStringLength > 0 && l.length-1 > l.MaxStringLength {
+			return s.limitError(StringLengthLimit, l.MaxStringLength)
+		}
+
+	case NumberStart:
+		if table[c]&isDigit != 0 {
+			if l.digits++; l.MaxNumberDigits > 0 && l.digits > l.MaxNumberDigits {
+				return s.limitError(NumberDigitsLimit, int64(l.MaxNumberDigits))
+			}
+		}
+	}
+
+	return ev
+}
+
+// limitError persists a *LimitError.
+func (s *Scanner) limitError(limit Limit, max int64) Event {
+	s.lim.state = afterError
+	s.err = &LimitError{limit, max}
+	return Error
+}
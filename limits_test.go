@@ -0,0 +1,174 @@
+package jo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func ExampleLimits() {
+	t := NewTokenizer(strings.NewReader(`{"ids": [1, 2, 3, 4, 5]}`))
+	t.SetLimits(Limits{MaxDepth: 16, MaxArrayLength: 3})
+
+	_, err := readTokens(t)
+
+	var lerr *LimitError
+	if errors.As(err, &lerr) {
+		fmt.Printf("%s exceeded at offset %d: %s\n", lerr.Limit, err.(*SyntaxError).Offset, err)
+	}
+	// Output:
+	// MaxArrayLength exceeded at offset 18: array exceeds maximum length of 3 elements
+}
+
+var limitTests = []struct {
+	limits Limits
+	in     string
+
+	// Limit exceeded, if any, and the offset at which it's detected.
+	limit  Limit
+	offset int64
+}{
+	{Limits{MaxDepth: 2}, `[[1], {"a": {}}]`, DepthLimit, 12},
+	{Limits{MaxDepth: 2}, `[[1], {"a": [2]}]`, DepthLimit, 12},
+	{Limits{MaxDepth: 3}, `[[1], {"a": [2]}]`, 0, 0},
+	{Limits{MaxDepth: 1}, `"abc"`, 0, 0},
+
+	{Limits{MaxTokenLength: 5}, `["abc", 123, true, false]`, 0, 0},
+	{Limits{MaxTokenLength: 4}, `["abc", 123]`, TokenLengthLimit, 5},
+	{Limits{MaxTokenLength: 4}, `[123, true, false]`, TokenLengthLimit, 16},
+	{Limits{MaxTokenLength: 4}, `{"abcd": 1}`, TokenLengthLimit, 5},
+	{Limits{MaxTokenLength: 3}, `12345`, TokenLengthLimit, 3},
+
+	{Limits{MaxStringLength: 3}, `{"abc": "def"}`, 0, 0},
+	{Limits{MaxStringLength: 3}, `{"abc": "defg"}`, StringLengthLimit, 12},
+	{Limits{MaxStringLength: 3}, `{"abcd": 1}`, StringLengthLimit, 5},
+	{Limits{MaxStringLength: 4}, `["\"\\"]`, 0, 0},
+	{Limits{MaxStringLength: 3}, `["\"\\"]`, StringLengthLimit, 5},
+
+	{Limits{MaxArrayLength: 2}, `[[1, 2], [3, [], {}]]`, ArrayLengthLimit, 17},
+	{Limits{MaxArrayLength: 2}, `[[1, 2], [3, [4, 5]]]`, 0, 0},
+	{Limits{MaxArrayLength: 2}, `{"a": 1, "b": 2, "c": 3}`, 0, 0},
+
+	{Limits{MaxObjectLength: 2}, `{"a": 1, "b": {"c": [1, 2, 3]}}`, 0, 0},
+	{Limits{MaxObjectLength: 2}, `[{"a": 1, "b": 2, "c": 3}]`, ObjectLengthLimit, 18},
+
+	{Limits{MaxInputLength: 9}, ` [1, 2]  `, 0, 0},
+	{Limits{MaxInputLength: 8}, ` [1, 2]  `, InputLengthLimit, 8},
+
+	{Limits{MaxNumberDigits: 4}, `[1234, -1.23e+4]`, 0, 0},
+	{Limits{MaxNumberDigits: 4}, `[1234, -1.23e+45]`, NumberDigitsLimit, 15},
+	{Limits{MaxNumberDigits: 4}, `[12345]`, NumberDigitsLimit, 5},
+}
+
+func TestLimits(t *testing.T) {
+	for _, test := range limitTests {
+		tz := NewBytesTokenizer([]byte(test.in))
+		tz.SetLimits(test.limits)
+		_, err := readTokens(tz)
+
+		if test.limit == 0 {
+			if err != nil {
+				t.Errorf("%+v %#q: unexpected error: %s", test.limits, test.in, err)
+			}
+			continue
+		}
+
+		var lerr *LimitError
+		if !errors.As(err, &lerr) {
+			t.Errorf("%+v %#q: got error %v, want *LimitError", test.limits, test.in, err)
+			continue
+		}
+
+		if off := err.(*SyntaxError).Offset; lerr.Limit != test.limit || off != test.offset {
+			t.Errorf("%+v %#q:", test.limits, test.in)
+			t.Errorf("  got  %s at offset %d", lerr.Limit, off)
+			t.Errorf("  want %s at offset %d", test.limit, test.offset)
+		}
+	}
+}
+
+func TestLimitsScanner(t *testing.T) {
+	s := NewScanner()
+	s.SetLimits(Limits{MaxDepth: 1})
+
+	for _, c := range []byte(`[[]]`) {
+		if s.Scan(c) == Error {
+			break
+		}
+	}
+	if err, ok := s.LastError().(*LimitError); !ok || err.Limit != DepthLimit {
+		t.Errorf("got error %v, want DepthLimit", s.LastError())
+	}
+
+	// Limits remain in place after a reset, but can be removed.
+	s.Reset()
+	for _, c := range []byte(`[[`) {
+		s.Scan(c)
+	}
+	if s.LastError() == nil {
+		t.Errorf("limits were lost on Reset")
+	}
+
+	s.Reset()
+	s.SetLimits(Limits{})
+	for _, c := range []byte(`[[]]`) {
+		s.Scan(c)
+	}
+	if s.End() == Error {
+		t.Errorf("unexpected error: %s", s.LastError())
+	}
+}
+
+func TestLimitsDecoder(t *testing.T) {
+	// MaxInputLength applies to the whole stream, the others to each value.
+	d := NewDecoder(strings.NewReader(`[1, 2] [3, 4] [5, 6]`))
+	d.SetLimits(Limits{MaxArrayLength: 2, MaxInputLength: 16})
+
+	var n int
+	var err error
+	for {
+		if _, err = d.Token(); err != nil {
+			break
+		}
+		n++
+	}
+
+	var lerr *LimitError
+	if !errors.As(err, &lerr) || lerr.Limit != InputLengthLimit {
+		t.Fatalf("got error %v, want InputLengthLimit", err)
+	}
+	if n != 10 {
+		t.Errorf("got %d tokens before the error, want 10", n)
+	}
+}
+
+func TestSyntaxErrorUnwrap(t *testing.T) {
+	err := Validate([]byte(`[1,]`))
+	if errors.Unwrap(err) != nil {
+		t.Errorf("got %v, want no underlying error", errors.Unwrap(err))
+	}
+}
+
+func BenchmarkLimits(b *testing.B) {
+	var r = strings.NewReader(sample)
+
+	b.SetBytes(int64(len(sample)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		r.Reset(sample)
+		t := NewTokenizer(r)
+		t.SetLimits(Limits{MaxDepth: 64, MaxStringLength: 1 << 20, MaxArrayLength: 1 << 16})
+
+		for {
+			if _, err := t.Next(); err != nil {
+				if err != io.EOF {
+					b.Fatal(err)
+				}
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package jo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// A LineError reports a problem with a line of newline-delimited JSON.
+type LineError struct {
+	// Line is the line's number, counting from 1, and Offset the input
+	// offset of its first byte.
+	Line   int64
+	Offset int64
+
+	// Err is a *SyntaxError, with an offset relative to the input as a
+	// whole, or an error returned by the caller's function.
+	Err error
+}
+
+// Error returns a description of the error, prefixed by the line number.
+func (e *LineError) Error() string {
+	return "line " + strconv.FormatInt(e.Line, 10) + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Size of the chunks ParallelLines splits its input into, before they're
+// adjusted to line boundaries.
+var parallelChunkSize int64 = 4 << 20
+
+// ParallelLines validates the first size bytes of r as newline-delimited
+// JSON, using up to workers goroutines, or GOMAXPROCS if workers is zero or
+// less. Each non-blank line must hold a single JSON value, which is passed
+// to fn along with its line number, stripped of its line terminator. The
+// line is only valid until fn returns, and fn may be nil.
+//
+// The input is split into chunks at line boundaries, and the lines of each
+// chunk are validated and passed to fn in order; fn is however called
+// concurrently for lines in different chunks.
+//
+// The first problem found stops further processing. Malformed lines result
+// in a *LineError wrapping a *SyntaxError, and errors returned by fn are
+// wrapped in a *LineError; read errors are returned as they are. If there's
+// more than one problem, the one earliest in the input is returned, although
+// fn may already have been called for lines after it.
+func ParallelLines(r io.ReaderAt, size int64, workers int, fn func(line []byte, lineNo int64) error) error {
+	return parallelLines(r, size, workers, fn, false)
+}
+
+// ParallelLinesOrdered is like ParallelLines, but calls fn for one line at a
+// time, in input order, stopping at the first problem. Lines are still read
+// and validated in parallel.
+func ParallelLinesOrdered(r io.ReaderAt, size int64, workers int, fn func(line []byte, lineNo int64) error) error {
+	return parallelLines(r, size, workers, fn, true)
+}
+
+// A lineChunk is a part of the input processed by a single worker.
+type lineChunk struct {
+	// Number of lines in the chunk, and the number of its first line.
+	// Both are valid once counted has been closed.
+	lines, first int64
+	counted      chan struct{}
+
+	// Closed once the chunk's lines have been passed to fn, in ordered
+	// mode.
+	done chan struct{}
+
+	err error
+}
+
+func parallelLines(r io.ReaderAt, size int64, workers int, fn func([]byte, int64) error, ordered bool) error {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	chunks := make([]lineChunk, (size+parallelChunkSize-1)/parallelChunkSize)
+	for i := range chunks {
+		chunks[i].counted = make(chan struct{})
+		chunks[i].done = make(chan struct{})
+	}
+
+	// Index of the earliest chunk with an error, so later chunks can be
+	// abandoned, and of the next chunk to be processed.
+	var failed atomic.Int64
+	var next atomic.Int64
+	failed.Store(int64(len(chunks)))
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			p := &lineProcessor{r: r, size: size, fn: fn, ordered: ordered, s: NewScanner()}
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(chunks) {
+					return
+				}
+
+				p.process(chunks, i, &failed)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if f := failed.Load(); f < int64(len(chunks)) {
+		return chunks[f].err
+	}
+	return nil
+}
+
+// A lineProcessor processes chunks of input for a single worker.
+type lineProcessor struct {
+	r       io.ReaderAt
+	size    int64
+	fn      func([]byte, int64) error
+	ordered bool
+
+	s   *Scanner
+	buf []byte
+}
+
+// process processes the i-th chunk, always closing its channels. Errors are
+// recorded before the channels are closed, so that later chunks waiting for
+// them know to stop.
+func (p *lineProcessor) process(chunks []lineChunk, i int, failed *atomic.Int64) (err error) {
+	c := &chunks[i]
+
+	counted := false
+	defer func() {
+		if err != nil {
+			c.err = err
+			for {
+				f := failed.Load()
+				if int64(i) >= f || failed.CompareAndSwap(f, int64(i)) {
+					break
+				}
+			}
+		}
+
+		if !counted {
+			close(c.counted)
+		}
+		close(c.done)
+	}()
+
+	// Later chunks are abandoned after an error.
+	stopped := func() bool {
+		return int64(i) > failed.Load()
+	}
+	if stopped() {
+		return nil
+	}
+
+	start, data, err := p.read(int64(i) * parallelChunkSize)
+	if err != nil {
+		return err
+	}
+
+	// Number the chunk's lines once all earlier chunks have been counted.
+	c.lines = int64(bytes.Count(data, []byte{'\n'}))
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		c.lines++
+	}
+	c.first = 1
+	if i > 0 {
+		prev := &chunks[i-1]
+		<-prev.counted
+		c.first = prev.first + prev.lines
+	}
+	close(c.counted)
+	counted = true
+
+	if !p.ordered {
+		return eachLine(data, start, c.first, func(line []byte, n, off int64) error {
+			if stopped() {
+				return errStopped
+			}
+			if err := p.validate(line, n, off); err != nil {
+				return err
+			}
+			if p.fn != nil {
+				if err := p.fn(line, n); err != nil {
+					return &LineError{n, off, err}
+				}
+			}
+			return nil
+		})
+	}
+
+	// Validate everything up front, then wait for earlier chunks to be
+	// passed to fn before passing the lines preceding any error.
+	verr := eachLine(data, start, c.first, p.validate)
+
+	if i > 0 {
+		<-chunks[i-1].done
+	}
+	if stopped() || p.fn == nil {
+		return verr
+	}
+
+	err = eachLine(data, start, c.first, func(line []byte, n, off int64) error {
+		if le, ok := verr.(*LineError); ok && off >= le.Offset {
+			return errStopped
+		}
+		if err := p.fn(line, n); err != nil {
+			return &LineError{n, off, err}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return verr
+}
+
+// validate checks that a line, starting at the given input offset, holds a
+// single JSON value.
+func (p *lineProcessor) validate(line []byte, n, off int64) error {
+	p.s.Reset()
+
+	for j, c := range line {
+		if p.s.Scan(c) == Error {
+			return &LineError{n, off, p.s.syntaxError(off + int64(j))}
+		}
+	}
+	if p.s.End() == Error {
+		return &LineError{n, off, p.s.syntaxError(off + int64(len(line)))}
+	}
+
+	return nil
+}
+
+// errStopped stops eachLine without an error.
+var errStopped = errors.New("stopped")
+
+// eachLine calls fn for each non-blank line in data, which starts at the
+// given input offset and line number, along with the line's number and
+// offset. It stops at the first error returned by fn.
+func eachLine(data []byte, start, first int64, fn func(line []byte, n, off int64) error) error {
+	n := first
+	for off := 0; off < len(data); n++ {
+		line := data[off:]
+		if i := bytes.IndexByte(line, '\n'); i >= 0 {
+			line = line[:i+1]
+		}
+		lstart := start + int64(off)
+		off += len(line)
+
+		if line = bytes.TrimRight(line, "\r\n"); isBlank(line) {
+			continue
+		}
+
+		if err := fn(line, n, lstart); err == errStopped {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// read reads the lines beginning in the chunk starting at the given input
+// offset: everything from the start of the first line at or after off, up
+// to and including the newline ending the last line starting before the
+// next chunk. It returns the input offset of the data read.
+func (p *lineProcessor) read(off int64) (int64, []byte, error) {
+	end := min(off+parallelChunkSize, p.size)
+
+	// Unless the chunk is the first one, or the previous one ends with a
+	// newline, its first line belongs to the previous chunk.
+	from := max(off-1, 0)
+
+	p.buf = p.buf[:0]
+	if err := p.readAt(from, end); err != nil {
+		return 0, nil, err
+	}
+
+	skip := 0
+	if off > 0 {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			// A single line spans the whole chunk.
+			return end, nil, nil
+		}
+		skip = i + 1
+	}
+
+	// Extend the chunk to the end of its last line.
+	for len(p.buf) > skip && p.buf[len(p.buf)-1] != '\n' && end < p.size {
+		n := len(p.buf)
+		next := min(end+64<<10, p.size)
+		if err := p.readAt(end, next); err != nil {
+			return 0, nil, err
+		}
+		end = next
+
+		if i := bytes.IndexByte(p.buf[n:], '\n'); i >= 0 {
+			p.buf = p.buf[:n+i+1]
+			break
+		}
+	}
+
+	return from + int64(skip), p.buf[skip:], nil
+}
+
+// readAt appends the input from offset from up to offset to to p.buf.
+func (p *lineProcessor) readAt(from, to int64) error {
+	n := len(p.buf)
+	p.buf = append(p.buf, make([]byte, to-from)...)
+
+	m, err := p.r.ReadAt(p.buf[n:], from)
+	if m == len(p.buf)-n {
+		return nil
+	}
+	if err == nil || err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// isBlank reports whether a line holds nothing but whitespace.
+func isBlank(line []byte) bool {
+	for _, c := range line {
+		if table[c]&isSpace == 0 {
+			return false
+		}
+	}
+	return true
+}
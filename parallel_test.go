@@ -0,0 +1,214 @@
+package jo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func ExampleParallelLines() {
+	data := "{\"id\": 1}\n{\"id\": 2}\n\n{\"id\": 3,}\n{\"id\": 4}\n"
+
+	err := ParallelLines(strings.NewReader(data), int64(len(data)), 4, nil)
+
+	var lerr *LineError
+	if errors.As(err, &lerr) {
+		fmt.Printf("line %d at offset %d: %s\n", lerr.Line, lerr.Err.(*SyntaxError).Offset, lerr.Err)
+	}
+	// Output:
+	// line 4 at offset 30: invalid character '}': expected object key after ','; trailing commas are not allowed
+}
+
+var parallelTests = []string{
+	``,
+	"\n\n\n",
+	`{"a": 1}`,
+	"1\n2\n3\n",
+	"1\r\n[2, 3]\r\n  \r\n\"four\"\r\n",
+	"{\"a\": [1, 2, 3], \"b\": \"" + strings.Repeat("x", 100) + "\"}\n\n{}\n" + strings.Repeat("[]\n", 20),
+	"1\n2\n[3,\n4\n",
+	"true\nfalse\nnull\n{\"x\":\n",
+	"\"a\"\n\"b\" \"c\"\n" + strings.Repeat("0\n", 30) + "-\n",
+	strings.Repeat("{\"k\": \"v\"}\n", 40) + "{\"k\" \"v\"}\n" + strings.Repeat("[1]\n", 40),
+}
+
+// serialLines processes data one line at a time, as ParallelLines should.
+func serialLines(data []byte) ([]string, error) {
+	var out []string
+
+	var off int64
+	for i, line := range bytes.SplitAfter(data, []byte{'\n'}) {
+		n, lstart := int64(i+1), off
+		off += int64(len(line))
+
+		text := bytes.TrimRight(line, "\r\n")
+		if len(bytes.TrimSpace(text)) == 0 {
+			continue
+		}
+
+		if err := Validate(text); err != nil {
+			serr := err.(*SyntaxError)
+			serr.Offset += lstart
+			return out, &LineError{n, lstart, serr}
+		}
+		out = append(out, fmt.Sprintf("%d %s", n, text))
+	}
+
+	return out, nil
+}
+
+func describeLineError(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+	lerr, ok := err.(*LineError)
+	if !ok {
+		return fmt.Sprintf("%T %v", err, err)
+	}
+	serr, ok := lerr.Err.(*SyntaxError)
+	if !ok {
+		return fmt.Sprintf("line %d at %d: %v", lerr.Line, lerr.Offset, lerr.Err)
+	}
+	return fmt.Sprintf("line %d at %d: offset %d: %s", lerr.Line, lerr.Offset, serr.Offset, serr)
+}
+
+func TestParallelLines(t *testing.T) {
+	defer func(size int64) { parallelChunkSize = size }(parallelChunkSize)
+
+	for _, in := range parallelTests {
+		want, werr := serialLines([]byte(in))
+
+		for _, size := range []int64{1, 2, 3, 5, 8, 13, 64, 4 << 20} {
+			parallelChunkSize = size
+
+			for _, workers := range []int{1, 3, 8} {
+				for _, ordered := range []bool{false, true} {
+					var mu sync.Mutex
+					var got []string
+					fn := func(line []byte, n int64) error {
+						mu.Lock()
+						got = append(got, fmt.Sprintf("%d %s", n, line))
+						mu.Unlock()
+						return nil
+					}
+
+					var err error
+					if ordered {
+						err = ParallelLinesOrdered(strings.NewReader(in), int64(len(in)), workers, fn)
+					} else {
+						err = ParallelLines(strings.NewReader(in), int64(len(in)), workers, fn)
+						slices.SortStableFunc(got, func(a, b string) int {
+							var x, y int
+							fmt.Sscan(a, &x)
+							fmt.Sscan(b, &y)
+							return x - y
+						})
+					}
+
+					name := fmt.Sprintf("%#q (chunks of %d, %d workers, ordered %t)", in, size, workers, ordered)
+
+					if describeLineError(err) != describeLineError(werr) {
+						t.Errorf("%s:", name)
+						t.Errorf("  got  error %s", describeLineError(err))
+						t.Errorf("  want error %s", describeLineError(werr))
+					}
+
+					// Without ordering, lines after an error may be passed
+					// to fn as well.
+					if !ordered && werr != nil {
+						continue
+					}
+					if strings.Join(got, "\n") != strings.Join(want, "\n") {
+						t.Errorf("%s:", name)
+						t.Errorf("  got  %q", got)
+						t.Errorf("  want %q", want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestParallelLinesCallbackError(t *testing.T) {
+	defer func(size int64) { parallelChunkSize = size }(parallelChunkSize)
+	parallelChunkSize = 16
+
+	in := strings.Repeat("[1, 2, 3]\n", 100)
+	stop := errors.New("stop")
+
+	var seen []int64
+	err := ParallelLinesOrdered(strings.NewReader(in), int64(len(in)), 4, func(line []byte, n int64) error {
+		seen = append(seen, n)
+		if n == 42 {
+			return stop
+		}
+		return nil
+	})
+
+	var lerr *LineError
+	if !errors.As(err, &lerr) || !errors.Is(err, stop) || lerr.Line != 42 || lerr.Offset != 410 {
+		t.Errorf("got error %s, want stop at line 42", describeLineError(err))
+	}
+	if len(seen) != 42 || seen[41] != 42 {
+		t.Errorf("fn called for %d lines, ending with %d", len(seen), seen[len(seen)-1])
+	}
+}
+
+// failingReaderAt fails to read past a given offset.
+type failingReaderAt struct {
+	data  string
+	limit int64
+}
+
+var errRead = errors.New("read failed")
+
+func (r failingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off+int64(len(p)) > r.limit {
+		return 0, errRead
+	}
+	return copy(p, r.data[off:]), nil
+}
+
+func TestParallelLinesReadError(t *testing.T) {
+	defer func(size int64) { parallelChunkSize = size }(parallelChunkSize)
+	parallelChunkSize = 16
+
+	in := strings.Repeat("[1, 2, 3]\n", 100)
+	err := ParallelLines(failingReaderAt{in, 500}, int64(len(in)), 4, nil)
+	if err != errRead {
+		t.Errorf("got error %v, want %v", err, errRead)
+	}
+
+	// Input ending early is reported as well.
+	err = ParallelLines(strings.NewReader(in[:500]), int64(len(in)), 4, nil)
+	if err == nil {
+		t.Errorf("got no error for short input")
+	}
+}
+
+func BenchmarkParallelLines(b *testing.B) {
+	line, err := Compact(nil, []byte(sample))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	for buf.Len() < 16<<20 {
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	data := buf.Bytes()
+
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := ParallelLines(bytes.NewReader(data), int64(len(data)), 0, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package jo
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// A PartialParser builds a tree of values from a JSON document which arrives
+// in chunks, such as a response streamed token by token. After each chunk,
+// the tree holds everything read so far, with values still being read marked
+// as incomplete.
+//
+// Every chunk is checked by a Scanner as it's written, so input which can't
+// be the beginning of a JSON value is rejected straight away. Writing a chunk
+// costs time proportional to its length and the current nesting depth, not
+// to the length of the document so far.
+type PartialParser struct {
+	s, probe *Scanner
+
+	// All input written so far.
+	buf []byte
+
+	root *PartialValue
+
+	// Open containers, and the scalar value being read, if any.
+	stack []*PartialValue
+	cur   *PartialValue
+
+	// Quoted key of the next object member, and where the key being read
+	// started.
+	key    []byte
+	kstart int
+
+	err error
+}
+
+// A PartialValue is a value in the tree built by a PartialParser. Until
+// Complete is set, Raw holds as much of the value as has been read, and the
+// members or elements of an object or array are those read so far.
+//
+// Object members appear once their value starts, so a member whose key is
+// still being read is left out.
+type PartialValue struct {
+	Value
+
+	// Complete reports whether the value has been read in full.
+	Complete bool
+
+	// Quoted key, for object members.
+	key []byte
+
+	// Elements of an array, or members of an object.
+	kids []*PartialValue
+}
+
+// NewPartialParser returns a PartialParser expecting a single JSON value.
+func NewPartialParser() *PartialParser {
+	return &PartialParser{s: NewScanner(), probe: NewScanner()}
+}
+
+// Write adds the next chunk of input and updates the value tree. If the
+// chunk can't continue the input written so far, Write returns a
+// *SyntaxError, and so will any later calls.
+func (p *PartialParser) Write(chunk []byte) (int, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+
+	base := len(p.buf)
+	p.buf = append(p.buf, chunk...)
+
+	for i := base; i < len(p.buf); i++ {
+		ev := p.s.Scan(p.buf[i])
+		if ev == Error {
+			p.err = p.s.syntaxError(int64(i))
+			p.buf = p.buf[:i]
+			p.update()
+			return i - base, p.err
+		}
+		p.handle(ev, i)
+	}
+
+	p.update()
+	return len(chunk), nil
+}
+
+// Close signals the end of input. It returns a *SyntaxError unless the input
+// written holds exactly one complete JSON value.
+func (p *PartialParser) Close() error {
+	if p.err != nil {
+		return p.err
+	}
+
+	ev := p.s.End()
+	if ev == Error {
+		p.err = p.s.syntaxError(int64(len(p.buf)))
+		return p.err
+	}
+	p.handle(ev, len(p.buf))
+
+	return nil
+}
+
+// Value returns the root of the value tree, or nil if no value has started
+// yet. The tree is updated in place by later calls to Write.
+func (p *PartialParser) Value() *PartialValue {
+	return p.root
+}
+
+// handle updates the tree with the events produced by the byte at offset i.
+func (p *PartialParser) handle(ev Event, i int) {
+	switch ev & End {
+	case None:
+	case KeyEnd:
+		p.key = p.buf[p.kstart:i]
+	case ObjectEnd, ArrayEnd:
+		v := p.stack[len(p.stack)-1]
+		p.stack = p.stack[:len(p.stack)-1]
+		v.Raw = p.buf[v.Offset:i]
+		v.Complete = true
+	default:
+		p.cur.Raw = p.buf[p.cur.Offset:i]
+		p.cur.Complete = true
+		p.cur = nil
+	}
+
+	switch ev & Start {
+	case None:
+	case KeyStart:
+		p.kstart = i
+	default:
+		v := &PartialValue{
+			Value: Value{Kind: ev & Start, Raw: p.buf[i : i+1], Offset: int64(i)},
+			key:   p.key,
+		}
+		p.key = nil
+
+		if n := len(p.stack); n > 0 {
+			p.stack[n-1].kids = append(p.stack[n-1].kids, v)
+		} else {
+			p.root = v
+		}
+
+		if ev&(ObjectStart|ArrayStart) != 0 {
+			p.stack = append(p.stack, v)
+		} else {
+			p.cur = v
+		}
+	}
+}
+
+// update brings the Raw fields of the values still being read up to date,
+// and marks values which have been read in full as complete even though the
+// Scanner won't report their end until it sees the next byte.
+func (p *PartialParser) update() {
+	for _, v := range p.stack {
+		v.Raw = p.buf[v.Offset:]
+	}
+	if p.cur != nil {
+		p.cur.Raw = p.buf[p.cur.Offset:]
+	}
+
+	if p.err != nil {
+		return
+	}
+
+	// A number may always go on, so it's only complete once something
+	// follows it.
+	p.probe.copyFrom(p.s)
+	ev := p.probe.Scan(' ')
+	if ev == Error {
+		return
+	}
+
+	switch ev & End &^ (NumberEnd | KeyEnd) {
+	case None:
+	case ObjectEnd, ArrayEnd:
+		p.stack[len(p.stack)-1].Complete = true
+	default:
+		p.cur.Complete = true
+	}
+}
+
+// Len returns the number of elements in an array, or members in an object,
+// read so far.
+func (v *PartialValue) Len() int {
+	return len(v.kids)
+}
+
+// Children returns the elements of an array, or members of an object, read
+// so far.
+func (v *PartialValue) Children() []*PartialValue {
+	return v.kids
+}
+
+// Index returns the i-th element of an array, or the value of the i-th
+// member of an object. It returns nil if i is out of range.
+func (v *PartialValue) Index(i int) *PartialValue {
+	if i < 0 || i >= len(v.kids) {
+		return nil
+	}
+	return v.kids[i]
+}
+
+// Get returns the value of the first object member with the given key, or
+// nil if there is no such member yet.
+func (v *PartialValue) Get(key string) *PartialValue {
+	for _, kid := range v.kids {
+		if keyEquals(kid.key, key) {
+			return kid
+		}
+	}
+	return nil
+}
+
+// Key returns the decoded key of an object member. It returns the empty
+// string for values which aren't object members.
+func (v *PartialValue) Key() string {
+	if v.key == nil {
+		return ""
+	}
+	return string(unquote(nil, v.key))
+}
+
+// Text decodes a string value. For a string which is still being read, it
+// returns the text read so far, leaving out any escape sequence or UTF-8
+// encoded character which has only been read in part.
+func (v *PartialValue) Text() (string, error) {
+	if v.Complete || v.Kind != StringStart {
+		return v.Value.Text()
+	}
+
+	raw := trimPartialText(v.Raw)
+	return string(unquote(nil, append(raw[:len(raw):len(raw)], '"'))), nil
+}
+
+// trimPartialText trims an incomplete escape sequence or UTF-8 sequence off
+// the end of raw, the beginning of a quoted string.
+func trimPartialText(raw []byte) []byte {
+	// The last escape sequence, if it's at the very end.
+	esc := -1
+
+	for i := 1; i < len(raw); {
+		if raw[i] != '\\' {
+			i++
+			continue
+		}
+
+		n := 2
+		if i+1 < len(raw) && raw[i+1] == 'u' {
+			n = 6
+		}
+		if i+n > len(raw) {
+			return raw[:i]
+		}
+		if i+n == len(raw) {
+			esc = i
+		}
+		i += n
+	}
+
+	// A high surrogate is only decoded along with the low surrogate which
+	// follows it.
+	if esc >= 0 && raw[esc+1] == 'u' {
+		if r := hex4(raw[esc+2:]); utf16.IsSurrogate(r) && r < 0xdc00 {
+			return raw[:esc]
+		}
+	}
+
+	// Back up to the start of the last character.
+	i := len(raw) - 1
+	for i > 0 && len(raw)-i < utf8.UTFMax && !utf8.RuneStart(raw[i]) {
+		i--
+	}
+	if raw[i] >= utf8.RuneSelf && !utf8.FullRune(raw[i:]) {
+		return raw[:i]
+	}
+
+	return raw
+}
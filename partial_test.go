@@ -0,0 +1,207 @@
+package jo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func ExamplePartialParser() {
+	p := NewPartialParser()
+
+	for _, chunk := range []string{`{"city": "Par`, `is", "temp`, `": 2`, `1.5}`} {
+		if _, err := p.Write([]byte(chunk)); err != nil {
+			panic(err)
+		}
+
+		city, _ := p.Value().Get("city").Text()
+		fmt.Printf("%q complete=%v", city, p.Value().Get("city").Complete)
+		if temp := p.Value().Get("temp"); temp != nil {
+			fmt.Printf(", temp %s complete=%v", temp.Raw, temp.Complete)
+		}
+		fmt.Println()
+	}
+	// Output:
+	// "Par" complete=false
+	// "Paris" complete=true
+	// "Paris" complete=true, temp 2 complete=false
+	// "Paris" complete=true, temp 21.5 complete=true
+}
+
+// render formats the tree built by a PartialParser, marking incomplete
+// values with a trailing '~'.
+func render(v *PartialValue) string {
+	var b strings.Builder
+
+	var walk func(v *PartialValue)
+	walk = func(v *PartialValue) {
+		if v.key != nil {
+			b.WriteString(v.Key() + ":")
+		}
+
+		switch v.Kind {
+		case ObjectStart, ArrayStart:
+			open, close := "[", "]"
+			if v.Kind == ObjectStart {
+				open, close = "{", "}"
+			}
+			b.WriteString(open)
+			for i, kid := range v.Children() {
+				if i > 0 {
+					b.WriteString(" ")
+				}
+				walk(kid)
+			}
+			b.WriteString(close)
+		case StringStart:
+			text, _ := v.Text()
+			fmt.Fprintf(&b, "%q", text)
+		default:
+			b.Write(v.Raw)
+		}
+
+		if !v.Complete {
+			b.WriteString("~")
+		}
+	}
+
+	if v == nil {
+		return "<nil>"
+	}
+	walk(v)
+	return b.String()
+}
+
+var partialTests = []struct {
+	in   string
+	tree string
+}{
+	{``, `<nil>`},
+	{` `, `<nil>`},
+	{`[`, `[]~`},
+	{`[1`, `[1~]~`},
+	{`[1,`, `[1]~`},
+	{`[1, tr`, `[1 tr~]~`},
+	{`[1, true`, `[1 true]~`},
+	{`[1, true]`, `[1 true]`},
+	{`{"a`, `{}~`},
+	{`{"a":`, `{}~`},
+	{`{"a": "`, `{a:""~}~`},
+	{`{"a": "x\`, `{a:"x"~}~`},
+	{`{"a": "x\u00e`, `{a:"x"~}~`},
+	{`{"a": "xé`, `{a:"xé"~}~`},
+	{`{"a": "x\ud83d`, `{a:"x"~}~`},
+	{`{"a": "x😀`, `{a:"x😀"~}~`},
+	{"{\"a\": \"x\xc3", `{a:"x"~}~`},
+	{`{"a": "x\""`, `{a:"x\""}~`},
+	{`{"a": "b", "c": [{}, -`, `{a:"b" c:[{} -~]~}~`},
+	{`{"a": "b", "c": [{}, -1.5e3]}`, `{a:"b" c:[{} -1.5e3]}`},
+	{`12`, `12~`},
+	{`12 `, `12`},
+}
+
+func TestPartialParser(t *testing.T) {
+	for _, test := range partialTests {
+		p := NewPartialParser()
+		if _, err := p.Write([]byte(test.in)); err != nil {
+			t.Fatalf("Write(%#q): %v", test.in, err)
+		}
+
+		if got := render(p.Value()); got != test.tree {
+			t.Errorf("Write(%#q):", test.in)
+			t.Errorf("  got  %s", got)
+			t.Errorf("  want %s", test.tree)
+		}
+	}
+}
+
+// Once all input has been written, the tree matches the one built by Parse,
+// however the input was split up.
+func TestPartialParserChunks(t *testing.T) {
+	data := []byte(sample)
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want strings.Builder
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		fmt.Fprintf(&want, "%s %d %q %q\n", n.Kind, n.Offset, n.Key(), n.Raw)
+		for i := range n.Children() {
+			walk(n.Index(i))
+		}
+	}
+	walk(doc)
+
+	for _, size := range []int{1, 2, 7, 64, len(data)} {
+		p := NewPartialParser()
+		for i := 0; i < len(data); i += size {
+			if _, err := p.Write(data[i:min(i+size, len(data))]); err != nil {
+				t.Fatalf("chunk size %d: %v", size, err)
+			}
+		}
+		if err := p.Close(); err != nil {
+			t.Fatalf("chunk size %d: %v", size, err)
+		}
+
+		var got strings.Builder
+		var walk func(v *PartialValue)
+		walk = func(v *PartialValue) {
+			if !v.Complete {
+				t.Errorf("chunk size %d: incomplete value at %d", size, v.Offset)
+			}
+			fmt.Fprintf(&got, "%s %d %q %q\n", v.Kind, v.Offset, v.Key(), v.Raw)
+			for _, kid := range v.Children() {
+				walk(kid)
+			}
+		}
+		walk(p.Value())
+
+		if got.String() != want.String() {
+			t.Errorf("chunk size %d: tree differs from Parse's", size)
+		}
+	}
+}
+
+func TestPartialParserErrors(t *testing.T) {
+	p := NewPartialParser()
+	p.Write([]byte(`{"a": [1, `))
+
+	n, err := p.Write([]byte(`2}`))
+	if serr := (*SyntaxError)(nil); !errors.As(err, &serr) || serr.Offset != 11 || n != 1 {
+		t.Errorf("Write: got %d, %v; want 1 and a syntax error at offset 11", n, err)
+	}
+	if _, err2 := p.Write([]byte(`]}`)); err2 != err {
+		t.Errorf("Write after error: got %v", err2)
+	}
+
+	// The tree still holds what was read before the error.
+	if got, want := render(p.Value()), `{a:[1 2~]~}~`; got != want {
+		t.Errorf("got tree %s, want %s", got, want)
+	}
+
+	p = NewPartialParser()
+	p.Write([]byte(`[1`))
+	if err := p.Close(); err == nil {
+		t.Errorf("Close on truncated input: expected error")
+	}
+}
+
+func BenchmarkPartialParser(b *testing.B) {
+	var data = []byte(sample)
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		p := NewPartialParser()
+		for j := 0; j < len(data); j += 16 {
+			p.Write(data[j:min(j+16, len(data))])
+		}
+		p.Close()
+	}
+}
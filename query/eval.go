@@ -0,0 +1,317 @@
+package query
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+// A node is an expression in a parsed query. Evaluating it against an input
+// passes each of its outputs to out, in order, stopping at the first error.
+type node interface {
+	eval(v any, out func(any) error) error
+}
+
+// identityNode is `.`.
+type identityNode struct{}
+
+func (n *identityNode) eval(v any, out func(any) error) error {
+	return out(v)
+}
+
+// literalNode is a string, number, boolean or null literal.
+type literalNode struct {
+	v any
+}
+
+func (n *literalNode) eval(v any, out func(any) error) error {
+	return out(n.v)
+}
+
+// pipeNode is `lhs | rhs`.
+type pipeNode struct {
+	lhs, rhs node
+}
+
+func (n *pipeNode) eval(v any, out func(any) error) error {
+	return n.lhs.eval(v, func(x any) error {
+		return n.rhs.eval(x, out)
+	})
+}
+
+// commaNode is `lhs, rhs`.
+type commaNode struct {
+	lhs, rhs node
+}
+
+func (n *commaNode) eval(v any, out func(any) error) error {
+	if err := n.lhs.eval(v, out); err != nil {
+		return err
+	}
+	return n.rhs.eval(v, out)
+}
+
+// indexNode is `target[idx]`, `target.foo` or `target."foo"`. The index
+// expression is evaluated against the same input as the target.
+type indexNode struct {
+	target, idx node
+}
+
+func (n *indexNode) eval(v any, out func(any) error) error {
+	return n.target.eval(v, func(t any) error {
+		return n.idx.eval(v, func(i any) error {
+			x, err := index(t, i)
+			if err != nil {
+				return err
+			}
+			return out(x)
+		})
+	})
+}
+
+// index looks up the member or element i of t.
+func index(t, i any) (any, error) {
+	switch i := i.(type) {
+	case string:
+		switch t := t.(type) {
+		case nil:
+			return nil, nil
+		case *Object:
+			x, _ := t.Get(i)
+			return x, nil
+		}
+		return nil, &RuntimeError{"cannot index " + typeName(t) + " with " + strconv.Quote(i)}
+
+	case json.Number:
+		switch t := t.(type) {
+		case nil:
+			return nil, nil
+		case []any:
+			f := math.Floor(number(i))
+			if f < 0 {
+				f += float64(len(t))
+			}
+			if f < 0 || f >= float64(len(t)) {
+				return nil, nil
+			}
+			return t[int(f)], nil
+		}
+		return nil, &RuntimeError{"cannot index " + typeName(t) + " with number"}
+
+	case nil:
+		if t == nil {
+			return nil, nil
+		}
+	}
+
+	return nil, &RuntimeError{"cannot index " + typeName(t) + " with " + typeName(i)}
+}
+
+// iterateNode is `target[]`.
+type iterateNode struct {
+	target node
+}
+
+func (n *iterateNode) eval(v any, out func(any) error) error {
+	return n.target.eval(v, func(t any) error {
+		return iterate(t, out)
+	})
+}
+
+// iterate passes each element of an array, or each value of an object, to
+// out.
+func iterate(t any, out func(any) error) error {
+	switch t := t.(type) {
+	case []any:
+		for _, x := range t {
+			if err := out(x); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *Object:
+		for _, k := range t.keys {
+			if err := out(t.vals[k]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return &RuntimeError{"cannot iterate over " + describe(t)}
+}
+
+// logicNode is `lhs and rhs` or `lhs or rhs`. The right-hand side is only
+// evaluated when the left-hand side doesn't decide the result on its own.
+type logicNode struct {
+	lhs, rhs node
+	or       bool
+}
+
+func (n *logicNode) eval(v any, out func(any) error) error {
+	return n.lhs.eval(v, func(l any) error {
+		if truthy(l) == n.or {
+			return out(n.or)
+		}
+		return n.rhs.eval(v, func(r any) error {
+			return out(truthy(r))
+		})
+	})
+}
+
+// compareNode is a comparison. As in jq, the outputs of the right-hand side
+// form the outer loop.
+type compareNode struct {
+	lhs, rhs node
+	op       string
+}
+
+func (n *compareNode) eval(v any, out func(any) error) error {
+	return n.rhs.eval(v, func(r any) error {
+		return n.lhs.eval(v, func(l any) error {
+			c := compare(l, r)
+
+			switch n.op {
+			case "==":
+				return out(c == 0)
+			case "!=":
+				return out(c != 0)
+			case "<":
+				return out(c < 0)
+			case "<=":
+				return out(c <= 0)
+			case ">":
+				return out(c > 0)
+			}
+			return out(c >= 0)
+		})
+	})
+}
+
+// collectNode is `[e]`, or `[]` when e is nil.
+type collectNode struct {
+	n node
+}
+
+func (n *collectNode) eval(v any, out func(any) error) error {
+	arr := []any{}
+
+	if n.n != nil {
+		err := n.n.eval(v, func(x any) error {
+			arr = append(arr, x)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return out(arr)
+}
+
+// objectNode is `{k: v, ...}`. When keys or values produce several outputs,
+// one object is produced for each combination of them.
+type objectNode struct {
+	keys, vals []node
+}
+
+func (n *objectNode) eval(v any, out func(any) error) error {
+	return n.build(v, 0, nil, out)
+}
+
+// build evaluates the i:th member, for each of its outputs appending it to
+// the key-value pairs kvs before moving on to the next member.
+func (n *objectNode) build(v any, i int, kvs []any, out func(any) error) error {
+	if i == len(n.keys) {
+		obj := NewObject()
+		for j := 0; j < len(kvs); j += 2 {
+			obj.Set(kvs[j].(string), kvs[j+1])
+		}
+		return out(obj)
+	}
+
+	return n.keys[i].eval(v, func(k any) error {
+		if _, ok := k.(string); !ok {
+			return &RuntimeError{"object keys must be strings, not " + describe(k)}
+		}
+		return n.vals[i].eval(v, func(x any) error {
+			return n.build(v, i+1, append(kvs[:2*i:2*i], k, x), out)
+		})
+	})
+}
+
+// callNode is a call to a built-in function.
+type callNode struct {
+	name string
+	args []node
+}
+
+func (n *callNode) eval(v any, out func(any) error) error {
+	switch n.name {
+	case "select":
+		return n.args[0].eval(v, func(x any) error {
+			if truthy(x) {
+				return out(v)
+			}
+			return nil
+		})
+
+	case "map":
+		arr := []any{}
+		err := iterate(v, func(e any) error {
+			return n.args[0].eval(e, func(x any) error {
+				arr = append(arr, x)
+				return nil
+			})
+		})
+		if err != nil {
+			return err
+		}
+		return out(arr)
+
+	case "keys":
+		switch v := v.(type) {
+		case *Object:
+			keys := make([]any, 0, v.Len())
+			for _, k := range sortedKeys(v) {
+				keys = append(keys, k)
+			}
+			return out(keys)
+		case []any:
+			keys := make([]any, len(v))
+			for i := range v {
+				keys[i] = json.Number(strconv.Itoa(i))
+			}
+			return out(keys)
+		}
+		return &RuntimeError{describe(v) + " has no keys"}
+
+	case "length":
+		switch v := v.(type) {
+		case nil:
+			return out(json.Number("0"))
+		case json.Number:
+			return out(newNumber(math.Abs(number(v))))
+		case string:
+			return out(json.Number(strconv.Itoa(utf8.RuneCountInString(v))))
+		case []any:
+			return out(json.Number(strconv.Itoa(len(v))))
+		case *Object:
+			return out(json.Number(strconv.Itoa(v.Len())))
+		}
+		return &RuntimeError{describe(v) + " has no length"}
+
+	case "not":
+		return out(!truthy(v))
+
+	case "type":
+		return out(typeName(v))
+
+	case "empty":
+		return nil
+	}
+
+	panic("query: unknown function " + n.name)
+}
@@ -0,0 +1,557 @@
+package query
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/erkl/jo"
+)
+
+// A ParseError describes a malformed query expression.
+type ParseError struct {
+	msg string
+
+	// Offset of the error in the expression, in bytes.
+	Offset int
+}
+
+func (e *ParseError) Error() string {
+	return "query: " + e.msg + " at offset " + strconv.Itoa(e.Offset)
+}
+
+// Token kinds.
+const (
+	tokEOF = iota
+	tokIdent
+	tokField  // .foo or ."foo"
+	tokString // "foo"
+	tokNumber
+	tokPunct // everything else, including two-byte operators
+)
+
+type token struct {
+	kind int
+	text string // identifier, field or punctuation; decoded string literal
+	off  int
+}
+
+// parser is a recursive-descent parser for query expressions, with a single
+// token of lookahead.
+type parser struct {
+	src string
+	off int
+	tok token
+}
+
+func (p *parser) parse() (node, error) {
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	n, err := p.pipe()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.unexpected()
+	}
+
+	return n, nil
+}
+
+// pipe parses `a | b | ...`, the loosest-binding operator.
+func (p *parser) pipe() (node, error) {
+	lhs, err := p.comma()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.is("|") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		rhs, err := p.comma()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &pipeNode{lhs, rhs}
+	}
+
+	return lhs, nil
+}
+
+// comma parses `a, b, ...`.
+func (p *parser) comma() (node, error) {
+	lhs, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.is(",") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		rhs, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &commaNode{lhs, rhs}
+	}
+
+	return lhs, nil
+}
+
+// or parses `a or b or ...`.
+func (p *parser) or() (node, error) {
+	lhs, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.tok.kind == tokIdent && p.tok.text == "or" {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		rhs, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &logicNode{lhs, rhs, true}
+	}
+
+	return lhs, nil
+}
+
+// and parses `a and b and ...`.
+func (p *parser) and() (node, error) {
+	lhs, err := p.comparison()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.tok.kind == tokIdent && p.tok.text == "and" {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		rhs, err := p.comparison()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &logicNode{lhs, rhs, false}
+	}
+
+	return lhs, nil
+}
+
+// comparison parses a single, non-associative comparison.
+func (p *parser) comparison() (node, error) {
+	lhs, err := p.postfix()
+	if err != nil {
+		return nil, err
+	}
+
+	switch op := p.tok.text; {
+	case p.tok.kind == tokPunct && (op == "==" || op == "!=" || op == "<" || op == "<=" || op == ">" || op == ">="):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		rhs, err := p.postfix()
+		if err != nil {
+			return nil, err
+		}
+		return &compareNode{lhs, rhs, op}, nil
+	}
+
+	return lhs, nil
+}
+
+// postfix parses a primary expression followed by any number of field
+// accesses, indexes and iterations.
+func (p *parser) postfix() (node, error) {
+	n, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.tok.kind == tokField:
+			n = &indexNode{n, &literalNode{p.tok.text}}
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+
+		case p.is("["):
+			if n, err = p.brackets(n); err != nil {
+				return nil, err
+			}
+
+		case p.is(".") && p.peek() == '[':
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			if n, err = p.brackets(n); err != nil {
+				return nil, err
+			}
+
+		default:
+			return n, nil
+		}
+	}
+}
+
+// brackets parses `[]` or `[e]` applied to n.
+func (p *parser) brackets(n node) (node, error) {
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	if p.is("]") {
+		return &iterateNode{n}, p.next()
+	}
+
+	idx, err := p.pipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+
+	return &indexNode{n, idx}, nil
+}
+
+// primary parses literals, the identity, function calls, and parenthesized,
+// array and object expressions.
+func (p *parser) primary() (node, error) {
+	tok := p.tok
+
+	switch tok.kind {
+	case tokField:
+		// A leading field access applies to the input.
+		return &identityNode{}, nil
+
+	case tokString:
+		return &literalNode{tok.text}, p.next()
+
+	case tokNumber:
+		return &literalNode{json.Number(tok.text)}, p.next()
+
+	case tokIdent:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		return p.call(tok)
+
+	case tokPunct:
+		switch tok.text {
+		case ".":
+			return &identityNode{}, p.next()
+
+		case "(":
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			n, err := p.pipe()
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+
+		case "[":
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			if p.is("]") {
+				return &collectNode{nil}, p.next()
+			}
+			n, err := p.pipe()
+			if err != nil {
+				return nil, err
+			}
+			return &collectNode{n}, p.expect("]")
+
+		case "{":
+			return p.object()
+		}
+	}
+
+	return nil, p.unexpected()
+}
+
+// builtins lists the arity of each built-in function.
+var builtins = map[string]int{
+	"select": 1,
+	"map":    1,
+	"keys":   0,
+	"length": 0,
+	"not":    0,
+	"type":   0,
+	"empty":  0,
+}
+
+// call parses the rest of a literal keyword or function call starting with
+// the identifier tok.
+func (p *parser) call(tok token) (node, error) {
+	switch tok.text {
+	case "null":
+		return &literalNode{nil}, nil
+	case "true":
+		return &literalNode{true}, nil
+	case "false":
+		return &literalNode{false}, nil
+	}
+
+	arity, ok := builtins[tok.text]
+	if !ok {
+		return nil, &ParseError{"unknown function " + strconv.Quote(tok.text), tok.off}
+	}
+
+	var args []node
+	if p.is("(") {
+		for {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			arg, err := p.pipe()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+
+			if !p.is(";") {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(args) != arity {
+		return nil, &ParseError{tok.text + "/" + strconv.Itoa(len(args)) + " is not defined", tok.off}
+	}
+
+	return &callNode{tok.text, args}, nil
+}
+
+// object parses an object construction expression.
+func (p *parser) object() (node, error) {
+	var n objectNode
+
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.is("}") {
+		return &n, p.next()
+	}
+
+	for {
+		var key, val node
+
+		switch tok := p.tok; {
+		case tok.kind == tokIdent || tok.kind == tokString:
+			key = &literalNode{tok.text}
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			// Shorthand for {a: .a}.
+			if !p.is(":") {
+				val = &indexNode{&identityNode{}, key}
+			}
+
+		case tok.kind == tokPunct && tok.text == "(":
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			k, err := p.pipe()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			key = k
+
+		default:
+			return nil, p.unexpected()
+		}
+
+		if val == nil {
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			v, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			val = v
+		}
+
+		n.keys = append(n.keys, key)
+		n.vals = append(n.vals, val)
+
+		if !p.is(",") {
+			break
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &n, p.expect("}")
+}
+
+// is reports whether the current token is the punctuation s.
+func (p *parser) is(s string) bool {
+	return p.tok.kind == tokPunct && p.tok.text == s
+}
+
+// expect consumes the punctuation s, or fails.
+func (p *parser) expect(s string) error {
+	if !p.is(s) {
+		return p.unexpected()
+	}
+	return p.next()
+}
+
+// unexpected returns an error describing the current token.
+func (p *parser) unexpected() error {
+	switch p.tok.kind {
+	case tokEOF:
+		return &ParseError{"unexpected end of expression", p.tok.off}
+	case tokString:
+		return &ParseError{"unexpected string", p.tok.off}
+	case tokNumber:
+		return &ParseError{"unexpected number " + p.tok.text, p.tok.off}
+	case tokField:
+		return &ParseError{"unexpected field access", p.tok.off}
+	}
+	return &ParseError{"unexpected " + strconv.Quote(p.tok.text), p.tok.off}
+}
+
+// peek returns the byte immediately following the current token, or 0.
+func (p *parser) peek() byte {
+	if p.off < len(p.src) {
+		return p.src[p.off]
+	}
+	return 0
+}
+
+// next reads the next token.
+func (p *parser) next() error {
+	for p.off < len(p.src) && isSpace(p.src[p.off]) {
+		p.off++
+	}
+
+	start := p.off
+	p.tok = token{off: start}
+
+	if start == len(p.src) {
+		p.tok.kind = tokEOF
+		return nil
+	}
+
+	switch c := p.src[start]; {
+	case c == '.':
+		p.off++
+		if p.off < len(p.src) && isIdentStart(p.src[p.off]) {
+			p.off = identEnd(p.src, p.off)
+			p.tok.kind, p.tok.text = tokField, p.src[start+1:p.off]
+			return nil
+		}
+		if p.off < len(p.src) && p.src[p.off] == '"' {
+			s, err := p.string()
+			if err != nil {
+				return err
+			}
+			p.tok.kind, p.tok.text = tokField, s
+			return nil
+		}
+		p.tok.kind, p.tok.text = tokPunct, "."
+
+	case isIdentStart(c):
+		p.off = identEnd(p.src, start)
+		p.tok.kind, p.tok.text = tokIdent, p.src[start:p.off]
+
+	case c == '"':
+		s, err := p.string()
+		if err != nil {
+			return err
+		}
+		p.tok.kind, p.tok.text = tokString, s
+
+	case c == '-' || '0' <= c && c <= '9':
+		p.off = numberEnd(p.src, start)
+		p.tok.kind, p.tok.text = tokNumber, p.src[start:p.off]
+		if jo.Validate([]byte(p.tok.text)) != nil {
+			return &ParseError{"invalid number " + p.tok.text, start}
+		}
+
+	default:
+		p.off++
+		if p.off < len(p.src) && p.src[p.off] == '=' && (c == '=' || c == '!' || c == '<' || c == '>') {
+			p.off++
+		}
+		p.tok.kind, p.tok.text = tokPunct, p.src[start:p.off]
+
+		switch p.tok.text {
+		case "|", ",", "(", ")", "[", "]", "{", "}", ":", ";", "==", "!=", "<", "<=", ">", ">=":
+		default:
+			return &ParseError{"unexpected character " + strconv.Quote(p.tok.text), start}
+		}
+	}
+
+	return nil
+}
+
+// string reads a JSON string literal starting at the current offset.
+func (p *parser) string() (string, error) {
+	start := p.off
+
+	for i := start + 1; i < len(p.src); i++ {
+		switch p.src[i] {
+		case '\\':
+			i++
+		case '"':
+			raw := []byte(p.src[start : i+1])
+			if jo.Validate(raw) != nil {
+				return "", &ParseError{"invalid string literal", start}
+			}
+			p.off = i + 1
+			return jo.Unquote(raw), nil
+		}
+	}
+
+	return "", &ParseError{"unterminated string literal", start}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isIdentStart(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_'
+}
+
+func identEnd(s string, i int) int {
+	for i < len(s) && (isIdentStart(s[i]) || '0' <= s[i] && s[i] <= '9') {
+		i++
+	}
+	return i
+}
+
+func numberEnd(s string, start int) int {
+	i := start
+	for i < len(s) {
+		switch c := s[i]; {
+		case '0' <= c && c <= '9', c == '.', c == 'e', c == 'E':
+		case c == '-' && i == start:
+		case (c == '-' || c == '+') && i > start && (s[i-1] == 'e' || s[i-1] == 'E'):
+		default:
+			return i
+		}
+		i++
+	}
+	return i
+}
@@ -0,0 +1,302 @@
+// Package query implements a practical subset of the jq language for
+// querying JSON values.
+//
+// The supported subset consists of:
+//
+//	.                 identity
+//	.foo, ."foo"      object member access, yielding null for missing keys
+//	.[e]              object member or array element access, with negative
+//	                  indices counting from the end
+//	.[]               iteration over array elements or object values
+//	a | b             pipes, feeding each output of a into b
+//	a, b              concatenation of the outputs of a and b
+//	[e]               array construction
+//	{a: e, "b": e, (e): e, c}
+//	                  object construction
+//	== != < <= > >=   comparisons, using jq's ordering of values
+//	and, or, not      boolean logic
+//	select(e), map(e), keys, length, type, empty
+//	                  built-in functions
+//	"str", 12, true, false, null
+//	                  literals
+//
+// Values are represented as nil, bool, json.Number, string, []any and
+// *Object. Numbers keep their original text until arithmetic is done on them.
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"iter"
+	"slices"
+	"strconv"
+
+	"github.com/erkl/jo"
+)
+
+// A Query is a parsed query expression.
+type Query struct {
+	root node
+}
+
+// Parse parses a query expression.
+func Parse(expr string) (*Query, error) {
+	p := &parser{src: expr}
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return &Query{root}, nil
+}
+
+// Run evaluates the query against v, yielding its outputs. Evaluation stops
+// at the first error, which is yielded last.
+func (q *Query) Run(v any) iter.Seq2[any, error] {
+	return func(yield func(any, error) bool) {
+		err := q.root.eval(v, func(out any) error {
+			if !yield(out, nil) {
+				return errStop
+			}
+			return nil
+		})
+		if err != nil && err != errStop {
+			yield(nil, err)
+		}
+	}
+}
+
+// errStop is used to unwind evaluation when a caller stops iterating.
+var errStop = errors.New("stop")
+
+// A RuntimeError describes a failure to evaluate a query, such as indexing
+// a number.
+type RuntimeError struct {
+	msg string
+}
+
+func (e *RuntimeError) Error() string {
+	return "query: " + e.msg
+}
+
+// An Object is a JSON object whose members keep their insertion order.
+type Object struct {
+	keys []string
+	vals map[string]any
+}
+
+// NewObject returns an empty Object.
+func NewObject() *Object {
+	return &Object{vals: map[string]any{}}
+}
+
+// Len returns the number of members in the object.
+func (o *Object) Len() int {
+	return len(o.keys)
+}
+
+// Keys returns the object's keys in insertion order.
+func (o *Object) Keys() []string {
+	return o.keys
+}
+
+// Get returns the value of the member with the given key.
+func (o *Object) Get(key string) (any, bool) {
+	v, ok := o.vals[key]
+	return v, ok
+}
+
+// Set sets the value of the member with the given key. New members are
+// added at the end, while existing ones keep their position.
+func (o *Object) Set(key string, v any) {
+	if _, ok := o.vals[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.vals[key] = v
+}
+
+// MarshalJSON encodes the object with its members in insertion order.
+func (o *Object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := jo.NewWriter(&buf)
+	w.SetEscapeHTML(false)
+
+	if err := Write(w, o); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Write writes a query value using w. Unlike json.Marshal, it doesn't
+// reorder object members.
+func Write(w *jo.Writer, v any) error {
+	switch v := v.(type) {
+	case nil:
+		return w.Null()
+	case bool:
+		return w.Bool(v)
+	case json.Number:
+		return w.Number(string(v))
+	case string:
+		return w.String(v)
+
+	case []any:
+		if err := w.BeginArray(); err != nil {
+			return err
+		}
+		for _, e := range v {
+			if err := Write(w, e); err != nil {
+				return err
+			}
+		}
+		return w.EndArray()
+
+	case *Object:
+		if err := w.BeginObject(); err != nil {
+			return err
+		}
+		for _, k := range v.keys {
+			if err := w.Key(k); err != nil {
+				return err
+			}
+			if err := Write(w, v.vals[k]); err != nil {
+				return err
+			}
+		}
+		return w.EndObject()
+	}
+
+	return &RuntimeError{"unsupported value of type " + typeName(v)}
+}
+
+// typeName returns the jq name of a value's type.
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case *Object:
+		return "object"
+	}
+	return "invalid"
+}
+
+// describe returns a short description of a value for error messages.
+func describe(v any) string {
+	var buf bytes.Buffer
+
+	w := jo.NewWriter(&buf)
+	w.SetEscapeHTML(false)
+	Write(w, v)
+	w.Flush()
+
+	s := buf.String()
+	if len(s) > 11 {
+		s = s[:10] + "..."
+	}
+	return typeName(v) + " (" + s + ")"
+}
+
+// truthy reports whether v counts as true: anything but null and false.
+func truthy(v any) bool {
+	return v != nil && v != false
+}
+
+// number converts a json.Number to a float64.
+func number(n json.Number) float64 {
+	f, _ := strconv.ParseFloat(string(n), 64)
+	return f
+}
+
+// newNumber converts a float64 to a json.Number.
+func newNumber(f float64) json.Number {
+	return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+// typeOrder ranks value types in jq's sort order.
+func typeOrder(v any) int {
+	switch v := v.(type) {
+	case nil:
+		return 0
+	case bool:
+		if !v {
+			return 1
+		}
+		return 2
+	case json.Number:
+		return 3
+	case string:
+		return 4
+	case []any:
+		return 5
+	}
+	return 6
+}
+
+// compare orders two values the way jq does: null, false, true, numbers,
+// strings, arrays and objects, with arrays compared element-wise, and
+// objects compared by their sorted keys first and their values second.
+func compare(a, b any) int {
+	if ta, tb := typeOrder(a), typeOrder(b); ta != tb {
+		return ta - tb
+	}
+
+	switch a := a.(type) {
+	case json.Number:
+		fa, fb := number(a), number(b.(json.Number))
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return +1
+		}
+		return 0
+
+	case string:
+		b := b.(string)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return +1
+		}
+		return 0
+
+	case []any:
+		return slices.CompareFunc(a, b.([]any), compare)
+
+	case *Object:
+		b := b.(*Object)
+
+		ka, kb := sortedKeys(a), sortedKeys(b)
+		if c := slices.Compare(ka, kb); c != 0 {
+			return c
+		}
+		for _, k := range ka {
+			if c := compare(a.vals[k], b.vals[k]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	return 0
+}
+
+// sortedKeys returns the keys of an object in sorted order.
+func sortedKeys(o *Object) []string {
+	keys := slices.Clone(o.keys)
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,312 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/erkl/jo"
+)
+
+func ExampleQuery() {
+	q, err := Parse(`.[] | select(.age > 30) | {name, tags: (.tags | length)}`)
+	if err != nil {
+		panic(err)
+	}
+
+	s := NewStream(strings.NewReader(`[
+		{"name": "ada", "age": 36, "tags": ["math"]},
+		{"name": "alan", "age": 24, "tags": []},
+		{"name": "grace", "age": 45, "tags": ["navy", "cobol"]}
+	]`))
+
+	v, err := s.Next()
+	if err != nil {
+		panic(err)
+	}
+
+	for out, err := range q.Run(v) {
+		if err != nil {
+			panic(err)
+		}
+
+		// A Writer writes a single value.
+		w := jo.NewWriter(os.Stdout)
+		Write(w, out)
+		w.Close()
+		fmt.Println()
+	}
+	// Output:
+	// {"name":"ada","tags":1}
+	// {"name":"grace","tags":2}
+}
+
+var queryTests = []struct {
+	expr, in string
+	out      []string
+}{
+	{`.`, `{"b":1,"a":2}`, []string{`{"b":1,"a":2}`}},
+	{`.a`, `{"a":{"b":[1]}}`, []string{`{"b":[1]}`}},
+	{`.a.b`, `{"a":{"b":[1]}}`, []string{`[1]`}},
+	{`.missing`, `{"a":1}`, []string{`null`}},
+	{`.a.b.c`, `null`, []string{`null`}},
+	{`."a b"`, `{"a b":true}`, []string{`true`}},
+	{`.["a"]`, `{"a":"x"}`, []string{`"x"`}},
+	{`.a["b"]`, `{"a":{"b":"x"}}`, []string{`"x"`}},
+	{`.[0]`, `[1,2,3]`, []string{`1`}},
+	{`.[-1]`, `[1,2,3]`, []string{`3`}},
+	{`.[3]`, `[1,2,3]`, []string{`null`}},
+	{`.[-4]`, `[1,2,3]`, []string{`null`}},
+	{`.[1.7]`, `[1,2,3]`, []string{`2`}},
+	{`.a.[0]`, `{"a":[5]}`, []string{`5`}},
+	{`.[.i]`, `{"i":"j","j":7}`, []string{`7`}},
+	{`.[]`, `[1,"a",null]`, []string{`1`, `"a"`, `null`}},
+	{`.[]`, `{"x":1,"y":2}`, []string{`1`, `2`}},
+	{`.[]`, `[]`, nil},
+	{`.a[].b`, `{"a":[{"b":1},{"b":2}]}`, []string{`1`, `2`}},
+	{`.[][]`, `[[1,2],[3]]`, []string{`1`, `2`, `3`}},
+	{`.[] | .x`, `[{"x":1},{"x":2}]`, []string{`1`, `2`}},
+	{`.a, .b`, `{"a":1,"b":2}`, []string{`1`, `2`}},
+	{`.a, .b | .c`, `{"a":{"c":1},"b":{"c":2}}`, []string{`1`, `2`}},
+	{`[.[] | .x]`, `[{"x":1},{"x":2}]`, []string{`[1,2]`}},
+	{`[]`, `null`, []string{`[]`}},
+	{`[.[] | select(. > 1)]`, `[1,2,3]`, []string{`[2,3]`}},
+	{`.[] | select(.ok)`, `[{"ok":true,"n":1},{"ok":false,"n":2},{"n":3}]`, []string{`{"ok":true,"n":1}`}},
+	{`map(.n)`, `[{"n":1},{"n":2}]`, []string{`[1,2]`}},
+	{`map(.[])`, `[[1,2],[],[3]]`, []string{`[1,2,3]`}},
+	{`map(. == 1)`, `{"a":1,"b":2}`, []string{`[true,false]`}},
+	{`keys`, `{"b":1,"a":2,"c":3}`, []string{`["a","b","c"]`}},
+	{`keys`, `[7,8]`, []string{`[0,1]`}},
+	{`length`, `[1,2,3]`, []string{`3`}},
+	{`length`, `{"a":1}`, []string{`1`}},
+	{`length`, `"héllo"`, []string{`5`}},
+	{`length`, `null`, []string{`0`}},
+	{`length`, `-2.5`, []string{`2.5`}},
+	{`type`, `[]`, []string{`"array"`}},
+	{`.[] | type`, `[null,true,1,"",[],{}]`, []string{`"null"`, `"boolean"`, `"number"`, `"string"`, `"array"`, `"object"`}},
+	{`.a | not`, `{"a":null}`, []string{`true`}},
+	{`empty`, `1`, nil},
+	{`1, empty, 2`, `null`, []string{`1`, `2`}},
+	{`{a: 1, "b c": .x, (.k): 2}`, `{"x":true,"k":"key"}`, []string{`{"a":1,"b c":true,"key":2}`}},
+	{`{a, b}`, `{"a":1,"b":2,"c":3}`, []string{`{"a":1,"b":2}`}},
+	{`{"a"}`, `{"a":1}`, []string{`{"a":1}`}},
+	{`{}`, `null`, []string{`{}`}},
+	{`{a: (1, 2), b: (3, 4)}`, `null`, []string{`{"a":1,"b":3}`, `{"a":1,"b":4}`, `{"a":2,"b":3}`, `{"a":2,"b":4}`}},
+	{`{b: 1, a: 2, b: 3}`, `null`, []string{`{"b":3,"a":2}`}},
+	{`.a == .b`, `{"a":[1,{"x":2}],"b":[1,{"x":2}]}`, []string{`true`}},
+	{`.a != .b`, `{"a":1,"b":1.0}`, []string{`false`}},
+	{`. < 1`, `0.5`, []string{`true`}},
+	{`.[0] < .[1]`, `[null,false]`, []string{`true`}},
+	{`.[0] < .[1]`, `[true,0]`, []string{`true`}},
+	{`.[0] < .[1]`, `[99,""]`, []string{`true`}},
+	{`.[0] < .[1]`, `["z",[]]`, []string{`true`}},
+	{`.[0] < .[1]`, `[[9],{}]`, []string{`true`}},
+	{`.[0] < .[1]`, `[[1,2],[1,3]]`, []string{`true`}},
+	{`.[0] < .[1]`, `[{"a":9},{"b":1}]`, []string{`true`}},
+	{`.[0] <= .[1]`, `["ab","ab"]`, []string{`true`}},
+	{`.[0] >= .[1]`, `["ab","b"]`, []string{`false`}},
+	{`.[0] > .[1]`, `[10,9]`, []string{`true`}},
+	{`(1, 2) == (1, 2)`, `null`, []string{`true`, `false`, `false`, `true`}},
+	{`(1, 2) < (2, 3)`, `null`, []string{`true`, `false`, `true`, `true`}},
+	{`.a and .b`, `{"a":1,"b":null}`, []string{`false`}},
+	{`.a or .b`, `{"a":false,"b":0}`, []string{`true`}},
+	{`.a > 1 and .a < 3 or .b`, `{"a":2}`, []string{`true`}},
+	{`"x", 1.50, true, false, null`, `0`, []string{`"x"`, `1.50`, `true`, `false`, `null`}},
+	{`"é\n"`, `0`, []string{`"é\n"`}},
+	{`.a | {(.[]): 1}`, `{"a":["x","y"]}`, []string{`{"x":1}`, `{"y":1}`}},
+	{`[.[] | {n: .}] | length`, `[1,2]`, []string{`2`}},
+	{`.n`, `{"n":1.000000000000000000001}`, []string{`1.000000000000000000001`}},
+	{`.s`, `{"s":"<&>"}`, []string{`"<&>"`}},
+}
+
+func TestQuery(t *testing.T) {
+	for _, test := range queryTests {
+		q, err := Parse(test.expr)
+		if err != nil {
+			t.Errorf("Parse(%#q): %v", test.expr, err)
+			continue
+		}
+
+		got, err := run(q, test.in)
+		if err != nil || !slices.Equal(got, test.out) {
+			t.Errorf("%#q on %s:", test.expr, test.in)
+			t.Errorf("  got  %q, %v", got, err)
+			t.Errorf("  want %q", test.out)
+		}
+	}
+}
+
+var runtimeErrorTests = []struct {
+	expr, in string
+	out      []string
+	err      string
+}{
+	{`.a`, `1`, nil, `query: cannot index number with "a"`},
+	{`.[0]`, `{"a":1}`, nil, `query: cannot index object with number`},
+	{`.[true]`, `[1]`, nil, `query: cannot index array with boolean`},
+	{`.[]`, `"abc"`, nil, `query: cannot iterate over string ("abc")`},
+	{`.[]`, `1`, nil, `query: cannot iterate over number (1)`},
+	{`.a[]`, `{"a":"a very long string"}`, nil, `query: cannot iterate over string ("a very lo...)`},
+	{`keys`, `1`, nil, `query: number (1) has no keys`},
+	{`length`, `true`, nil, `query: boolean (true) has no length`},
+	{`{(.): 1}`, `1`, nil, `query: object keys must be strings, not number (1)`},
+	{`.[] | .a`, `[{"a":1},2]`, []string{`1`}, `query: cannot index number with "a"`},
+}
+
+func TestQueryErrors(t *testing.T) {
+	for _, test := range runtimeErrorTests {
+		q, err := Parse(test.expr)
+		if err != nil {
+			t.Errorf("Parse(%#q): %v", test.expr, err)
+			continue
+		}
+
+		got, err := run(q, test.in)
+		if re := (*RuntimeError)(nil); !errors.As(err, &re) || err.Error() != test.err || !slices.Equal(got, test.out) {
+			t.Errorf("%#q on %s:", test.expr, test.in)
+			t.Errorf("  got  %q, %v", got, err)
+			t.Errorf("  want %q, %s", test.out, test.err)
+		}
+	}
+}
+
+var parseErrorTests = []struct {
+	expr string
+	err  string
+}{
+	{``, `query: unexpected end of expression at offset 0`},
+	{`.a |`, `query: unexpected end of expression at offset 4`},
+	{`.a ]`, `query: unexpected "]" at offset 3`},
+	{`.[1`, `query: unexpected end of expression at offset 3`},
+	{`{a: 1`, `query: unexpected end of expression at offset 5`},
+	{`{1: 2}`, `query: unexpected number 1 at offset 1`},
+	{`."abc`, `query: unterminated string literal at offset 1`},
+	{`"\x"`, `query: invalid string literal at offset 0`},
+	{`01`, `query: invalid number 01 at offset 0`},
+	{`.a = 1`, `query: unexpected character "=" at offset 3`},
+	{`false and error`, `query: unknown function "error" at offset 10`},
+	{`select`, `query: select/0 is not defined at offset 0`},
+	{`length(1)`, `query: length/1 is not defined at offset 0`},
+	{`1 == 2 == 3`, `query: unexpected "==" at offset 7`},
+	{`{.a}`, `query: unexpected field access at offset 1`},
+	{`"a" "b"`, `query: unexpected string at offset 4`},
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, test := range parseErrorTests {
+		_, err := Parse(test.expr)
+		if pe := (*ParseError)(nil); !errors.As(err, &pe) || err.Error() != test.err {
+			t.Errorf("Parse(%#q):", test.expr)
+			t.Errorf("  got  %v", err)
+			t.Errorf("  want %s", test.err)
+		}
+	}
+}
+
+func TestRunStop(t *testing.T) {
+	q, err := Parse(`.[]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := 0
+	for _, err := range q.Run([]any{1, 2, 3}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n++; n == 2 {
+			break
+		}
+	}
+	if n != 2 {
+		t.Errorf("got %d outputs, want 2", n)
+	}
+}
+
+func TestStream(t *testing.T) {
+	s := NewStream(strings.NewReader("{\"a\":[1,{}]}\n\n  2 \"x\"\r\n[]"))
+
+	var got []string
+	for {
+		v, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, encode(v))
+	}
+
+	want := []string{`{"a":[1,{}]}`, `2`, `"x"`, `[]`}
+	if !slices.Equal(got, want) {
+		t.Errorf("got  %q", got)
+		t.Errorf("want %q", want)
+	}
+}
+
+func TestStreamErrors(t *testing.T) {
+	for _, in := range []string{
+		`{"a":1} {"a"`,
+		`[1, 2`,
+		`{"a":1} ]`,
+		`[1,]`,
+	} {
+		s := NewStream(strings.NewReader(in))
+
+		var err error
+		for err == nil {
+			_, err = s.Next()
+		}
+		if se := (*jo.SyntaxError)(nil); !errors.As(err, &se) {
+			t.Errorf("Stream on %#q: got %v, want a syntax error", in, err)
+		}
+	}
+}
+
+func TestObjectMarshalJSON(t *testing.T) {
+	o := NewObject()
+	o.Set("z", json.Number("1"))
+	o.Set("a", []any{"<", nil})
+	o.Set("z", true)
+
+	got, err := o.MarshalJSON()
+	if want := `{"z":true,"a":["<",null]}`; err != nil || string(got) != want {
+		t.Errorf("got  %s, %v", got, err)
+		t.Errorf("want %s", want)
+	}
+}
+
+// run evaluates q on the JSON value in, returning its outputs encoded.
+func run(q *Query, in string) ([]string, error) {
+	v, err := NewStream(strings.NewReader(in)).Next()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []string
+	for x, err := range q.Run(v) {
+		if err != nil {
+			return out, err
+		}
+		out = append(out, encode(x))
+	}
+	return out, nil
+}
+
+func encode(v any) string {
+	var buf bytes.Buffer
+	w := jo.NewWriter(&buf)
+	w.SetEscapeHTML(false)
+	if err := Write(w, v); err != nil {
+		return "!" + err.Error()
+	}
+	w.Close()
+	return buf.String()
+}
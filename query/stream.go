@@ -0,0 +1,84 @@
+package query
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/erkl/jo"
+)
+
+// A Stream reads a sequence of whitespace-separated JSON values, such as
+// newline-delimited JSON, one value at a time. Only the value being read is
+// held in memory.
+type Stream struct {
+	d *jo.Decoder
+}
+
+// NewStream returns a Stream reading from r.
+func NewStream(r io.Reader) *Stream {
+	d := jo.NewDecoder(r)
+	d.UseNumber()
+	return &Stream{d}
+}
+
+// Next reads the next value from the stream. At the end of the stream, Next
+// returns nil, io.EOF. Malformed input is reported using *jo.SyntaxError.
+func (s *Stream) Next() (any, error) {
+	tok, err := s.d.Token()
+	if err != nil {
+		return nil, err
+	}
+	return s.value(tok)
+}
+
+// Offset returns the input offset following the most recently read value.
+func (s *Stream) Offset() int64 {
+	return s.d.InputOffset()
+}
+
+// value reads the rest of the value starting with tok.
+func (s *Stream) value(tok json.Token) (any, error) {
+	switch tok {
+	case json.Delim('['):
+		arr := []any{}
+		for {
+			tok, err := s.d.Token()
+			if err != nil {
+				return nil, err
+			}
+			if tok == json.Delim(']') {
+				return arr, nil
+			}
+
+			v, err := s.value(tok)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+
+	case json.Delim('{'):
+		obj := NewObject()
+		for {
+			tok, err := s.d.Token()
+			if err != nil {
+				return nil, err
+			}
+			if tok == json.Delim('}') {
+				return obj, nil
+			}
+
+			tok2, err := s.d.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := s.value(tok2)
+			if err != nil {
+				return nil, err
+			}
+			obj.Set(tok.(string), v)
+		}
+	}
+
+	return tok, nil
+}
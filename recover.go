@@ -0,0 +1,193 @@
+package jo
+
+// ValidateAll is like Validate, but rather than stopping at the first syntax
+// error it resynchronizes and keeps going, so that every problem in data can
+// be reported at once. At most max errors are returned, or all of them if
+// max is zero or less.
+//
+// After an error, scanning resumes at the next ',', '}' or ']' which fits
+// the structure scanned so far; everything in between is skipped. A value
+// following a complete top-level value is instead scanned as a new value,
+// as in a stream. Since the recovery is heuristic, errors after the first
+// may be caused by the first.
+func ValidateAll(data []byte, max int) []*SyntaxError {
+	return validateAll(data, false, max)
+}
+
+// ValidateLines is like ValidateAll, but treats data as newline-delimited
+// JSON: each non-blank line must hold a single JSON value, and scanning also
+// resynchronizes at the start of the next line.
+func ValidateLines(data []byte, max int) []*SyntaxError {
+	return validateAll(data, true, max)
+}
+
+func validateAll(data []byte, lines bool, max int) []*SyntaxError {
+	var errs []*SyntaxError
+
+	r := newRecoverer(lines)
+	r.run(data, len(data), nil, func(err *SyntaxError) bool {
+		errs = append(errs, err)
+		return max <= 0 || len(errs) < max
+	})
+
+	return errs
+}
+
+// Bytes tried, in order, when filling in whatever is needed for scanning to
+// resume at a boundary. They're the same as for completion, minus the
+// closing delimiters, since the boundary belongs to the current container.
+var resyncBytes = completionBytes[2:]
+
+// recoverer drives a Scanner through input, resynchronizing it after errors.
+type recoverer struct {
+	// The Scanner, and a copy of it from before the latest byte.
+	s, prev *Scanner
+
+	// Scratch Scanners used for trying out bytes.
+	p, q *Scanner
+
+	lines bool
+}
+
+func newRecoverer(lines bool) *recoverer {
+	return &recoverer{
+		s:     NewScanner(),
+		prev:  NewScanner(),
+		p:     NewScanner(),
+		q:     NewScanner(),
+		lines: lines,
+	}
+}
+
+// run scans data[:stop], passing the events produced by each byte to track
+// and syntax errors to report, until report returns false. The events of
+// any bytes filled in during recovery are passed to track along with the
+// offset of the boundary at which scanning resumed. If stop is len(data),
+// the end of input is checked as well.
+func (r *recoverer) run(data []byte, stop int, track func(ev Event, i int), report func(*SyntaxError) bool) {
+	if track == nil {
+		track = func(Event, int) {}
+	}
+
+	// Whether the current line holds anything but whitespace.
+	started := false
+
+	for i := 0; i < stop; i++ {
+		c := data[i]
+
+		if r.lines && c == '\n' {
+			if started {
+				r.p.copyFrom(r.s)
+				if r.p.End() == Error && !report(r.p.syntaxError(int64(i))) {
+					return
+				}
+				r.s.Reset()
+				started = false
+			}
+			continue
+		}
+
+		r.prev.copyFrom(r.s)
+
+		ev := r.s.Scan(c)
+		if ev != Error {
+			started = started || ev&Start != 0
+			track(ev, i)
+			continue
+		}
+
+		if !report(r.s.syntaxError(int64(i))) {
+			return
+		}
+
+		// A value following a complete top-level value starts over, as
+		// in a stream of values.
+		if r.p.copyFrom(r.prev); r.p.End() != Error {
+			r.s.Reset()
+			if ev := r.s.Scan(c); ev != Error {
+				started = true
+				track(ev, i)
+				continue
+			}
+		}
+
+		// Find the first boundary at which scanning can resume.
+		j := i
+		for ; j < stop; j++ {
+			b := data[j]
+
+			if r.lines && b == '\n' {
+				// Start over on the next line.
+				r.s.Reset()
+				started = false
+				break
+			}
+
+			if b == ',' || b == '}' || b == ']' {
+				if fill, ok := r.resync(b); ok {
+					r.s.copyFrom(r.prev)
+					for _, f := range fill {
+						track(r.s.Scan(f)&^(KeyStart|KeyEnd), j)
+					}
+					track(r.s.Scan(b), j)
+					break
+				}
+			}
+		}
+
+		if j == stop {
+			// Nothing left to resynchronize on.
+			return
+		}
+		i = j
+	}
+
+	if stop == len(data) && (!r.lines || started) {
+		r.p.copyFrom(r.s)
+		if r.p.End() == Error {
+			report(r.p.syntaxError(int64(len(data))))
+		}
+	}
+}
+
+// resync works out which bytes have to be filled in after the state saved
+// in r.prev for the boundary b to be accepted.
+func (r *recoverer) resync(b byte) (fill []byte, ok bool) {
+	r.p.copyFrom(r.prev)
+
+	for steps := 0; steps < 16; steps++ {
+		// Inside a string, where the boundary would be taken as just
+		// another character, only a quote makes progress.
+		candidates := resyncBytes
+		r.q.copyFrom(r.p)
+		if ev := r.q.Scan('x'); ev != Error && ev&Start == 0 {
+			candidates = []byte{'"'}
+		} else {
+			r.q.copyFrom(r.p)
+			if r.q.Scan(b) != Error {
+				return fill, true
+			}
+		}
+
+		progress := false
+		for _, c := range candidates {
+			r.q.copyFrom(r.p)
+
+			ev := r.q.Scan(c)
+			if ev == Error || c == 'n' && ev&NullStart == 0 {
+				continue
+			}
+
+			fill = append(fill, c)
+			r.p, r.q = r.q, r.p
+			progress = true
+			break
+		}
+
+		if !progress {
+			break
+		}
+	}
+
+	return nil, false
+}
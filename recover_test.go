@@ -0,0 +1,119 @@
+package jo
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func ExampleValidateAll() {
+	data := []byte(`{"a": 1 "b": 2, "c": tru, "d": [1, 2,]}`)
+
+	for _, err := range ValidateAll(data, 0) {
+		fmt.Printf("%d: %v\n", err.Offset, err)
+	}
+	// Output:
+	// 8: invalid character '"': expected ',' or '}' after object value
+	// 24: invalid character ',': expected 'e' in literal true
+	// 37: invalid character ']': expected value after ','; trailing commas are not allowed
+}
+
+var validateAllTests = []struct {
+	in      string
+	offsets []int64
+}{
+	{`{"a":1}`, nil},
+	{`{"a":1,}`, []int64{7}},
+	{`[1,]`, []int64{3}},
+	{`[1, 2} , 3 x]`, []int64{5, 11}},
+	{`{"a": 1 "b": 2, "c": tru, "d": [1 2, 3], "e": {"x" 1}, "f": ?}`, []int64{8, 24, 34, 51, 60}},
+	{`[{"a":"x}, 1 2]`, []int64{15}},
+	{`[1,2] x`, []int64{6}},
+	{`[1 x`, []int64{3}},
+	{`1 2 [x]`, []int64{2, 4, 5}},
+	{"{\"a\":1}\n{\"b\":[1,}\n", []int64{8, 16}},
+	{`[1] } "a"`, []int64{4}},
+	{``, []int64{0}},
+}
+
+func TestValidateAll(t *testing.T) {
+	for _, test := range validateAllTests {
+		var got []int64
+		for _, err := range ValidateAll([]byte(test.in), 0) {
+			got = append(got, err.Offset)
+		}
+
+		if !slices.Equal(got, test.offsets) {
+			t.Errorf("ValidateAll(%#q):", test.in)
+			t.Errorf("  got  %v", got)
+			t.Errorf("  want %v", test.offsets)
+		}
+
+		// The first error is always the one Validate finds.
+		if err := Validate([]byte(test.in)); (err == nil) != (len(got) == 0) || err != nil && err.(*SyntaxError).Offset != got[0] {
+			t.Errorf("ValidateAll(%#q): first error differs from Validate's %v", test.in, err)
+		}
+	}
+}
+
+func TestValidateAllMax(t *testing.T) {
+	data := []byte(`[1 2, 3 4, 5 6, 7 8]`)
+
+	if got := len(ValidateAll(data, 0)); got != 4 {
+		t.Errorf("max 0: got %d errors, want 4", got)
+	}
+	if got := len(ValidateAll(data, 2)); got != 2 {
+		t.Errorf("max 2: got %d errors, want 2", got)
+	}
+}
+
+func TestValidateLines(t *testing.T) {
+	data := []byte("{\"a\":1}\n{\"a\" 1}\n\n[1,\n 2 3\n[]")
+
+	var got []int64
+	for _, err := range ValidateLines(data, 0) {
+		got = append(got, err.Offset)
+	}
+	if want := []int64{13, 20, 24}; !slices.Equal(got, want) {
+		t.Errorf("got offsets %v, want %v", got, want)
+	}
+
+	if errs := ValidateLines([]byte("1\n\n\"a\"\n  \n[]\n"), 0); errs != nil {
+		t.Errorf("valid input: got %v", errs)
+	}
+}
+
+// Diagnostics for errors after the first describe the recovered structure.
+func TestDiagnoseRecovered(t *testing.T) {
+	data := []byte(`{"a": 1 "b": 2, "c": tru, "d": [1 2, 3]}`)
+
+	var paths []string
+	for _, err := range ValidateAll(data, 0) {
+		paths = append(paths, Diagnose(data, err).Path)
+	}
+	if want := []string{`$`, `$.c`, `$.d`}; !slices.Equal(paths, want) {
+		t.Errorf("got paths %q, want %q", paths, want)
+	}
+}
+
+// Errors in a stream of values are diagnosed within the value they were
+// found in.
+func TestDiagnoseStream(t *testing.T) {
+	data := []byte("{\"a\": 1}\n[2, {\"b\": [3,}\n")
+
+	d := NewDecoder(bytes.NewReader(data))
+	var err error
+	for err == nil {
+		_, err = d.Token()
+	}
+
+	serr, ok := err.(*SyntaxError)
+	if !ok {
+		t.Fatalf("got error %v, want *SyntaxError", err)
+	}
+	diag := Diagnose(data, serr)
+	if diag.Line != 2 || diag.Column != 14 || diag.Path != `$[1].b[1]` || !slices.Equal(diag.Expected, []string{"value"}) {
+		t.Errorf("got %d:%d %s %q, want 2:14 $[1].b[1] [\"value\"]", diag.Line, diag.Column, diag.Path, diag.Expected)
+	}
+}
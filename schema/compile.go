@@ -0,0 +1,475 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/erkl/jo"
+)
+
+// A schema is a compiled schema or subschema. Integer limits which aren't
+// set are -1.
+type schema struct {
+	// JSON Pointer to the schema within the schema document.
+	loc string
+
+	// Set for the schema false, which nothing satisfies.
+	never bool
+
+	ref *schema
+
+	allOf, anyOf, oneOf  []*schema
+	not, cond, then, els *schema
+	dependentSchemas     map[string]*schema
+
+	types typeSet
+
+	// Canonical forms of the values allowed by enum and const.
+	enum     []string
+	constant *string
+
+	minimum, maximum                   *float64
+	exclusiveMinimum, exclusiveMaximum *float64
+	multipleOf                         *big.Rat
+
+	minLength, maxLength int
+	pattern              *regexp.Regexp
+
+	prefixItems          []*schema
+	items, contains      *schema
+	minItems, maxItems   int
+	minContains          int
+	maxContains          int
+	uniqueItems          bool
+	properties           map[string]*schema
+	patternProperties    []patternSchema
+	additionalProperties *schema
+	propertyNames        *schema
+	required             []string
+	dependentRequired    map[string][]string
+	minProperties        int
+	maxProperties        int
+}
+
+// A patternSchema applies to object members whose keys match a pattern.
+type patternSchema struct {
+	re *regexp.Regexp
+	s  *schema
+}
+
+// A typeSet is a set of JSON Schema types.
+type typeSet uint8
+
+const (
+	typeNull typeSet = 1 << iota
+	typeBoolean
+	typeObject
+	typeArray
+	typeNumber
+	typeInteger
+	typeString
+)
+
+var typeNames = []string{"null", "boolean", "object", "array", "number", "integer", "string"}
+
+// String lists the types in t.
+func (t typeSet) String() string {
+	var names []string
+	for i, name := range typeNames {
+		if t&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, " or ")
+}
+
+// A compiler compiles a schema document.
+type compiler struct {
+	doc *jo.Node
+
+	// Compiled schemas, by location, $anchor and $id.
+	schemas map[string]*schema
+	anchors map[string]*schema
+	ids     map[string]*schema
+
+	// References yet to be resolved.
+	refs []pendingRef
+
+	err error
+}
+
+// A pendingRef is a $ref keyword which hasn't been resolved yet.
+type pendingRef struct {
+	s   *schema
+	ref string
+}
+
+func compile(data []byte) (*schema, error) {
+	doc, err := jo.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &compiler{
+		doc:     doc,
+		schemas: make(map[string]*schema),
+		anchors: make(map[string]*schema),
+		ids:     make(map[string]*schema),
+	}
+	root := c.compile(doc, "")
+
+	// Resolving a reference may compile parts of the document which no
+	// keyword has reached so far, adding further references.
+	for i := 0; i < len(c.refs) && c.err == nil; i++ {
+		r := c.refs[i]
+		if r.s.ref = c.resolve(r.ref); r.s.ref == nil && c.err == nil {
+			c.fail(r.s.loc+"/$ref", "unresolvable reference %q", r.ref)
+		}
+	}
+
+	if c.err != nil {
+		return nil, c.err
+	}
+	return root, nil
+}
+
+// fail records a *SchemaError, unless an error has already been recorded.
+func (c *compiler) fail(loc string, format string, args ...any) {
+	if c.err == nil {
+		c.err = &SchemaError{fmt.Sprintf(format, args...), loc}
+	}
+}
+
+// compile compiles the schema n, found at loc.
+func (c *compiler) compile(n *jo.Node, loc string) *schema {
+	if s, ok := c.schemas[loc]; ok {
+		return s
+	}
+
+	s := &schema{
+		loc:           loc,
+		minLength:     -1,
+		maxLength:     -1,
+		minItems:      -1,
+		maxItems:      -1,
+		minContains:   -1,
+		maxContains:   -1,
+		minProperties: -1,
+		maxProperties: -1,
+	}
+	c.schemas[loc] = s
+
+	switch n.Kind {
+	case jo.BoolStart:
+		b, _ := n.Bool()
+		s.never = !b
+		return s
+	case jo.ObjectStart:
+	default:
+		c.fail(loc, "schema must be an object or a boolean")
+		return s
+	}
+
+	for i := range n.Children() {
+		kw := n.Index(i)
+		key := kw.Key()
+		kloc := loc + "/" + pointerToken(key)
+
+		switch key {
+		case "$id":
+			id := c.text(kw, kloc)
+			c.ids[strings.TrimSuffix(id, "#")] = s
+		case "$anchor", "$dynamicAnchor":
+			c.anchors[c.text(kw, kloc)] = s
+		case "$ref":
+			c.refs = append(c.refs, pendingRef{s, c.text(kw, kloc)})
+		case "$defs":
+			if kw.Kind != jo.ObjectStart {
+				c.fail(kloc, "$defs must be an object")
+				break
+			}
+			for j := range kw.Children() {
+				def := kw.Index(j)
+				c.compile(def, kloc+"/"+pointerToken(def.Key()))
+			}
+		case "$dynamicRef", "unevaluatedItems", "unevaluatedProperties":
+			c.fail(kloc, "unsupported keyword %s", key)
+
+		case "allOf":
+			s.allOf = c.subs(kw, kloc)
+		case "anyOf":
+			s.anyOf = c.subs(kw, kloc)
+		case "oneOf":
+			s.oneOf = c.subs(kw, kloc)
+		case "not":
+			s.not = c.compile(kw, kloc)
+		case "if":
+			s.cond = c.compile(kw, kloc)
+		case "then":
+			s.then = c.compile(kw, kloc)
+		case "else":
+			s.els = c.compile(kw, kloc)
+		case "dependentSchemas":
+			s.dependentSchemas = c.subMap(kw, kloc)
+
+		case "type":
+			s.types = c.types(kw, kloc)
+		case "enum":
+			if kw.Kind != jo.ArrayStart {
+				c.fail(kloc, "enum must be an array")
+				break
+			}
+			for _, v := range kw.Children() {
+				s.enum = append(s.enum, canonical(v.Raw))
+			}
+		case "const":
+			v := canonical(kw.Raw)
+			s.constant = &v
+
+		case "minimum":
+			s.minimum = c.number(kw, kloc)
+		case "maximum":
+			s.maximum = c.number(kw, kloc)
+		case "exclusiveMinimum":
+			s.exclusiveMinimum = c.number(kw, kloc)
+		case "exclusiveMaximum":
+			s.exclusiveMaximum = c.number(kw, kloc)
+		case "multipleOf":
+			if c.number(kw, kloc) != nil {
+				if r, ok := rat(kw.Raw); !ok || r.Sign() <= 0 {
+					c.fail(kloc, "multipleOf must be greater than 0")
+				} else {
+					s.multipleOf = r
+				}
+			}
+
+		case "minLength":
+			s.minLength = c.count(kw, kloc)
+		case "maxLength":
+			s.maxLength = c.count(kw, kloc)
+		case "pattern":
+			s.pattern = c.regexp(kw, kloc)
+
+		case "prefixItems":
+			s.prefixItems = c.subs(kw, kloc)
+		case "items":
+			s.items = c.compile(kw, kloc)
+		case "contains":
+			s.contains = c.compile(kw, kloc)
+		case "minItems":
+			s.minItems = c.count(kw, kloc)
+		case "maxItems":
+			s.maxItems = c.count(kw, kloc)
+		case "minContains":
+			s.minContains = c.count(kw, kloc)
+		case "maxContains":
+			s.maxContains = c.count(kw, kloc)
+		case "uniqueItems":
+			if b, err := kw.Bool(); err != nil {
+				c.fail(kloc, "uniqueItems must be a boolean")
+			} else {
+				s.uniqueItems = b
+			}
+
+		case "properties":
+			s.properties = c.subMap(kw, kloc)
+		case "patternProperties":
+			if kw.Kind != jo.ObjectStart {
+				c.fail(kloc, "patternProperties must be an object")
+				break
+			}
+			for j := range kw.Children() {
+				m := kw.Index(j)
+				mloc := kloc + "/" + pointerToken(m.Key())
+				re, err := regexp.Compile(m.Key())
+				if err != nil {
+					c.fail(mloc, "invalid pattern: %v", err)
+				}
+				s.patternProperties = append(s.patternProperties, patternSchema{re, c.compile(m, mloc)})
+			}
+		case "additionalProperties":
+			s.additionalProperties = c.compile(kw, kloc)
+		case "propertyNames":
+			s.propertyNames = c.compile(kw, kloc)
+		case "required":
+			s.required = c.names(kw, kloc)
+		case "dependentRequired":
+			if kw.Kind != jo.ObjectStart {
+				c.fail(kloc, "dependentRequired must be an object")
+				break
+			}
+			s.dependentRequired = make(map[string][]string)
+			for j := range kw.Children() {
+				m := kw.Index(j)
+				s.dependentRequired[m.Key()] = c.names(m, kloc+"/"+pointerToken(m.Key()))
+			}
+		case "minProperties":
+			s.minProperties = c.count(kw, kloc)
+		case "maxProperties":
+			s.maxProperties = c.count(kw, kloc)
+		}
+	}
+
+	if s.contains != nil && s.minContains < 0 {
+		s.minContains = 1
+	}
+
+	return s
+}
+
+// resolve returns the schema referred to by ref, or nil if there is none.
+func (c *compiler) resolve(ref string) *schema {
+	base, frag, _ := strings.Cut(ref, "#")
+
+	loc := ""
+	if base != "" {
+		s, ok := c.ids[base]
+		if !ok {
+			return nil
+		}
+		loc = s.loc
+	}
+
+	if frag != "" && frag[0] != '/' {
+		return c.anchors[frag]
+	}
+
+	frag, err := url.PathUnescape(frag)
+	if err != nil {
+		return nil
+	}
+
+	loc += frag
+	if s, ok := c.schemas[loc]; ok {
+		return s
+	}
+	if n := c.doc.Lookup(loc); n != nil {
+		return c.compile(n, loc)
+	}
+	return nil
+}
+
+// subs compiles a non-empty array of schemas.
+func (c *compiler) subs(n *jo.Node, loc string) []*schema {
+	if n.Kind != jo.ArrayStart || n.Len() == 0 {
+		c.fail(loc, "%s must be a non-empty array", keyword(loc))
+		return nil
+	}
+
+	subs := make([]*schema, n.Len())
+	for i := range subs {
+		subs[i] = c.compile(n.Index(i), fmt.Sprintf("%s/%d", loc, i))
+	}
+	return subs
+}
+
+// subMap compiles an object whose members are schemas.
+func (c *compiler) subMap(n *jo.Node, loc string) map[string]*schema {
+	if n.Kind != jo.ObjectStart {
+		c.fail(loc, "%s must be an object", keyword(loc))
+		return nil
+	}
+
+	subs := make(map[string]*schema, n.Len())
+	for i := range n.Children() {
+		m := n.Index(i)
+		subs[m.Key()] = c.compile(m, loc+"/"+pointerToken(m.Key()))
+	}
+	return subs
+}
+
+// text decodes a string.
+func (c *compiler) text(n *jo.Node, loc string) string {
+	s, err := n.Text()
+	if err != nil {
+		c.fail(loc, "%s must be a string", keyword(loc))
+	}
+	return s
+}
+
+// names decodes an array of strings.
+func (c *compiler) names(n *jo.Node, loc string) []string {
+	if n.Kind != jo.ArrayStart {
+		c.fail(loc, "%s must be an array of strings", keyword(loc))
+		return nil
+	}
+
+	list := make([]string, 0, n.Len())
+	for i := range n.Children() {
+		s, err := n.Index(i).Text()
+		if err != nil {
+			c.fail(loc, "%s must be an array of strings", keyword(loc))
+			return nil
+		}
+		list = append(list, s)
+	}
+	return list
+}
+
+// number decodes a number.
+func (c *compiler) number(n *jo.Node, loc string) *float64 {
+	f, err := n.Float64()
+	if err != nil {
+		c.fail(loc, "%s must be a number", keyword(loc))
+		return nil
+	}
+	return &f
+}
+
+// count decodes a non-negative integer.
+func (c *compiler) count(n *jo.Node, loc string) int {
+	f, err := n.Float64()
+	if err != nil || f < 0 || f != math.Trunc(f) {
+		c.fail(loc, "%s must be a non-negative integer", keyword(loc))
+		return -1
+	}
+	return int(min(f, math.MaxInt32))
+}
+
+// regexp compiles a pattern.
+func (c *compiler) regexp(n *jo.Node, loc string) *regexp.Regexp {
+	re, err := regexp.Compile(c.text(n, loc))
+	if err != nil {
+		c.fail(loc, "invalid pattern: %v", err)
+	}
+	return re
+}
+
+// types decodes the value of a type keyword.
+func (c *compiler) types(n *jo.Node, loc string) typeSet {
+	var names []*jo.Node
+	switch n.Kind {
+	case jo.StringStart:
+		names = []*jo.Node{n}
+	case jo.ArrayStart:
+		for i := range n.Children() {
+			names = append(names, n.Index(i))
+		}
+	}
+
+	var t typeSet
+	for _, name := range names {
+		s, _ := name.Text()
+		i := slices.Index(typeNames, s)
+		if i < 0 {
+			c.fail(loc, "unknown type %q", s)
+			return 0
+		}
+		t |= 1 << i
+	}
+
+	if t == 0 {
+		c.fail(loc, "type must be a string or a non-empty array of strings")
+	}
+	return t
+}
+
+// keyword returns the last reference token of a keyword's location.
+func keyword(loc string) string {
+	return loc[strings.LastIndexByte(loc, '/')+1:]
+}
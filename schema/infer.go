@@ -0,0 +1,444 @@
+package schema
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/erkl/jo"
+)
+
+// An Inferrer infers a JSON Schema from sample values, such as the documents
+// of a data feed or the lines of a newline-delimited JSON file.
+//
+// The inferred schema lists the types observed at each path, the range of
+// numbers, which object members were always present, and the possible values
+// of strings which only took on a few distinct values. Samples are folded
+// into a summary as they are read, so memory use depends on the variety of
+// the samples' structure rather than on their number or size: objects with
+// too many distinct keys are summarized as maps, and strings stop being
+// tracked once there are too many distinct ones.
+type Inferrer struct {
+	root    *shape
+	samples int
+
+	maxEnum, maxProperties int
+}
+
+// A shape summarizes the values observed at a path.
+type shape struct {
+	count int
+	types typeSet
+
+	// Numbers.
+	min, max   json.Number
+	minF, maxF float64
+
+	// Strings, and the distinct values seen, in order, while there are
+	// few enough of them.
+	strings  int
+	values   []string
+	overflow bool
+
+	// Objects, their members by key in order of appearance, and once
+	// there are too many distinct keys, all of their members.
+	objects int
+	props   map[string]*shape
+	keys    []string
+	extra   *shape
+
+	// Arrays, and all of their elements.
+	arrays int
+	items  *shape
+}
+
+// Strings longer than this aren't considered for enums.
+const maxEnumLen = 64
+
+// NewInferrer returns an Inferrer which has seen no samples.
+func NewInferrer() *Inferrer {
+	return &Inferrer{root: &shape{}, maxEnum: 10, maxProperties: 200}
+}
+
+// SetMaxEnum sets the largest number of distinct strings observed at a path
+// for which an enum is suggested. The default is 10, and 0 disables enums.
+func (inf *Inferrer) SetMaxEnum(n int) {
+	inf.maxEnum = n
+}
+
+// SetMaxProperties sets the largest number of distinct keys an object may
+// have before its members are no longer told apart, and it's described
+// using additionalProperties instead. The default is 200.
+func (inf *Inferrer) SetMaxProperties(n int) {
+	inf.maxProperties = n
+}
+
+// Add reads a sequence of whitespace-separated JSON values from r, adding
+// each one as a sample. Malformed input results in a *jo.SyntaxError, in
+// which case the values before the error have been added, along with what
+// was read of the malformed one.
+func (inf *Inferrer) Add(r io.Reader) error {
+	d := jo.NewDecoder(r)
+	d.UseNumber()
+
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		inf.samples++
+		if err := inf.observe(d, tok, inf.root); err != nil {
+			return err
+		}
+	}
+}
+
+// Samples returns the number of samples added so far.
+func (inf *Inferrer) Samples() int {
+	return inf.samples
+}
+
+// Write writes the inferred schema to w as a single line of JSON.
+func (inf *Inferrer) Write(w io.Writer) error {
+	jw := jo.NewWriter(w)
+	jw.SetEscapeHTML(false)
+
+	jw.BeginObject()
+	jw.Key("$schema")
+	jw.String("https://json-schema.org/draft/2020-12/schema")
+	inf.write(jw, inf.root)
+
+	return jw.Close()
+}
+
+// Infer infers a schema from the sequence of whitespace-separated JSON
+// values read from r, using the Inferrer's defaults.
+func Infer(r io.Reader) ([]byte, error) {
+	inf := NewInferrer()
+	if err := inf.Add(r); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := inf.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// observe reads the rest of the value starting with tok, adding it to s.
+func (inf *Inferrer) observe(d *jo.Decoder, tok json.Token, s *shape) error {
+	s.count++
+
+	switch tok := tok.(type) {
+	case nil:
+		s.types |= typeNull
+	case bool:
+		s.types |= typeBoolean
+	case json.Number:
+		s.number(tok)
+	case string:
+		inf.string(s, tok)
+
+	case json.Delim:
+		if tok == '[' {
+			s.types |= typeArray
+			s.arrays++
+			if s.items == nil {
+				s.items = &shape{}
+			}
+
+			for {
+				tok, err := d.Token()
+				if err != nil {
+					return err
+				}
+				if tok == json.Delim(']') {
+					return nil
+				}
+				if err := inf.observe(d, tok, s.items); err != nil {
+					return err
+				}
+			}
+		}
+
+		s.types |= typeObject
+		s.objects++
+
+		for {
+			tok, err := d.Token()
+			if err != nil {
+				return err
+			}
+			if tok == json.Delim('}') {
+				return nil
+			}
+
+			key := tok.(string)
+			if tok, err = d.Token(); err != nil {
+				return err
+			}
+			if err := inf.observe(d, tok, inf.member(s, key)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// member returns the shape of the object member with the given key.
+func (inf *Inferrer) member(s *shape, key string) *shape {
+	if s.extra != nil {
+		return s.extra
+	}
+
+	if p, ok := s.props[key]; ok {
+		return p
+	}
+
+	if len(s.keys) == inf.maxProperties {
+		inf.toMap(s)
+		return s.extra
+	}
+
+	if s.props == nil {
+		s.props = make(map[string]*shape)
+	}
+	p := &shape{}
+	s.props[key] = p
+	s.keys = append(s.keys, key)
+	return p
+}
+
+// toMap stops telling apart the members of the objects observed in s, which
+// have too many distinct keys.
+func (inf *Inferrer) toMap(s *shape) {
+	s.extra = &shape{}
+	for _, k := range s.keys {
+		inf.merge(s.extra, s.props[k])
+	}
+	s.props, s.keys = nil, nil
+}
+
+// number adds a number to s.
+func (s *shape) number(n json.Number) {
+	if isInteger([]byte(n)) {
+		s.types |= typeInteger
+	} else {
+		s.types |= typeNumber
+	}
+
+	f, _ := strconv.ParseFloat(string(n), 64)
+	s.numbers(n, f, n, f)
+}
+
+// numbers widens the range of numbers observed in s.
+func (s *shape) numbers(min json.Number, minF float64, max json.Number, maxF float64) {
+	if s.min == "" || compareNumbers(min, minF, s.min, s.minF) < 0 {
+		s.min, s.minF = min, minF
+	}
+	if s.max == "" || compareNumbers(max, maxF, s.max, s.maxF) > 0 {
+		s.max, s.maxF = max, maxF
+	}
+}
+
+// compareNumbers compares two numbers, given along with their float64
+// values. Numbers which are too large or too small to tell apart as float64
+// values are compared exactly.
+func compareNumbers(a json.Number, af float64, b json.Number, bf float64) int {
+	if af != bf || a == b || af != 0 && !math.IsInf(af, 0) {
+		return cmp.Compare(af, bf)
+	}
+
+	x, _, _ := big.ParseFloat(string(a), 10, 64, big.ToNearestEven)
+	y, _, _ := big.ParseFloat(string(b), 10, 64, big.ToNearestEven)
+	return x.Cmp(y)
+}
+
+// string adds a string to s.
+func (inf *Inferrer) string(s *shape, str string) {
+	s.types |= typeString
+	s.strings++
+	inf.value(s, str)
+}
+
+// value adds a string to the distinct values observed in s.
+func (inf *Inferrer) value(s *shape, str string) {
+	if s.overflow {
+		return
+	}
+
+	for _, v := range s.values {
+		if v == str {
+			return
+		}
+	}
+
+	if len(str) > maxEnumLen || len(s.values) == inf.maxEnum {
+		s.values, s.overflow = nil, true
+		return
+	}
+	s.values = append(s.values, str)
+}
+
+// merge adds the observations in src to dst.
+func (inf *Inferrer) merge(dst, src *shape) {
+	dst.count += src.count
+	dst.types |= src.types
+
+	if src.min != "" {
+		dst.numbers(src.min, src.minF, src.max, src.maxF)
+	}
+
+	dst.strings += src.strings
+	if src.overflow {
+		dst.values, dst.overflow = nil, true
+	}
+	for _, v := range src.values {
+		inf.value(dst, v)
+	}
+
+	dst.objects += src.objects
+	if src.extra != nil {
+		if dst.extra == nil {
+			inf.toMap(dst)
+		}
+		inf.merge(dst.extra, src.extra)
+	}
+	for _, k := range src.keys {
+		inf.merge(inf.member(dst, k), src.props[k])
+	}
+
+	dst.arrays += src.arrays
+	if src.items != nil {
+		if dst.items == nil {
+			dst.items = &shape{}
+		}
+		inf.merge(dst.items, src.items)
+	}
+}
+
+// write writes the keywords describing s, and closes the schema object.
+func (inf *Inferrer) write(w *jo.Writer, s *shape) {
+	types := s.types
+	if types&typeNumber != 0 {
+		types &^= typeInteger
+	}
+
+	// Only suggest an enum if some value was seen more than once. Unless
+	// only strings were seen, the enum goes in a branch of its own.
+	enum := s.values != nil && s.strings > len(s.values)
+	if enum && types != typeString {
+		w.Key("anyOf")
+		w.BeginArray()
+		w.BeginObject()
+		writeTypes(w, types&^typeString)
+		w.EndObject()
+		w.BeginObject()
+		writeTypes(w, typeString)
+		writeEnum(w, s.values)
+		w.EndObject()
+		w.EndArray()
+	} else {
+		writeTypes(w, types)
+	}
+
+	// Bounds too large for a float64 can't be checked, so are left out.
+	if s.min != "" && !math.IsInf(s.minF, 0) {
+		w.Key("minimum")
+		w.Number(string(s.min))
+	}
+	if s.max != "" && !math.IsInf(s.maxF, 0) {
+		w.Key("maximum")
+		w.Number(string(s.max))
+	}
+
+	if enum && types == typeString {
+		writeEnum(w, s.values)
+	}
+
+	if s.objects > 0 {
+		if s.keys != nil {
+			w.Key("properties")
+			w.BeginObject()
+			for _, k := range s.keys {
+				w.Key(k)
+				w.BeginObject()
+				inf.write(w, s.props[k])
+			}
+			w.EndObject()
+
+			var required []string
+			for _, k := range s.keys {
+				if s.props[k].count == s.objects {
+					required = append(required, k)
+				}
+			}
+			if required != nil {
+				w.Key("required")
+				w.BeginArray()
+				for _, k := range required {
+					w.String(k)
+				}
+				w.EndArray()
+			}
+		}
+
+		if s.extra != nil {
+			w.Key("additionalProperties")
+			w.BeginObject()
+			inf.write(w, s.extra)
+		}
+	}
+
+	if s.arrays > 0 && s.items.count > 0 {
+		w.Key("items")
+		w.BeginObject()
+		inf.write(w, s.items)
+	}
+
+	w.EndObject()
+}
+
+// writeTypes writes the "type" keyword for a set of types.
+func writeTypes(w *jo.Writer, types typeSet) {
+	var names []string
+	for i, name := range typeNames {
+		if types&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+
+	switch len(names) {
+	case 0:
+	case 1:
+		w.Key("type")
+		w.String(names[0])
+	default:
+		w.Key("type")
+		w.BeginArray()
+		for _, name := range names {
+			w.String(name)
+		}
+		w.EndArray()
+	}
+}
+
+// writeEnum writes the "enum" keyword for a list of strings.
+func writeEnum(w *jo.Writer, values []string) {
+	w.Key("enum")
+	w.BeginArray()
+	for _, v := range values {
+		w.String(v)
+	}
+	w.EndArray()
+}
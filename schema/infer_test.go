@@ -0,0 +1,198 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/erkl/jo"
+)
+
+func ExampleInfer() {
+	feed := `{"id": 1, "status": "open", "score": 0.5}
+{"id": 2, "status": "closed", "tags": ["x"]}
+{"id": 3, "status": "open", "score": null}`
+
+	s, err := Infer(strings.NewReader(feed))
+	if err != nil {
+		panic(err)
+	}
+
+	out, _ := jo.Indent(nil, s, "", "  ")
+	fmt.Println(string(out))
+	// Output:
+	// {
+	//   "$schema": "https://json-schema.org/draft/2020-12/schema",
+	//   "type": "object",
+	//   "properties": {
+	//     "id": {
+	//       "type": "integer",
+	//       "minimum": 1,
+	//       "maximum": 3
+	//     },
+	//     "status": {
+	//       "type": "string",
+	//       "enum": [
+	//         "open",
+	//         "closed"
+	//       ]
+	//     },
+	//     "score": {
+	//       "type": [
+	//         "null",
+	//         "number"
+	//       ],
+	//       "minimum": 0.5,
+	//       "maximum": 0.5
+	//     },
+	//     "tags": {
+	//       "type": "array",
+	//       "items": {
+	//         "type": "string"
+	//       }
+	//     }
+	//   },
+	//   "required": [
+	//     "id",
+	//     "status"
+	//   ]
+	// }
+}
+
+var inferTests = []struct {
+	in, schema string
+}{
+	{`1 2.5 -3`, `{"type":"number","minimum":-3,"maximum":2.5}`},
+	{`"a" "b" "a"`, `{"type":"string","enum":["a","b"]}`},
+	{`"a" "b" "c"`, `{"type":"string"}`},
+	{`"a" "b" "c" "d" "a"`, `{"type":"string"}`},
+	{`[] [[1], []]`, `{"type":"array","items":{"type":"array","items":{"type":"integer","minimum":1,"maximum":1}}}`},
+	{`{"a":1} {"b":true} {"a":2,"b":false}`, `{"type":"object","properties":{"a":{"type":"integer","minimum":1,"maximum":2},"b":{"type":"boolean"}}}`},
+	{`{"x":{"a":1,"b":2,"c":3}} {"x":{"d":"e"}}`, `{"type":"object","properties":{"x":{"type":"object","additionalProperties":{"type":["integer","string"],"minimum":1,"maximum":3}}},"required":["x"]}`},
+	{`null true`, `{"type":["null","boolean"]}`},
+	{`"a" "a" null "b"`, `{"anyOf":[{"type":"null"},{"type":"string","enum":["a","b"]}]}`},
+	{`{"a":"x"} {"a":"x"} {"a":null} {"a":1}`, `{"type":"object","properties":{"a":{"anyOf":[{"type":["null","integer"]},{"type":"string","enum":["x"]}],"minimum":1,"maximum":1}},"required":["a"]}`},
+	{`1 1e400`, `{"type":"integer","minimum":1}`},
+	{`-1e400 1e400 -2e400`, `{"type":"integer"}`},
+	{`1e-400 -1e-400 0 -2e-400`, `{"type":"number","minimum":-2e-400,"maximum":1e-400}`},
+}
+
+func TestInfer(t *testing.T) {
+	for _, test := range inferTests {
+		inf := NewInferrer()
+		inf.SetMaxEnum(2)
+		inf.SetMaxProperties(3)
+
+		if err := inf.Add(strings.NewReader(test.in)); err != nil {
+			t.Fatalf("Add(%#q): %v", test.in, err)
+		}
+
+		var b strings.Builder
+		inf.Write(&b)
+
+		want := `{"$schema":"https://json-schema.org/draft/2020-12/schema",` + test.schema[1:]
+		if b.String() != want {
+			t.Errorf("%#q:", test.in)
+			t.Errorf("  got  %s", b.String())
+			t.Errorf("  want %s", want)
+		}
+	}
+}
+
+// Every sample satisfies the schema inferred from it.
+func TestInferValidates(t *testing.T) {
+	samples := []string{
+		inferSample,
+		`{"a":[1,"x",{"b":null}],"c":-0.5e1}`,
+		`{"a":[],"d":{"e":"f"}}`,
+		`[{"k":1},{"k":"v","l":[true]}]`,
+	}
+
+	inf := NewInferrer()
+	for _, s := range samples {
+		if err := inf.Add(strings.NewReader(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if inf.Samples() != len(samples) {
+		t.Errorf("Samples: got %d, want %d", inf.Samples(), len(samples))
+	}
+
+	var b strings.Builder
+	inf.Write(&b)
+
+	s, err := Compile([]byte(b.String()))
+	if err != nil {
+		t.Fatalf("Compile: %v\n%s", err, b.String())
+	}
+	for _, sample := range samples {
+		if err := s.ValidateBytes([]byte(sample)); err != nil {
+			t.Errorf("%#.40q...: %v", sample, err)
+		}
+	}
+}
+
+// Enums for strings seen alongside other types don't reject the other types.
+func TestInferMixedEnum(t *testing.T) {
+	samples := []string{
+		`{"a":"x"}`, `{"a":"x"}`, `{"a":null}`, `{"a":1}`,
+		`{"a":[{"b":"y"}, {"b":"y"}, {"b":true}, {"b":{"c":2}}]}`,
+	}
+
+	out, err := Infer(strings.NewReader(strings.Join(samples, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Compile(out)
+	if err != nil {
+		t.Fatalf("Compile: %v\n%s", err, out)
+	}
+	for _, sample := range samples {
+		if err := s.ValidateBytes([]byte(sample)); err != nil {
+			t.Errorf("%#q: %v\n%s", sample, err, out)
+		}
+	}
+}
+
+// Schemas inferred from numbers beyond the range of a float64 compile, and
+// accept the numbers they were inferred from.
+func TestInferExtremeNumbers(t *testing.T) {
+	for _, samples := range [][]string{
+		{`1e400`, `-1e400`, `0`},
+		{`[1, 2, 1e400]`},
+		{`{"a": -1e400, "b": 1e-400}`, `{"a": 1, "b": -1e-400}`},
+		{`[1e-400, -1e-400, 4.9e-324, 1.7976931348623157e308]`},
+	} {
+		inf := NewInferrer()
+		for _, in := range samples {
+			if err := inf.Add(strings.NewReader(in)); err != nil {
+				t.Fatalf("Add(%#q): %v", in, err)
+			}
+		}
+
+		var b strings.Builder
+		inf.Write(&b)
+
+		s, err := Compile([]byte(b.String()))
+		if err != nil {
+			t.Errorf("%#q: Compile: %v\n%s", samples, err, b.String())
+			continue
+		}
+		for _, in := range samples {
+			if err := s.ValidateBytes([]byte(in)); err != nil {
+				t.Errorf("%#q: %v", in, err)
+			}
+		}
+	}
+}
+
+// A document with a variety of values and structures.
+const inferSample = `{
+	"users": [
+		{"id": 1, "name": "ada", "roles": ["admin", "dev"], "active": true},
+		{"id": 2, "name": "alan", "roles": [], "active": false, "manager": 1},
+		{"id": 3, "name": "grace", "roles": ["dev"], "active": true, "manager": null}
+	],
+	"meta": {"count": 3, "generated": "2024-01-01T00:00:00Z"}
+}`
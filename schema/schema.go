@@ -0,0 +1,125 @@
+// Package schema validates JSON documents against JSON Schema (draft
+// 2020-12) as they are read, without building a tree of the document.
+//
+// Schemas are compiled from the keywords of the core, applicator and
+// validation vocabularies:
+//
+//	$ref, $defs, $anchor, $id
+//	allOf, anyOf, oneOf, not, if, then, else, dependentSchemas
+//	properties, patternProperties, additionalProperties, propertyNames
+//	prefixItems, items, contains
+//	type, enum, const
+//	multipleOf, maximum, exclusiveMaximum, minimum, exclusiveMinimum
+//	maxLength, minLength, pattern
+//	maxItems, minItems, uniqueItems, maxContains, minContains
+//	maxProperties, minProperties, required, dependentRequired
+//
+// Other keywords, such as format and the annotation keywords, are ignored,
+// except for $dynamicRef, unevaluatedItems and unevaluatedProperties, which
+// result in an error since ignoring them would accept invalid documents.
+// References must either be fragments, as in "#/$defs/name" or "#name", or
+// name a schema in the same document by the exact value of its $id.
+//
+// Patterns use the syntax of package regexp rather than that of ECMA-262.
+// Numbers are compared as float64 values, except for multipleOf, which is
+// checked exactly.
+//
+// Document values are validated against every applicable subschema at once,
+// as the document's tokens go by. Only the values of keywords which have to
+// compare whole values, enum, const and uniqueItems, are buffered, and only
+// while being compared.
+package schema
+
+import (
+	"io"
+	"strings"
+
+	"github.com/erkl/jo"
+)
+
+// A Schema is a compiled JSON Schema.
+type Schema struct {
+	root *schema
+}
+
+// Compile compiles a JSON Schema document. Malformed JSON results in a
+// *jo.SyntaxError, and schemas which can't be compiled in a *SchemaError.
+func Compile(data []byte) (*Schema, error) {
+	root, err := compile(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{root}, nil
+}
+
+// Validate reads a single JSON value from r and validates it against s. It
+// stops reading as soon as the value is known to be invalid, returning a
+// *ValidationError. Malformed JSON results in a *jo.SyntaxError, and any
+// other errors are passed on from r.
+func (s *Schema) Validate(r io.Reader) error {
+	return s.validate(jo.NewTokenizer(r))
+}
+
+// ValidateBytes is like Validate, but validates the JSON value in data.
+func (s *Schema) ValidateBytes(data []byte) error {
+	return s.validate(jo.NewBytesTokenizer(data))
+}
+
+// A ValidationError describes a value which doesn't satisfy a schema.
+type ValidationError struct {
+	msg string
+
+	// InstanceLocation is a JSON Pointer to the offending value within
+	// the validated document.
+	InstanceLocation string
+
+	// KeywordLocation is a JSON Pointer to the keyword in the schema
+	// document which the value fails to satisfy.
+	KeywordLocation string
+
+	// Offset is the input offset of the offending value's first byte.
+	Offset int64
+}
+
+// Error returns a description of the validation error.
+func (e *ValidationError) Error() string {
+	loc := e.InstanceLocation
+	if loc == "" {
+		loc = "(root)"
+	}
+	return "schema: " + loc + ": " + e.msg + " (#" + e.KeywordLocation + ")"
+}
+
+// A SchemaError describes a schema which can't be compiled.
+type SchemaError struct {
+	msg string
+
+	// Location is a JSON Pointer to the offending part of the schema.
+	Location string
+}
+
+// Error returns a description of the schema error.
+func (e *SchemaError) Error() string {
+	return "schema: " + e.msg + " at #" + e.Location
+}
+
+// pointerToken escapes s for use as a JSON Pointer reference token.
+func pointerToken(s string) string {
+	if strings.IndexAny(s, "~/") < 0 {
+		return s
+	}
+	s = strings.ReplaceAll(s, "~", "~0")
+	return strings.ReplaceAll(s, "/", "~1")
+}
+
+// canonical returns the canonical form of the JSON value in raw, used to
+// compare values. Values without a canonical form are compared as written,
+// apart from whitespace.
+func canonical(raw []byte) string {
+	if c, err := jo.Canonicalize(raw); err == nil {
+		return string(c)
+	}
+
+	c, _ := jo.Compact(nil, raw)
+	return string(c)
+}
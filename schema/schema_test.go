@@ -0,0 +1,247 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/erkl/jo"
+)
+
+func ExampleSchema_Validate() {
+	s, err := Compile([]byte(`{
+		"type": "object",
+		"required": ["id", "tags"],
+		"properties": {
+			"id": {"type": "integer", "minimum": 1},
+			"tags": {"type": "array", "items": {"type": "string", "maxLength": 8}}
+		}
+	}`))
+	if err != nil {
+		panic(err)
+	}
+
+	err = s.Validate(strings.NewReader(`{"id": 7, "tags": ["json", "streaming"]}`))
+
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		fmt.Println(verr)
+		fmt.Println(verr.InstanceLocation, verr.KeywordLocation)
+	}
+	// Output:
+	// schema: /tags/1: string is 9 characters long, longer than 8 (#/properties/tags/items/maxLength)
+	// /tags/1 /properties/tags/items/maxLength
+}
+
+var validateTests = []struct {
+	schema, in string
+
+	// Instance and keyword locations of the expected error, if any.
+	ptr, kw string
+}{
+	{`true`, `{"a":[1]}`, ``, ``},
+	{`false`, `1`, ``, ``},
+	{`{}`, `null`, ``, ``},
+
+	// type
+	{`{"type":"string"}`, `"x"`, ``, ``},
+	{`{"type":"string"}`, `1`, ``, `/type`},
+	{`{"type":["null","boolean"]}`, `false`, ``, ``},
+	{`{"type":"integer"}`, `1.0`, ``, ``},
+	{`{"type":"integer"}`, `1e3`, ``, ``},
+	{`{"type":"integer"}`, `1.5`, ``, `/type`},
+	{`{"type":"number"}`, `-2`, ``, ``},
+	{`{"type":"object"}`, `[]`, ``, `/type`},
+
+	// enum and const
+	{`{"enum":["a",1,{"b":[null]}]}`, `1.0`, ``, ``},
+	{`{"enum":["a",1,{"b":[null]}]}`, `{ "b" : [ null ] }`, ``, ``},
+	{`{"enum":["a",1,{"b":[null]}]}`, `{"b":[]}`, ``, `/enum`},
+	{`{"const":{"x":1,"y":[true]}}`, `{"y":[true],"x":1}`, ``, ``},
+	{`{"const":{"x":1,"y":[true]}}`, `{"y":[false],"x":1}`, ``, `/const`},
+	{`{"items":{"const":"é"}}`, `["é","é"]`, ``, ``},
+
+	// numbers
+	{`{"minimum":1,"maximum":3}`, `3`, ``, ``},
+	{`{"minimum":1,"maximum":3}`, `0.5`, ``, `/minimum`},
+	{`{"minimum":1,"maximum":3}`, `3.5`, ``, `/maximum`},
+	{`{"exclusiveMinimum":1}`, `1`, ``, `/exclusiveMinimum`},
+	{`{"exclusiveMaximum":1}`, `0.99`, ``, ``},
+	{`{"multipleOf":0.1}`, `0.3`, ``, ``},
+	{`{"multipleOf":0.1}`, `0.35`, ``, `/multipleOf`},
+	{`{"multipleOf":3}`, `9e2`, ``, ``},
+	{`{"minimum":0}`, `"-1"`, ``, ``},
+
+	// strings
+	{`{"maxLength":2}`, `"é😀"`, ``, ``},
+	{`{"maxLength":2}`, `"abc"`, ``, `/maxLength`},
+	{`{"minLength":2}`, `"a"`, ``, `/minLength`},
+	{`{"pattern":"^[a-z]+$"}`, `"abc"`, ``, ``},
+	{`{"pattern":"^[a-z]+$"}`, `"ab1"`, ``, `/pattern`},
+
+	// arrays
+	{`{"prefixItems":[{"type":"string"}],"items":{"type":"integer"}}`, `["a",1,2]`, ``, ``},
+	{`{"prefixItems":[{"type":"string"}],"items":{"type":"integer"}}`, `["a",1,"b"]`, `/2`, `/items/type`},
+	{`{"prefixItems":[{"type":"string"}],"items":false}`, `["a",1]`, `/1`, `/items`},
+	{`{"minItems":2,"maxItems":3}`, `[1]`, ``, `/minItems`},
+	{`{"minItems":2,"maxItems":3}`, `[1,[2,3,4,5],3,4]`, ``, `/maxItems`},
+	{`{"contains":{"type":"string"}}`, `[1,"a"]`, ``, ``},
+	{`{"contains":{"type":"string"}}`, `[1,2]`, ``, `/contains`},
+	{`{"contains":{"type":"string"},"minContains":2}`, `[1,"a"]`, ``, `/minContains`},
+	{`{"contains":{"type":"string"},"maxContains":1}`, `["a","b"]`, ``, `/maxContains`},
+	{`{"contains":{"type":"string"},"minContains":0}`, `[]`, ``, ``},
+	{`{"uniqueItems":true}`, `[1,"1",[1],{"a":1}]`, ``, ``},
+	{`{"uniqueItems":true}`, `[{"a":1,"b":2},3,{"b":2,"a":1.0}]`, ``, `/uniqueItems`},
+	{`{"uniqueItems":true}`, `[[1,[2]],[1,[2]]]`, ``, `/uniqueItems`},
+
+	// objects
+	{`{"required":["a","b"]}`, `{"b":1,"a":2}`, ``, ``},
+	{`{"required":["a","b"]}`, `{"b":1}`, ``, `/required`},
+	{`{"properties":{"a":{"type":"string"}}}`, `{"a":1}`, `/a`, `/properties/a/type`},
+	{`{"properties":{"a/b~":{"type":"string"}}}`, `{"a/b~":1}`, `/a~1b~0`, `/properties/a~1b~0/type`},
+	{`{"patternProperties":{"^x-":{"type":"string"}},"additionalProperties":false}`, `{"x-a":"1"}`, ``, ``},
+	{`{"patternProperties":{"^x-":{"type":"string"}},"additionalProperties":false}`, `{"x-a":"1","b":2}`, `/b`, `/additionalProperties`},
+	{`{"propertyNames":{"maxLength":3}}`, `{"abc":1,"abcd":2}`, `/abcd`, `/propertyNames/maxLength`},
+	{`{"minProperties":1}`, `{}`, ``, `/minProperties`},
+	{`{"maxProperties":1}`, `{"a":{},"b":{}}`, ``, `/maxProperties`},
+	{`{"dependentRequired":{"a":["b"]}}`, `{"c":1}`, ``, ``},
+	{`{"dependentRequired":{"a":["b"]}}`, `{"a":1}`, ``, `/dependentRequired`},
+	{`{"dependentSchemas":{"a":{"required":["b"]}}}`, `{"a":1}`, ``, `/dependentSchemas/a/required`},
+	{`{"dependentSchemas":{"a":{"required":["b"]}}}`, `{"c":1}`, ``, ``},
+
+	// combinators
+	{`{"allOf":[{"type":"integer"},{"minimum":2}]}`, `1`, ``, `/allOf/1/minimum`},
+	{`{"anyOf":[{"type":"string"},{"minimum":2}]}`, `3`, ``, ``},
+	{`{"anyOf":[{"type":"string"},{"minimum":2}]}`, `1`, ``, `/anyOf`},
+	{`{"oneOf":[{"type":"integer"},{"minimum":2}]}`, `1.5`, ``, `/oneOf`},
+	{`{"oneOf":[{"type":"integer"},{"minimum":2}]}`, `1`, ``, ``},
+	{`{"oneOf":[{"type":"integer"},{"minimum":2}]}`, `3`, ``, `/oneOf`},
+	{`{"not":{"type":"array"}}`, `[]`, ``, `/not`},
+	{`{"if":{"minimum":10},"then":{"multipleOf":10},"else":{"maximum":5}}`, `20`, ``, ``},
+	{`{"if":{"minimum":10},"then":{"multipleOf":10},"else":{"maximum":5}}`, `21`, ``, `/then/multipleOf`},
+	{`{"if":{"minimum":10},"then":{"multipleOf":10},"else":{"maximum":5}}`, `7`, ``, `/else/maximum`},
+	{`{"items":{"anyOf":[{"required":["a"]},{"required":["b"]}]}}`, `[{"b":[{"a":1}]},{"c":1}]`, `/1`, `/items/anyOf`},
+
+	// references
+	{`{"$defs":{"pos":{"minimum":1}},"items":{"$ref":"#/$defs/pos"}}`, `[1,0]`, `/1`, `/$defs/pos/minimum`},
+	{`{"$defs":{"pos":{"$anchor":"p","minimum":1}},"$ref":"#p"}`, `0`, ``, `/$defs/pos/minimum`},
+	{`{"$id":"https://example.com/s","$defs":{"a b":{"type":"null"}},"$ref":"https://example.com/s#/$defs/a%20b"}`, `1`, ``, `/$defs/a b/type`},
+	{`{"type":"object","properties":{"kids":{"items":{"$ref":"#"}}}}`, `{"kids":[{"kids":[]},{"kids":[[]]}]}`, `/kids/1/kids/0`, `/type`},
+	{`{"definitions":{"n":{"type":"null"}},"$ref":"#/definitions/n"}`, `null`, ``, ``},
+}
+
+func TestValidate(t *testing.T) {
+	for _, test := range validateTests {
+		s, err := Compile([]byte(test.schema))
+		if err != nil {
+			t.Errorf("Compile(%#q): %v", test.schema, err)
+			continue
+		}
+
+		err = s.ValidateBytes([]byte(test.in))
+
+		var verr *ValidationError
+		if err != nil && !errors.As(err, &verr) {
+			t.Errorf("%#q on %#q: unexpected error %v", test.schema, test.in, err)
+			continue
+		}
+
+		var ptr, kw string
+		if verr != nil {
+			ptr, kw = verr.InstanceLocation, verr.KeywordLocation
+		}
+
+		wantErr := test.kw != "" || test.schema == "false"
+		if (verr != nil) != wantErr || ptr != test.ptr || kw != test.kw {
+			t.Errorf("%#q on %#q:", test.schema, test.in)
+			t.Errorf("  got  %v", err)
+			t.Errorf("  want error at %q, %q", test.ptr, test.kw)
+		}
+	}
+}
+
+// errReader fails the test if it's read from.
+type errReader struct {
+	t *testing.T
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	r.t.Error("read past the invalid value")
+	return 0, io.EOF
+}
+
+// Validation stops at the first invalid value, without reading further.
+func TestValidateEarly(t *testing.T) {
+	s, err := Compile([]byte(`{"items":{"properties":{"n":{"maximum":10}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := io.MultiReader(strings.NewReader(`[{"n": 1}, {"n": 11}, `), errReader{t})
+
+	var verr *ValidationError
+	if err := s.Validate(r); !errors.As(err, &verr) || verr.InstanceLocation != "/1/n" || verr.Offset != 17 {
+		t.Errorf("got %v, want an error at /1/n", err)
+	}
+}
+
+func TestValidateSyntax(t *testing.T) {
+	s, _ := Compile([]byte(`{}`))
+
+	var serr *jo.SyntaxError
+	if err := s.ValidateBytes([]byte(`[1, }`)); !errors.As(err, &serr) {
+		t.Errorf("got %v, want a syntax error", err)
+	}
+	if err := s.ValidateBytes([]byte(`1 2`)); !errors.As(err, &serr) {
+		t.Errorf("got %v, want a syntax error", err)
+	}
+}
+
+var compileErrorTests = []struct {
+	schema, loc string
+}{
+	{`1`, ``},
+	{`{"properties":{"a":"x"}}`, `/properties/a`},
+	{`{"type":"str"}`, `/type`},
+	{`{"minLength":-1}`, `/minLength`},
+	{`{"maxItems":1.5}`, `/maxItems`},
+	{`{"multipleOf":0}`, `/multipleOf`},
+	{`{"pattern":"("}`, `/pattern`},
+	{`{"allOf":[]}`, `/allOf`},
+	{`{"required":[1]}`, `/required`},
+	{`{"$ref":"#/$defs/missing"}`, `/$ref`},
+	{`{"$ref":"other.json"}`, `/$ref`},
+	{`{"items":{"unevaluatedProperties":false}}`, `/items/unevaluatedProperties`},
+	{`{"$dynamicRef":"#meta"}`, `/$dynamicRef`},
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, test := range compileErrorTests {
+		_, err := Compile([]byte(test.schema))
+
+		var serr *SchemaError
+		if !errors.As(err, &serr) || serr.Location != test.loc {
+			t.Errorf("Compile(%#q): got %v, want an error at %q", test.schema, err, test.loc)
+		}
+	}
+
+	var serr *jo.SyntaxError
+	if _, err := Compile([]byte(`{"type":}`)); !errors.As(err, &serr) {
+		t.Errorf("got %v, want a syntax error", err)
+	}
+}
+
+// A schema which refers to itself without making progress is caught.
+func TestValidateRecursion(t *testing.T) {
+	s, err := Compile([]byte(`{"allOf":[{"$ref":"#"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var verr *ValidationError
+	if err := s.ValidateBytes([]byte(`1`)); !errors.As(err, &verr) {
+		t.Errorf("got %v, want a validation error", err)
+	}
+}
@@ -0,0 +1,679 @@
+package schema
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/erkl/jo"
+)
+
+// Subschemas applying to the same value, such as those of $ref and allOf,
+// may be nested this deeply before validation is abandoned. Only schemas
+// which refer to themselves without any progress through the document get
+// this far.
+const maxNesting = 64
+
+// A validator validates a document, one token at a time.
+type validator struct {
+	// Values being validated, from the document's root inwards.
+	levels []level
+
+	// Decoded key of the object member last read, and the tasks for its
+	// value.
+	key  string
+	next []*task
+
+	// Recorders collecting the tokens of values which have to be compared
+	// as a whole.
+	recs []*recorder
+
+	// Errors which decide the outcome of validation go here.
+	top *sink
+}
+
+// A level is a value being validated.
+type level struct {
+	kind   jo.Event
+	offset int64
+
+	// Key or index of the value within its parent; index is -1 for
+	// object members.
+	key   string
+	index int
+
+	// Tasks validating the value.
+	tasks []*task
+
+	// Number of elements read so far, for arrays.
+	count int
+
+	// Number of recorders active when the value started.
+	mark int
+}
+
+// A task validates a value against a single schema.
+type task struct {
+	s    *schema
+	sink *sink
+
+	// Index of the value's level.
+	depth int
+
+	// Number of members or elements read so far.
+	count int
+
+	// Which of the required keys have been seen, and which of the keys
+	// with dependencies.
+	found   []bool
+	present map[string]bool
+
+	// Number of elements satisfying contains, and the verdict on the
+	// current element.
+	contains int
+	elem     *sink
+
+	// Records the value, for enum and const, or the current element, for
+	// uniqueItems, along with the elements seen so far.
+	rec, elemRec *recorder
+	seen         map[string]int
+
+	// Verdicts of subschemas which don't report errors directly.
+	anyOf, oneOf         []*sink
+	not, cond, then, els *sink
+	deps                 map[string]*sink
+}
+
+// A sink receives a task's errors. Only the first one is kept.
+type sink struct {
+	err *ValidationError
+}
+
+// A recorder reassembles a value from its tokens.
+type recorder struct {
+	buf []byte
+}
+
+func (s *Schema) validate(t *jo.Tokenizer) error {
+	v := &validator{top: &sink{}}
+
+	for {
+		tok, err := t.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch tok.Kind {
+		case jo.KeyStart:
+			v.member(tok)
+		case jo.ObjectEnd, jo.ArrayEnd:
+			v.record(tok)
+			v.close()
+		default:
+			v.value(s.root, tok)
+		}
+
+		if v.top.err != nil {
+			return v.top.err
+		}
+	}
+}
+
+// member prepares the tasks for the value of an object member.
+func (v *validator) member(tok jo.Token) {
+	v.key = jo.Unquote(tok.Raw)
+	v.record(tok)
+
+	v.next = v.next[:0]
+	for _, t := range v.levels[len(v.levels)-1].tasks {
+		v.next = t.member(v, v.key, tok, v.next)
+	}
+}
+
+// value starts validating a value.
+func (v *validator) value(root *schema, tok jo.Token) {
+	mark := len(v.recs)
+	key, index := "", -1
+
+	var seeds []*task
+	if len(v.levels) == 0 {
+		seeds = []*task{{s: root, sink: v.top}}
+	} else if p := &v.levels[len(v.levels)-1]; p.kind == jo.ArrayStart {
+		index = p.count
+		p.count++
+		for _, t := range p.tasks {
+			seeds = t.element(v, index, seeds)
+		}
+	} else {
+		seeds = append(seeds, v.next...)
+		key = v.key
+	}
+
+	v.open(seeds, tok, key, index, mark, true)
+}
+
+// open pushes a level for the value starting with tok, and starts the
+// tasks validating it. Unless record is false, tok is passed to the active
+// recorders. Scalar values are closed straight away.
+func (v *validator) open(seeds []*task, tok jo.Token, key string, index int, mark int, record bool) {
+	depth := len(v.levels)
+	v.levels = append(v.levels, level{
+		kind:   tok.Kind,
+		offset: tok.Offset,
+		key:    key,
+		index:  index,
+		mark:   mark,
+	})
+
+	var tasks []*task
+	for _, t := range seeds {
+		tasks = v.expand(t, tasks, depth, 0)
+	}
+	v.levels[depth].tasks = tasks
+
+	for _, t := range tasks {
+		t.start(v, tok)
+	}
+
+	if record {
+		v.record(tok)
+	}
+
+	if tok.Kind != jo.ObjectStart && tok.Kind != jo.ArrayStart {
+		v.close()
+	}
+}
+
+// close finishes the innermost value.
+func (v *validator) close() {
+	n := len(v.levels) - 1
+	lv := &v.levels[n]
+	v.recs = v.recs[:lv.mark]
+
+	// Tasks for subschemas come after the tasks which depend on them.
+	for i := len(lv.tasks) - 1; i >= 0; i-- {
+		lv.tasks[i].finish(v)
+	}
+
+	v.levels = v.levels[:n]
+
+	if n > 0 && v.levels[n-1].kind == jo.ArrayStart {
+		for _, t := range v.levels[n-1].tasks {
+			t.elementDone(v)
+		}
+	}
+}
+
+// expand appends t to tasks, along with the tasks for all subschemas which
+// apply to the same value.
+func (v *validator) expand(t *task, tasks []*task, depth, nesting int) []*task {
+	t.depth = depth
+	tasks = append(tasks, t)
+
+	s := t.s
+	if nesting == maxNesting {
+		v.fail(t, s, "", "subschemas nested too deeply")
+		return tasks
+	}
+
+	if s.ref != nil {
+		tasks = v.expand(&task{s: s.ref, sink: t.sink}, tasks, depth, nesting+1)
+	}
+	for _, sub := range s.allOf {
+		tasks = v.expand(&task{s: sub, sink: t.sink}, tasks, depth, nesting+1)
+	}
+
+	// The rest only contribute a verdict.
+	verdict := func(sub *schema) *sink {
+		k := &sink{}
+		tasks = v.expand(&task{s: sub, sink: k}, tasks, depth, nesting+1)
+		return k
+	}
+
+	for _, sub := range s.anyOf {
+		t.anyOf = append(t.anyOf, verdict(sub))
+	}
+	for _, sub := range s.oneOf {
+		t.oneOf = append(t.oneOf, verdict(sub))
+	}
+	if s.not != nil {
+		t.not = verdict(s.not)
+	}
+	if s.cond != nil {
+		t.cond = verdict(s.cond)
+		if s.then != nil {
+			t.then = verdict(s.then)
+		}
+		if s.els != nil {
+			t.els = verdict(s.els)
+		}
+	}
+	for key, sub := range s.dependentSchemas {
+		if t.deps == nil {
+			t.deps = make(map[string]*sink)
+		}
+		t.deps[key] = verdict(sub)
+	}
+
+	return tasks
+}
+
+// record passes tok to the active recorders.
+func (v *validator) record(tok jo.Token) {
+	for _, r := range v.recs {
+		r.token(tok)
+	}
+}
+
+// recorder starts recording the current value.
+func (v *validator) recorder() *recorder {
+	r := &recorder{}
+	v.recs = append(v.recs, r)
+	return r
+}
+
+// fail reports that the value validated by t doesn't satisfy the keyword kw
+// of the schema s, or the schema as a whole if kw is empty.
+func (v *validator) fail(t *task, s *schema, kw string, format string, args ...any) {
+	if t.sink.err != nil {
+		return
+	}
+
+	loc := s.loc
+	if kw != "" {
+		loc += "/" + kw
+	}
+
+	var ptr strings.Builder
+	for _, lv := range v.levels[1 : t.depth+1] {
+		ptr.WriteByte('/')
+		if lv.index >= 0 {
+			ptr.WriteString(strconv.Itoa(lv.index))
+		} else {
+			ptr.WriteString(pointerToken(lv.key))
+		}
+	}
+
+	t.sink.err = &ValidationError{
+		msg:              fmt.Sprintf(format, args...),
+		InstanceLocation: ptr.String(),
+		KeywordLocation:  loc,
+		Offset:           v.levels[t.depth].offset,
+	}
+}
+
+// forward passes on the error in k, if any, as t's own.
+func (v *validator) forward(t *task, k *sink) {
+	if t.sink.err == nil && k.err != nil {
+		t.sink.err = k.err
+	}
+}
+
+// start checks the first token of the value.
+func (t *task) start(v *validator, tok jo.Token) {
+	s := t.s
+	if s.never {
+		v.fail(t, s, "", "no value is allowed here")
+		return
+	}
+
+	if s.types != 0 && !s.types.allows(tok) {
+		v.fail(t, s, "type", "expected %s, found %s", s.types, typeOf(tok))
+	}
+
+	if s.enum != nil || s.constant != nil {
+		if tok.Kind == jo.ObjectStart || tok.Kind == jo.ArrayStart {
+			t.rec = v.recorder()
+		} else {
+			t.compare(v, canonical(tok.Raw))
+		}
+	}
+
+	switch tok.Kind {
+	case jo.StringStart:
+		t.checkString(v, jo.Unquote(tok.Raw))
+	case jo.NumberStart:
+		t.checkNumber(v, tok.Raw)
+	case jo.ObjectStart:
+		if s.required != nil {
+			t.found = make([]bool, len(s.required))
+		}
+		if s.dependentRequired != nil || s.dependentSchemas != nil {
+			t.present = make(map[string]bool)
+		}
+	case jo.ArrayStart:
+		if s.uniqueItems {
+			t.seen = make(map[string]int)
+		}
+	}
+}
+
+// member returns tasks, with the tasks for the value of the object member
+// with the given key appended.
+func (t *task) member(v *validator, key string, tok jo.Token, tasks []*task) []*task {
+	s := t.s
+	t.count++
+
+	for i, name := range s.required {
+		if name == key {
+			t.found[i] = true
+		}
+	}
+	if t.present != nil {
+		t.present[key] = true
+	}
+
+	if s.propertyNames != nil {
+		tok := jo.Token{Kind: jo.StringStart, Raw: tok.Raw, Offset: tok.Offset}
+		v.open([]*task{{s: s.propertyNames, sink: t.sink}}, tok, key, -1, len(v.recs), false)
+	}
+
+	matched := false
+	if sub, ok := s.properties[key]; ok {
+		tasks = append(tasks, &task{s: sub, sink: t.sink})
+		matched = true
+	}
+	for _, p := range s.patternProperties {
+		if p.re.MatchString(key) {
+			tasks = append(tasks, &task{s: p.s, sink: t.sink})
+			matched = true
+		}
+	}
+	if !matched && s.additionalProperties != nil {
+		tasks = append(tasks, &task{s: s.additionalProperties, sink: t.sink})
+	}
+
+	return tasks
+}
+
+// element returns tasks, with the tasks for the i-th element of the array
+// appended.
+func (t *task) element(v *validator, i int, tasks []*task) []*task {
+	s := t.s
+	t.count++
+
+	if i < len(s.prefixItems) {
+		tasks = append(tasks, &task{s: s.prefixItems[i], sink: t.sink})
+	} else if s.items != nil {
+		tasks = append(tasks, &task{s: s.items, sink: t.sink})
+	}
+
+	if s.contains != nil {
+		t.elem = &sink{}
+		tasks = append(tasks, &task{s: s.contains, sink: t.elem})
+	}
+	if t.seen != nil {
+		t.elemRec = v.recorder()
+	}
+
+	return tasks
+}
+
+// elementDone takes note of the outcome of an array element.
+func (t *task) elementDone(v *validator) {
+	if t.elem != nil && t.elem.err == nil {
+		t.contains++
+	}
+
+	if t.seen != nil {
+		c := canonical(t.elemRec.buf)
+		if j, ok := t.seen[c]; ok {
+			v.fail(t, t.s, "uniqueItems", "elements %d and %d are equal", j, t.count-1)
+		}
+		t.seen[c] = t.count - 1
+	}
+}
+
+// finish checks the value once it has been read in full.
+func (t *task) finish(v *validator) {
+	s := t.s
+
+	if t.rec != nil {
+		t.compare(v, canonical(t.rec.buf))
+	}
+
+	switch v.levels[t.depth].kind {
+	case jo.ObjectStart:
+		t.finishObject(v)
+	case jo.ArrayStart:
+		t.finishArray(v)
+	}
+
+	if t.anyOf != nil && !slices.ContainsFunc(t.anyOf, func(k *sink) bool { return k.err == nil }) {
+		v.fail(t, s, "anyOf", "value matches none of the schemas")
+	}
+	if t.oneOf != nil {
+		var matched []int
+		for i, k := range t.oneOf {
+			if k.err == nil {
+				matched = append(matched, i)
+			}
+		}
+		switch len(matched) {
+		case 0:
+			v.fail(t, s, "oneOf", "value matches none of the schemas")
+		case 1:
+		default:
+			v.fail(t, s, "oneOf", "value matches schemas %d and %d, but must match exactly one", matched[0], matched[1])
+		}
+	}
+	if t.not != nil && t.not.err == nil {
+		v.fail(t, s, "not", "value matches a schema it must not match")
+	}
+	if t.cond != nil {
+		if t.cond.err == nil && t.then != nil {
+			v.forward(t, t.then)
+		} else if t.cond.err != nil && t.els != nil {
+			v.forward(t, t.els)
+		}
+	}
+}
+
+func (t *task) finishObject(v *validator) {
+	s := t.s
+
+	for i, ok := range t.found {
+		if !ok {
+			v.fail(t, s, "required", "missing required property %q", s.required[i])
+		}
+	}
+
+	for key, deps := range s.dependentRequired {
+		if !t.present[key] {
+			continue
+		}
+		for _, dep := range deps {
+			if !t.present[dep] {
+				v.fail(t, s, "dependentRequired", "property %q requires property %q", key, dep)
+			}
+		}
+	}
+	for key, k := range t.deps {
+		if t.present[key] {
+			v.forward(t, k)
+		}
+	}
+
+	if s.minProperties >= 0 && t.count < s.minProperties {
+		v.fail(t, s, "minProperties", "object has %d properties, fewer than %d", t.count, s.minProperties)
+	}
+	if s.maxProperties >= 0 && t.count > s.maxProperties {
+		v.fail(t, s, "maxProperties", "object has %d properties, more than %d", t.count, s.maxProperties)
+	}
+}
+
+func (t *task) finishArray(v *validator) {
+	s := t.s
+
+	if s.minItems >= 0 && t.count < s.minItems {
+		v.fail(t, s, "minItems", "array has %d elements, fewer than %d", t.count, s.minItems)
+	}
+	if s.maxItems >= 0 && t.count > s.maxItems {
+		v.fail(t, s, "maxItems", "array has %d elements, more than %d", t.count, s.maxItems)
+	}
+
+	if s.contains != nil {
+		if t.contains < s.minContains {
+			kw := "minContains"
+			if t.contains == 0 {
+				kw = "contains"
+			}
+			v.fail(t, s, kw, "array has %d matching elements, fewer than %d", t.contains, s.minContains)
+		}
+		if s.maxContains >= 0 && t.contains > s.maxContains {
+			v.fail(t, s, "maxContains", "array has %d matching elements, more than %d", t.contains, s.maxContains)
+		}
+	}
+}
+
+// compare checks a value's canonical form against enum and const.
+func (t *task) compare(v *validator, c string) {
+	s := t.s
+
+	if s.constant != nil && c != *s.constant {
+		v.fail(t, s, "const", "value must be %s", *s.constant)
+	}
+	if s.enum != nil && !slices.Contains(s.enum, c) {
+		v.fail(t, s, "enum", "value must be one of %s", strings.Join(s.enum, ", "))
+	}
+}
+
+func (t *task) checkString(v *validator, str string) {
+	s := t.s
+
+	if s.minLength >= 0 || s.maxLength >= 0 {
+		n := utf8.RuneCountInString(str)
+		if s.minLength >= 0 && n < s.minLength {
+			v.fail(t, s, "minLength", "string is %d characters long, shorter than %d", n, s.minLength)
+		}
+		if s.maxLength >= 0 && n > s.maxLength {
+			v.fail(t, s, "maxLength", "string is %d characters long, longer than %d", n, s.maxLength)
+		}
+	}
+
+	if s.pattern != nil && !s.pattern.MatchString(str) {
+		v.fail(t, s, "pattern", "string doesn't match pattern %q", s.pattern)
+	}
+}
+
+func (t *task) checkNumber(v *validator, raw []byte) {
+	s := t.s
+
+	if s.minimum == nil && s.maximum == nil && s.exclusiveMinimum == nil && s.exclusiveMaximum == nil && s.multipleOf == nil {
+		return
+	}
+
+	f, _ := strconv.ParseFloat(string(raw), 64)
+
+	if s.minimum != nil && f < *s.minimum {
+		v.fail(t, s, "minimum", "%s is less than %v", raw, *s.minimum)
+	}
+	if s.maximum != nil && f > *s.maximum {
+		v.fail(t, s, "maximum", "%s is greater than %v", raw, *s.maximum)
+	}
+	if s.exclusiveMinimum != nil && f <= *s.exclusiveMinimum {
+		v.fail(t, s, "exclusiveMinimum", "%s is not greater than %v", raw, *s.exclusiveMinimum)
+	}
+	if s.exclusiveMaximum != nil && f >= *s.exclusiveMaximum {
+		v.fail(t, s, "exclusiveMaximum", "%s is not less than %v", raw, *s.exclusiveMaximum)
+	}
+
+	if s.multipleOf != nil {
+		r, ok := rat(raw)
+		if ok {
+			ok = r.Quo(r, s.multipleOf).IsInt()
+		}
+		if !ok {
+			v.fail(t, s, "multipleOf", "%s is not a multiple of %s", raw, s.multipleOf.RatString())
+		}
+	}
+}
+
+// allows reports whether a value starting with tok has one of the types.
+func (ts typeSet) allows(tok jo.Token) bool {
+	switch tok.Kind {
+	case jo.NullStart:
+		return ts&typeNull != 0
+	case jo.BoolStart:
+		return ts&typeBoolean != 0
+	case jo.ObjectStart:
+		return ts&typeObject != 0
+	case jo.ArrayStart:
+		return ts&typeArray != 0
+	case jo.StringStart:
+		return ts&typeString != 0
+	}
+
+	if ts&typeNumber != 0 {
+		return true
+	}
+	return ts&typeInteger != 0 && isInteger(tok.Raw)
+}
+
+// typeOf returns the type of a value starting with tok.
+func typeOf(tok jo.Token) string {
+	switch tok.Kind {
+	case jo.NullStart:
+		return "null"
+	case jo.BoolStart:
+		return "boolean"
+	case jo.ObjectStart:
+		return "object"
+	case jo.ArrayStart:
+		return "array"
+	case jo.StringStart:
+		return "string"
+	}
+	if isInteger(tok.Raw) {
+		return "integer"
+	}
+	return "number"
+}
+
+// isInteger reports whether the number raw has no fractional part.
+func isInteger(raw []byte) bool {
+	if !strings.ContainsAny(string(raw), ".eE") {
+		return true
+	}
+	if r, ok := rat(raw); ok {
+		return r.IsInt()
+	}
+	f, err := strconv.ParseFloat(string(raw), 64)
+	return err == nil && f == math.Trunc(f)
+}
+
+// rat converts the number raw to a big.Rat. Numbers with very large
+// exponents, whose exact values would be costly to work with, are refused.
+func rat(raw []byte) (*big.Rat, bool) {
+	s := string(raw)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		if exp, err := strconv.Atoi(strings.TrimPrefix(s[i+1:], "+")); err != nil || exp < -1000 || exp > 1000 {
+			return nil, false
+		}
+	}
+	return new(big.Rat).SetString(s)
+}
+
+// token appends tok to the value being reassembled.
+func (r *recorder) token(tok jo.Token) {
+	switch tok.Kind {
+	case jo.ObjectEnd, jo.ArrayEnd:
+	default:
+		if n := len(r.buf); n > 0 && r.buf[n-1] != '[' && r.buf[n-1] != '{' && r.buf[n-1] != ':' {
+			r.buf = append(r.buf, ',')
+		}
+	}
+
+	r.buf = append(r.buf, tok.Raw...)
+	if tok.Kind == jo.KeyStart {
+		r.buf = append(r.buf, ':')
+	}
+}
@@ -0,0 +1,281 @@
+package jo
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Size of the chunks ValidateParallel splits its input into.
+var speculativeChunkSize = 1 << 20
+
+// ValidateParallel is like Validate, but spreads the work across up to
+// workers goroutines, or GOMAXPROCS if workers is zero or less. It accepts
+// and rejects exactly the same inputs as Validate, and reports the same
+// errors at the same offsets.
+//
+// The input is split into chunks, each of which is first summarized in
+// parallel under two assumptions: that it begins outside a string, and that
+// it begins inside one. Stitching the summaries together in order reveals
+// which assumption holds for each chunk, and the objects and arrays open at
+// the first comma in it. Scanning resumes at each such comma, the Scanner
+// rebuilt from the open containers, so the pieces in between can be scanned
+// in parallel.
+//
+// Should the input be malformed, the pieces following the first error may
+// be scanned in the wrong state, but since their errors would all come
+// later in the input, the result is unaffected.
+func ValidateParallel(data []byte, workers int) error {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	chunks := splitChunks(data)
+	if len(chunks) < 2 || workers == 1 {
+		return Validate(data)
+	}
+
+	// Summarize the chunks under both assumptions.
+	sums := make([][2]chunkSummary, len(chunks))
+	parallelDo(len(chunks), workers, func(i int) bool {
+		c := data[chunks[i]:]
+		if i+1 < len(chunks) {
+			c = data[chunks[i]:chunks[i+1]]
+		}
+		sums[i][0] = summarize(c, false)
+		sums[i][1] = summarize(c, true)
+		return true
+	})
+
+	segs := stitch(chunks, sums)
+
+	// Scan the segments, keeping the error earliest in the input.
+	errs := make([]*SyntaxError, len(segs))
+	var failed atomic.Int64
+	failed.Store(int64(len(segs)))
+
+	parallelDo(len(segs), workers, func(i int) bool {
+		if int64(i) > failed.Load() {
+			return false
+		}
+
+		end := len(data)
+		if i+1 < len(segs) {
+			end = segs[i+1].start
+		}
+
+		if errs[i] = segs[i].scan(data, end, i+1 == len(segs)); errs[i] != nil {
+			for {
+				f := failed.Load()
+				if int64(i) >= f || failed.CompareAndSwap(f, int64(i)) {
+					break
+				}
+			}
+		}
+		return true
+	})
+
+	if f := failed.Load(); f < int64(len(segs)) {
+		return errs[f]
+	}
+	return nil
+}
+
+// splitChunks returns the offsets at which the input is split into chunks.
+// No chunk begins right after a backslash, so none begins in the middle of
+// an escape sequence.
+func splitChunks(data []byte) []int {
+	chunks := []int{0}
+
+	for b := speculativeChunkSize; b < len(data); b += speculativeChunkSize {
+		for b < len(data) && data[b-1] == '\\' {
+			b++
+		}
+		if b < len(data) && b > chunks[len(chunks)-1] {
+			chunks = append(chunks, b)
+		}
+	}
+
+	return chunks
+}
+
+// A chunkSummary describes a chunk under one assumption about whether it
+// begins inside a string.
+type chunkSummary struct {
+	// Whether the chunk ends inside a string.
+	inString bool
+
+	// Index of the first comma outside strings, or -1 if there is none,
+	// and the effect on the open containers of the bytes up to it and
+	// of those following it. Without a comma, before covers the whole
+	// chunk.
+	comma         int
+	before, after bracketEffect
+}
+
+// A bracketEffect describes the brackets in a stretch of input, once those
+// matching each other have been canceled out: a number of containers closed,
+// followed by containers opened, each recorded as '[' or '{'.
+type bracketEffect struct {
+	pops int
+	push []byte
+}
+
+// summarize summarizes a chunk, assuming it begins inside a string or not.
+func summarize(c []byte, inString bool) chunkSummary {
+	sum := chunkSummary{comma: -1}
+	eff := &sum.before
+
+	for i := 0; i < len(c); i++ {
+		b := c[i]
+
+		if inString {
+			if b == '\\' {
+				i++
+			} else if b == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch b {
+		case '"':
+			inString = true
+		case '[', '{':
+			eff.push = append(eff.push, b)
+		case ']', '}':
+			if n := len(eff.push); n > 0 {
+				eff.push = eff.push[:n-1]
+			} else {
+				eff.pops++
+			}
+		case ',':
+			if sum.comma < 0 {
+				sum.comma = i
+				eff = &sum.after
+			}
+		}
+	}
+
+	// An escape cut short by the end of the chunk can't happen, since no
+	// chunk begins right after a backslash.
+	sum.inString = inString
+	return sum
+}
+
+// A segment is a stretch of input which can be scanned independently, given
+// the containers open at its start.
+type segment struct {
+	start int
+
+	// Open containers, outermost first, or nil for the first segment.
+	open []byte
+}
+
+// stitch works out which assumption holds for each chunk, and returns the
+// segments beginning after the first comma in each chunk.
+func stitch(chunks []int, sums [][2]chunkSummary) []segment {
+	segs := []segment{{start: 0}}
+
+	var open []byte
+	inString, broken := false, false
+
+	apply := func(eff bracketEffect) {
+		if eff.pops > len(open) {
+			broken = true
+			return
+		}
+		open = append(open[:len(open)-eff.pops], eff.push...)
+	}
+
+	for i, start := range chunks {
+		sum := &sums[i][0]
+		if inString {
+			sum = &sums[i][1]
+		}
+		inString = sum.inString
+
+		apply(sum.before)
+		if sum.comma < 0 {
+			continue
+		}
+
+		// A comma which isn't inside an object or array is an error, as
+		// is anything following a mismatched bracket; both are left for
+		// the Scanner of the current segment to report.
+		if !broken && len(open) > 0 {
+			segs = append(segs, segment{start + sum.comma + 1, append([]byte(nil), open...)})
+		}
+		apply(sum.after)
+
+		if broken {
+			break
+		}
+	}
+
+	return segs
+}
+
+// scan scans the segment up to data[end], reporting the end of input if
+// last is set.
+func (seg *segment) scan(data []byte, end int, last bool) *SyntaxError {
+	s := NewScanner()
+	if seg.open != nil {
+		s.resumeAfterComma(seg.open)
+	}
+
+	for i := seg.start; i < end; i++ {
+		if s.Scan(data[i]) == Error {
+			return s.syntaxError(int64(i))
+		}
+	}
+	if last && s.End() == Error {
+		return s.syntaxError(int64(len(data)))
+	}
+
+	return nil
+}
+
+// resumeAfterComma puts s in the state it would be in after a comma
+// separating the members or elements of the innermost of the given open
+// containers.
+func (s *Scanner) resumeAfterComma(open []byte) {
+	s.Reset()
+
+	for _, c := range open[:len(open)-1] {
+		if c == '[' {
+			s.push(afterArrayElement)
+		} else {
+			s.push(afterObjectValue)
+		}
+	}
+
+	if open[len(open)-1] == '[' {
+		s.state = afterArrayComma
+	} else {
+		s.state = afterObjectComma
+	}
+}
+
+// parallelDo calls fn for each index below n, using up to workers
+// goroutines. Indices are handed out in order, and each worker stops once
+// fn returns false.
+func parallelDo(n, workers int, fn func(i int) bool) {
+	var next atomic.Int64
+	var wg sync.WaitGroup
+
+	for w := 0; w < min(workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= n || !fn(i) {
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,189 @@
+package jo
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+func ExampleValidateParallel() {
+	data := []byte(`{"users": [{"name": "Tom", "tags": ["a", "b"]}, {"name": "Tim", "tags": ["c",]}]}`)
+
+	fmt.Println(ValidateParallel(data, 4))
+	// Output:
+	// invalid character ']': expected value after ','; trailing commas are not allowed
+}
+
+var speculativeTests = []string{
+	``,
+	` `,
+	`1`,
+	`[1, 2, 3]`,
+	`{"a": [1, {"b": "x,y"}, "]\"[", "\\", "\\\""], "c": {"d": null}}`,
+	`["A\\\\", "a\\\"b,", [[[[]]]], {}, [{}, {"x": []}]]`,
+	`[1, 2, 3`,
+	`[1, 2, 3]]`,
+	`[1, 2}, 3]`,
+	`{"a": 1, "b" 2}`,
+	`[1, , 2]`,
+	`[1, 2], [3]`,
+	`["abc", "de`,
+	`["\x", 1]`,
+	`{"a": [1, 2, 3], "b": {"c": [4, 5, {"d": 6}]}, "e": "f"} x`,
+	`[` + strings.Repeat(`"\\\\\\",`, 20) + `1]`,
+	strings.Repeat(`[`, 50) + strings.Repeat(`1, `, 10) + `1` + strings.Repeat(`]`, 50),
+	strings.Repeat(`{"a": [`, 30) + `1, 2` + strings.Repeat(`]}`, 30),
+}
+
+// checkParallel compares the results of Validate and ValidateParallel on
+// data, with the given chunk sizes and numbers of workers.
+func checkParallel(t *testing.T, data []byte, sizes, workers []int) {
+	t.Helper()
+	want := fmt.Sprint(Validate(data))
+	if err := Validate(data); err != nil {
+		want += fmt.Sprintf(" at %d", err.(*SyntaxError).Offset)
+	}
+
+	for _, size := range sizes {
+		speculativeChunkSize = size
+
+		for _, w := range workers {
+			err := ValidateParallel(data, w)
+			got := fmt.Sprint(err)
+			if serr, ok := err.(*SyntaxError); ok {
+				got += fmt.Sprintf(" at %d", serr.Offset)
+			}
+
+			if got != want {
+				t.Errorf("%#q (chunks of %d, %d workers):", data, size, w)
+				t.Errorf("  got  %s", got)
+				t.Errorf("  want %s", want)
+			}
+		}
+	}
+}
+
+func TestValidateParallel(t *testing.T) {
+	defer func(size int) { speculativeChunkSize = size }(speculativeChunkSize)
+
+	for _, in := range speculativeTests {
+		checkParallel(t, []byte(in), []int{1, 2, 3, 4, 5, 7, 16, 64}, []int{2, 3, 8})
+	}
+}
+
+// randomValue appends a random JSON value to buf, favoring the kinds of
+// values which make splitting the input difficult: strings containing
+// quotes, backslashes, brackets and commas, and deeply nested containers.
+func randomValue(rnd *rand.Rand, buf []byte, depth int) []byte {
+	space := func() {
+		for rnd.IntN(4) == 0 {
+			buf = append(buf, " \t\n\r"[rnd.IntN(4)])
+		}
+	}
+
+	n := rnd.IntN(10)
+	if depth > 6 {
+		n %= 6
+	}
+
+	switch n {
+	case 0:
+		buf = append(buf, []string{"0", "-1", "12.5e-3", "1E+2", "-0.0"}[rnd.IntN(5)]...)
+	case 1:
+		buf = append(buf, []string{"true", "false", "null"}[rnd.IntN(3)]...)
+	case 2, 3, 4, 5:
+		buf = append(buf, '"')
+		for i := rnd.IntN(8); i > 0; i-- {
+			buf = append(buf, []string{`a`, `,`, `[`, `]`, `{`, `}`, `:`, ` `, `\"`, `\\`, `\/`, `\n`, `é`, `😀`, "é"}[rnd.IntN(15)]...)
+		}
+		buf = append(buf, '"')
+	case 6, 7:
+		buf = append(buf, '[')
+		for i := rnd.IntN(5); i > 0; i-- {
+			space()
+			buf = randomValue(rnd, buf, depth+1)
+			space()
+			if i > 1 {
+				buf = append(buf, ',')
+			}
+		}
+		buf = append(buf, ']')
+	default:
+		buf = append(buf, '{')
+		for i := rnd.IntN(5); i > 0; i-- {
+			space()
+			buf = randomValue(rnd, append(buf, `"k`+fmt.Sprint(i)+`"`+` : `...), depth+1)
+			space()
+			if i > 1 {
+				buf = append(buf, ',')
+			}
+		}
+		buf = append(buf, '}')
+	}
+
+	return buf
+}
+
+// mutate makes a few random edits to data.
+func mutate(rnd *rand.Rand, data []byte) []byte {
+	const alphabet = "[]{}\",:\\ 0e-tn\x01\xff"
+
+	for n := rnd.IntN(3) + 1; n > 0 && len(data) > 0; n-- {
+		i := rnd.IntN(len(data))
+		switch rnd.IntN(3) {
+		case 0:
+			data = append(data[:i], data[i+1:]...)
+		case 1:
+			data = append(data[:i], append([]byte{alphabet[rnd.IntN(len(alphabet))]}, data[i:]...)...)
+		default:
+			data[i] = alphabet[rnd.IntN(len(alphabet))]
+		}
+	}
+
+	return data
+}
+
+func TestValidateParallelRandom(t *testing.T) {
+	defer func(size int) { speculativeChunkSize = size }(speculativeChunkSize)
+
+	rnd := rand.New(rand.NewPCG(1, 2))
+	n := 2000
+	if testing.Short() {
+		n = 200
+	}
+
+	for i := 0; i < n; i++ {
+		data := randomValue(rnd, nil, 0)
+		if i%2 == 1 {
+			data = mutate(rnd, data)
+		}
+
+		checkParallel(t, data, []int{1, 2, 3, 5, 8, 13, 32}, []int{2, 4})
+		if t.Failed() {
+			return
+		}
+	}
+}
+
+func BenchmarkValidateParallel(b *testing.B) {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for buf.Len() < 16<<20 {
+		buf.WriteString(sample)
+		buf.WriteByte(',')
+	}
+	buf.WriteString(sample)
+	buf.WriteByte(']')
+	data := buf.Bytes()
+
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := ValidateParallel(data, 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
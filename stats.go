@@ -0,0 +1,211 @@
+package jo
+
+import (
+	"io"
+	"strconv"
+)
+
+// Statistics describes the contents of a JSON document.
+type Statistics struct {
+	// Size is the length of the document in bytes, and Whitespace the
+	// number of those bytes which are insignificant whitespace.
+	Size       int64
+	Whitespace int64
+
+	// Number of values of each kind, and of object keys.
+	Objects, Arrays, Strings, Numbers, Bools, Nulls int64
+	Keys                                            int64
+
+	// MaxDepth is the deepest nesting of objects and arrays, or 0 if the
+	// document is a single scalar value.
+	MaxDepth int
+
+	// LongestString is the string value with the longest literal, quotes
+	// excluded, and LargestArray the array with the most elements.
+	LongestString Largest
+	LargestArray  Largest
+
+	// DistinctKeys holds the number of distinct keys of the objects found
+	// at each path. Array indices are written as [*], so the elements of
+	// an array are counted together.
+	DistinctKeys map[string]int
+}
+
+// Largest locates the largest instance of some kind of value in a document.
+// Its fields are all zero if there is no such value.
+type Largest struct {
+	// Size is a length in bytes, or a number of elements.
+	Size int64
+
+	// Offset is the input offset of the value's first byte, and Path the
+	// path to it, such as $.users[3].name.
+	Offset int64
+	Path   string
+}
+
+// statsFrame records an open object or array.
+type statsFrame struct {
+	array  bool
+	offset int64
+
+	// Number of elements so far, or the current key.
+	count int64
+	key   string
+
+	// Path to the container, with array indices written as [*].
+	path string
+}
+
+// Stats reads a single JSON value from r and describes it. Malformed input
+// results in a *SyntaxError, along with a description of the input preceding
+// the error; other errors are passed on from r.
+func Stats(r io.Reader) (Statistics, error) {
+	var st Statistics
+	var frames []statsFrame
+
+	// Distinct keys by path.
+	keys := make(map[string]map[string]struct{})
+
+	// Start of the current string or key, and the key read so far.
+	var start int64
+	var key []byte
+	inKey := false
+
+	s := NewScanner()
+	buf := make([]byte, 32<<10)
+
+	handle := func(ev Event, c byte, i int64) {
+		if ev&Space != 0 {
+			st.Whitespace++
+		}
+		if inKey {
+			key = append(key, c)
+		}
+
+		switch ev & End {
+		case None:
+		case KeyEnd:
+			// The key's end is signaled by the byte following it.
+			inKey = false
+			k := Unquote(key[:len(key)-1])
+			top := &frames[len(frames)-1]
+			top.key = k
+			if set := keys[top.path]; set != nil {
+				set[k] = struct{}{}
+			}
+		case StringEnd:
+			if n := i - start - 2; n > st.LongestString.Size || st.LongestString.Path == "" {
+				st.LongestString = Largest{n, start, statsPath(frames)}
+			}
+		case ArrayEnd:
+			top := frames[len(frames)-1]
+			frames = frames[:len(frames)-1]
+			if top.count > st.LargestArray.Size || st.LargestArray.Path == "" {
+				st.LargestArray = Largest{top.count, top.offset, statsPath(frames)}
+			}
+		case ObjectEnd:
+			frames = frames[:len(frames)-1]
+		}
+
+		if ev&KeyStart != 0 {
+			st.Keys++
+			inKey = true
+			key = append(key[:0], c)
+			return
+		}
+		if ev&Start == 0 {
+			return
+		}
+
+		// The path of a container, for counting distinct keys.
+		path := "$"
+		if n := len(frames); n > 0 {
+			top := &frames[n-1]
+			if top.array {
+				top.count++
+				path = top.path + "[*]"
+			} else {
+				path = string(appendKey([]byte(top.path), top.key))
+			}
+		}
+
+		switch ev & Start {
+		case ObjectStart:
+			st.Objects++
+			frames = append(frames, statsFrame{offset: i, path: path})
+			if keys[path] == nil {
+				keys[path] = make(map[string]struct{})
+			}
+		case ArrayStart:
+			st.Arrays++
+			frames = append(frames, statsFrame{array: true, offset: i, path: path})
+		case StringStart:
+			st.Strings++
+			start = i
+		case NumberStart:
+			st.Numbers++
+		case BoolStart:
+			st.Bools++
+		case NullStart:
+			st.Nulls++
+		}
+
+		st.MaxDepth = max(st.MaxDepth, len(frames))
+	}
+
+	finish := func() {
+		st.DistinctKeys = make(map[string]int, len(keys))
+		for path, set := range keys {
+			st.DistinctKeys[path] = len(set)
+		}
+	}
+
+	for {
+		n, err := r.Read(buf)
+
+		for _, c := range buf[:n] {
+			ev := s.Scan(c)
+			if ev == Error {
+				finish()
+				return st, s.syntaxError(st.Size)
+			}
+			handle(ev, c, st.Size)
+			st.Size++
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			finish()
+			return st, err
+		}
+	}
+
+	ev := s.End()
+	if ev == Error {
+		finish()
+		return st, s.syntaxError(st.Size)
+	}
+	handle(ev, ' ', st.Size)
+
+	finish()
+	return st, nil
+}
+
+// statsPath formats the path to the value being read.
+func statsPath(frames []statsFrame) string {
+	buf := []byte{'$'}
+
+	for _, f := range frames {
+		if f.array {
+			buf = append(buf, '[')
+			buf = strconv.AppendInt(buf, f.count-1, 10)
+			buf = append(buf, ']')
+		} else {
+			buf = appendKey(buf, f.key)
+		}
+	}
+
+	return string(buf)
+}
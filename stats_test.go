@@ -0,0 +1,91 @@
+package jo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func ExampleStats() {
+	st, err := Stats(strings.NewReader(`{"users": [{"name": "ada"}, {"name": "grace", "admin": true}]}`))
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(st.Objects, st.Arrays, st.Strings, st.Bools, st.MaxDepth)
+	fmt.Println(st.LongestString.Size, st.LongestString.Path)
+	fmt.Println(st.DistinctKeys["$.users[*]"])
+	// Output:
+	// 3 1 2 1 3
+	// 5 $.users[1].name
+	// 2
+}
+
+func TestStats(t *testing.T) {
+	const doc = "{\"a\": [1, 2.5, \"xy\"], \"b c\": {\"a\": null, \"a\": false},\n \"d\": [[], [{\"e\": \"\\u0041bc\"}, {\"f\": 1}]]} "
+
+	want := Statistics{
+		Size:          int64(len(doc)),
+		Whitespace:    16,
+		Objects:       4,
+		Arrays:        4,
+		Strings:       2,
+		Numbers:       3,
+		Bools:         1,
+		Nulls:         1,
+		Keys:          7,
+		MaxDepth:      4,
+		LongestString: Largest{8, 72, `$.d[1][0].e`},
+		LargestArray:  Largest{3, 6, `$.a`},
+		DistinctKeys:  map[string]int{`$`: 3, `$["b c"]`: 1, `$.d[*][*]`: 2},
+	}
+
+	// The result doesn't depend on how the input is read.
+	for _, r := range []*strings.Reader{strings.NewReader(doc), strings.NewReader(doc)} {
+		got, err := Stats(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got  %+v", got)
+			t.Errorf("want %+v", want)
+		}
+
+		got, err = Stats(iotest.OneByteReader(strings.NewReader(doc)))
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("one byte at a time: got %+v, %v", got, err)
+		}
+	}
+}
+
+func TestStatsScalar(t *testing.T) {
+	got, err := Stats(strings.NewReader(` 12 `))
+	want := Statistics{Size: 4, Whitespace: 2, Numbers: 1, DistinctKeys: map[string]int{}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, %v", got, err)
+	}
+}
+
+func TestStatsErrors(t *testing.T) {
+	got, err := Stats(strings.NewReader(`[1, "ab", }`))
+
+	var serr *SyntaxError
+	if !errors.As(err, &serr) || serr.Offset != 10 {
+		t.Errorf("got %v, want a syntax error at offset 10", err)
+	}
+	if got.Numbers != 1 || got.Strings != 1 || got.Arrays != 1 {
+		t.Errorf("got %+v for the input preceding the error", got)
+	}
+}
+
+func BenchmarkStats(b *testing.B) {
+	b.SetBytes(int64(len(sample)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		Stats(strings.NewReader(sample))
+	}
+}
@@ -0,0 +1,430 @@
+package jo
+
+import (
+	"io"
+)
+
+// A Token is a single lexical element of a JSON document: either a complete
+// key or scalar value, or one of the delimiters opening or closing an object
+// or array.
+type Token struct {
+	// Kind is one of ObjectStart, ObjectEnd, ArrayStart, ArrayEnd,
+	// KeyStart, StringStart, NumberStart, BoolStart or NullStart.
+	Kind Event
+
+	// Raw holds the token's bytes exactly as they appeared in the input,
+	// including the quotes surrounding keys and strings.
+	Raw []byte
+
+	// Offset is the input offset of the token's first byte.
+	Offset int64
+}
+
+// A SyntaxError describes malformed input, and where it was detected.
+type SyntaxError struct {
+	msg string
+
+	// Offset is the input offset of the offending byte.
+	Offset int64
+
+	// The Scanner's error, if any.
+	err error
+}
+
+// Error returns a description of the syntax error.
+func (e *SyntaxError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the underlying error, which is a *LimitError when the input
+// exceeded one of the Scanner's Limits.
+func (e *SyntaxError) Unwrap() error {
+	if _, ok := e.err.(*LimitError); ok {
+		return e.err
+	}
+	return nil
+}
+
+// A Tokenizer groups the events produced by a Scanner into tokens.
+//
+// Delimiters, strings and literals are returned as soon as their final byte
+// has been read, while numbers by necessity require one byte of lookahead
+// (or the end of input).
+type Tokenizer struct {
+	r io.Reader
+	s *Scanner
+
+	// Buffered input. The byte at buf[i] has the input offset off+i, and
+	// buf[pos] is the next byte to be scanned.
+	buf []byte
+	off int64
+	pos int
+
+	// Index into buf where the current token began, or -1 if no token is
+	// in progress. For strings and keys, esc is set while the byte
+	// following a backslash is pending.
+	start int
+	kind  Event
+	esc   bool
+
+	// Set when the byte at buf[qpos] is a closing delimiter which was
+	// seen in the same byte as a NumberEnd event.
+	queued bool
+	qpos   int
+
+	// Nesting depth and input offset of the last token's end.
+	depth int
+	mark  int64
+
+	// While pinned is set, input from the offset pin onwards is retained.
+	pin    int64
+	pinned bool
+
+	// Whether the input is a stream of whitespace-separated values
+	// rather than a single value.
+	multi bool
+
+	// Set when duplicate keys are disallowed, when input must conform
+	// to I-JSON, and when imprecise integers are to be reported.
+	keys  *keyChecker
+	ijson bool
+	warn  func(Token)
+
+	// Set when the reader has been drained, and when the Tokenizer
+	// has nothing more to give, respectively.
+	eof bool
+	err error
+}
+
+// NewTokenizer returns a Tokenizer reading a single JSON value from r.
+func NewTokenizer(r io.Reader) *Tokenizer {
+	return &Tokenizer{
+		r:     r,
+		s:     NewScanner(),
+		buf:   make([]byte, 0, 4096),
+		start: -1,
+	}
+}
+
+// NewBytesTokenizer returns a Tokenizer reading directly from data. The Raw
+// field of each token will point into data, and remains valid for as long as
+// data does.
+func NewBytesTokenizer(data []byte) *Tokenizer {
+	return &Tokenizer{
+		s:     NewScanner(),
+		buf:   data,
+		start: -1,
+		eof:   true,
+	}
+}
+
+// Next returns the next token. The returned token's Raw field is only valid
+// until the following call to Next.
+//
+// At the end of input Next returns io.EOF. Malformed input results in a
+// *SyntaxError, and any other errors are passed on from the underlying
+// io.Reader.
+func (t *Tokenizer) Next() (Token, error) {
+	tok, err := t.next()
+	if err != nil {
+		return tok, err
+	}
+
+	if t.keys != nil && t.keys.token(tok) {
+		t.err = t.keys.error(tok.Offset)
+		return Token{}, t.err
+	}
+	if t.ijson {
+		if err := checkIJSON(tok); err != nil {
+			t.err = err
+			return Token{}, err
+		}
+	}
+	if t.warn != nil && tok.Kind == NumberStart && isImpreciseInteger(tok.Raw) {
+		t.warn(tok)
+	}
+
+	return tok, nil
+}
+
+// next returns the next token, as described for Next.
+func (t *Tokenizer) next() (Token, error) {
+	if t.queued {
+		t.queued = false
+		return t.closing(t.qpos), nil
+	}
+	if t.err != nil {
+		return Token{}, t.err
+	}
+
+	for {
+		if t.pos == len(t.buf) {
+			if t.eof {
+				return t.end()
+			}
+			if err := t.fill(); err != nil {
+				t.err = err
+				return Token{}, err
+			}
+			continue
+		}
+
+		c := t.buf[t.pos]
+		i := t.pos
+
+		// When reading a stream of values, a top-level number has to be
+		// terminated as soon as a byte which can't be part of it shows up;
+		// otherwise the next value would trip up the Scanner.
+		if t.multi && t.depth == 0 && t.start >= 0 && t.kind == NumberStart && !isNumberByte(c) {
+			if t.s.End() == Error {
+				return t.fail(i)
+			}
+			return t.complete(i), nil
+		}
+
+		ev := t.s.Scan(c)
+		t.pos++
+
+		if ev == Error {
+			// A complete number is still returned when the byte following
+			// it is at fault, just like encoding/json does. The error is
+			// then reported by the next call.
+			if t.start >= 0 && t.kind == NumberStart && !isNumberByte(c) && table[t.buf[i-1]]&isDigit != 0 {
+				t.fail(i)
+				return t.complete(i), nil
+			}
+			return t.fail(i)
+		}
+
+		// Numbers are the only tokens whose end events aren't preempted
+		// by the byte-level checks below.
+		if t.start >= 0 && t.kind == NumberStart {
+			if ev&NumberEnd == 0 {
+				continue
+			}
+
+			if c == '}' || c == ']' {
+				t.queued = true
+				t.qpos = i
+			}
+			return t.complete(i), nil
+		}
+
+		if ev&Start != 0 {
+			kind := ev & Start
+			if kind == ObjectStart || kind == ArrayStart {
+				t.depth++
+				t.mark = t.off + int64(i+1)
+				return Token{kind, t.buf[i : i+1], t.off + int64(i)}, nil
+			}
+
+			t.start = i
+			t.kind = kind
+			continue
+		}
+
+		if t.start < 0 {
+			if c == '}' || c == ']' {
+				return t.closing(i), nil
+			}
+			continue
+		}
+
+		switch t.kind {
+		case StringStart, KeyStart:
+			if t.esc {
+				t.esc = false
+			} else if c == '\\' {
+				t.esc = true
+			} else if c == '"' {
+				return t.complete(i + 1), nil
+			}
+
+		case BoolStart, NullStart:
+			n := i + 1 - t.start
+			if n == 4 && t.buf[t.start] != 'f' || n == 5 {
+				return t.complete(i + 1), nil
+			}
+		}
+	}
+}
+
+// Skip skips past the remainder of the value starting with tok, which must
+// be the token most recently returned by Next.
+func (t *Tokenizer) Skip(tok Token) error {
+	if tok.Kind != ObjectStart && tok.Kind != ArrayStart {
+		return nil
+	}
+
+	for depth := 1; depth > 0; {
+		tok, err := t.Next()
+		if err != nil {
+			return err
+		}
+
+		switch tok.Kind {
+		case ObjectStart, ArrayStart:
+			depth++
+		case ObjectEnd, ArrayEnd:
+			depth--
+		}
+	}
+
+	return nil
+}
+
+// RawValue skips past the remainder of the value starting with tok, which
+// must be the token most recently returned by Next, and returns all of the
+// value's bytes. The result is only valid until the following call to Next.
+func (t *Tokenizer) RawValue(tok Token) ([]byte, error) {
+	if tok.Kind != ObjectStart && tok.Kind != ArrayStart {
+		return tok.Raw, nil
+	}
+
+	t.pin = tok.Offset
+	t.pinned = true
+	err := t.Skip(tok)
+	t.pinned = false
+
+	if err != nil {
+		return nil, err
+	}
+
+	return t.buf[tok.Offset-t.off : t.mark-t.off], nil
+}
+
+// Offset returns the input offset immediately following the most recently
+// returned token.
+func (t *Tokenizer) Offset() int64 {
+	return t.mark
+}
+
+// peek returns the next byte of input which isn't whitespace, without
+// consuming it.
+func (t *Tokenizer) peek() (byte, error) {
+	if t.queued {
+		return t.buf[t.qpos], nil
+	}
+	if t.err != nil {
+		return 0, t.err
+	}
+
+	for n := 0; ; {
+		if t.pos+n == len(t.buf) {
+			if t.eof {
+				return 0, io.EOF
+			}
+			if err := t.fill(); err != nil {
+				t.err = err
+				return 0, err
+			}
+			continue
+		}
+
+		if c := t.buf[t.pos+n]; table[c]&isSpace == 0 {
+			return c, nil
+		}
+		n++
+	}
+}
+
+// end handles the end of input.
+func (t *Tokenizer) end() (Token, error) {
+	// A stream of values may end cleanly at any top-level boundary.
+	if t.multi && t.depth == 0 && t.start < 0 {
+		t.err = io.EOF
+		return Token{}, io.EOF
+	}
+
+	if t.s.End() == Error {
+		return t.fail(len(t.buf))
+	}
+
+	if t.start >= 0 {
+		return t.complete(len(t.buf)), nil
+	}
+
+	t.err = io.EOF
+	return Token{}, io.EOF
+}
+
+// complete finishes the token in progress, which ends at buf[j].
+func (t *Tokenizer) complete(j int) Token {
+	tok := Token{t.kind, t.buf[t.start:j], t.off + int64(t.start)}
+
+	t.start = -1
+	t.mark = t.off + int64(j)
+	t.top()
+
+	return tok
+}
+
+// closing produces a token for the closing delimiter at buf[i].
+func (t *Tokenizer) closing(i int) Token {
+	var kind Event = ObjectEnd
+	if t.buf[i] == ']' {
+		kind = ArrayEnd
+	}
+
+	t.depth--
+	t.mark = t.off + int64(i+1)
+	t.top()
+
+	return Token{kind, t.buf[i : i+1], t.off + int64(i)}
+}
+
+// top resets the Scanner after each complete top-level value when reading a
+// stream of values.
+func (t *Tokenizer) top() {
+	if t.multi && t.depth == 0 {
+		t.s.restart()
+	}
+}
+
+// fail persists and returns the Scanner's syntax error, positioned at buf[i].
+func (t *Tokenizer) fail(i int) (Token, error) {
+	t.err = t.s.syntaxError(t.off + int64(i))
+	return Token{}, t.err
+}
+
+// fill reads more input, first discarding bytes which are no longer needed.
+func (t *Tokenizer) fill() error {
+	keep := t.start
+	if keep < 0 {
+		keep = int(t.mark - t.off)
+	}
+	if t.pinned && int(t.pin-t.off) < keep {
+		keep = int(t.pin - t.off)
+	}
+
+	if keep > 0 {
+		n := copy(t.buf, t.buf[keep:])
+		t.buf = t.buf[:n]
+		t.off += int64(keep)
+		t.pos -= keep
+		if t.start >= 0 {
+			t.start -= keep
+		}
+	}
+
+	if len(t.buf) == cap(t.buf) {
+		buf := make([]byte, len(t.buf), 2*cap(t.buf)+4096)
+		copy(buf, t.buf)
+		t.buf = buf
+	}
+
+	n, err := t.r.Read(t.buf[len(t.buf):cap(t.buf)])
+	t.buf = t.buf[:len(t.buf)+n]
+
+	if err == io.EOF {
+		t.eof = true
+		return nil
+	}
+
+	return err
+}
+
+// isNumberByte reports whether c may appear in a numeric literal.
+func isNumberByte(c byte) bool {
+	return table[c]&isDigit != 0 || c == '.' || c == 'e' || c == 'E' || c == '+' || c == '-'
+}
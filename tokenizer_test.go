@@ -0,0 +1,163 @@
+package jo
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+var tokenizerTests = []struct {
+	in  string
+	out []string
+}{
+	{
+		` 123 `,
+		[]string{
+			`NumberStart 1 "123"`,
+		},
+	},
+	{
+		`{"a":[1,-2.5e3,"x\"]"],"b":{},"c":true,"d":null,"e":false}`,
+		[]string{
+			`ObjectStart 0 "{"`,
+			`KeyStart 1 "\"a\""`,
+			`ArrayStart 5 "["`,
+			`NumberStart 6 "1"`,
+			`NumberStart 8 "-2.5e3"`,
+			`StringStart 15 "\"x\\\"]\""`,
+			`ArrayEnd 21 "]"`,
+			`KeyStart 23 "\"b\""`,
+			`ObjectStart 27 "{"`,
+			`ObjectEnd 28 "}"`,
+			`KeyStart 30 "\"c\""`,
+			`BoolStart 34 "true"`,
+			`KeyStart 39 "\"d\""`,
+			`NullStart 43 "null"`,
+			`KeyStart 48 "\"e\""`,
+			`BoolStart 52 "false"`,
+			`ObjectEnd 57 "}"`,
+		},
+	},
+	{
+		`[ [ ] , [[0]] ]`,
+		[]string{
+			`ArrayStart 0 "["`,
+			`ArrayStart 2 "["`,
+			`ArrayEnd 4 "]"`,
+			`ArrayStart 8 "["`,
+			`ArrayStart 9 "["`,
+			`NumberStart 10 "0"`,
+			`ArrayEnd 11 "]"`,
+			`ArrayEnd 12 "]"`,
+			`ArrayEnd 14 "]"`,
+		},
+	},
+}
+
+func readTokens(t *Tokenizer) ([]string, error) {
+	var out []string
+
+	for {
+		tok, err := t.Next()
+		if err == io.EOF {
+			return out, nil
+		} else if err != nil {
+			return out, err
+		}
+
+		out = append(out, fmt.Sprintf("%s %d %q", tok.Kind, tok.Offset, tok.Raw))
+	}
+}
+
+func TestTokenizer(t *testing.T) {
+	for _, test := range tokenizerTests {
+		for _, r := range []io.Reader{
+			strings.NewReader(test.in),
+			iotest.OneByteReader(strings.NewReader(test.in)),
+		} {
+			out, err := readTokens(NewTokenizer(r))
+			if err != nil {
+				t.Errorf("Tokenizer(%#q): unexpected error: %s", test.in, err)
+				continue
+			}
+
+			if strings.Join(out, "\n") != strings.Join(test.out, "\n") {
+				t.Errorf("Tokenizer(%#q):", test.in)
+				t.Errorf("  got  %q", out)
+				t.Errorf("  want %q", test.out)
+			}
+		}
+	}
+}
+
+var tokenizerErrorTests = []struct {
+	in     string
+	offset int64
+}{
+	{``, 0},
+	{`[1,]`, 3},
+	{`{"a" 1}`, 5},
+	{`[1] 2`, 4},
+	{`"abc`, 4},
+}
+
+func TestTokenizerErrors(t *testing.T) {
+	for _, test := range tokenizerErrorTests {
+		_, err := readTokens(NewTokenizer(strings.NewReader(test.in)))
+
+		serr, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("Tokenizer(%#q): got error %v, want *SyntaxError", test.in, err)
+		} else if serr.Offset != test.offset {
+			t.Errorf("Tokenizer(%#q): got error at offset %d, want %d", test.in, serr.Offset, test.offset)
+		}
+	}
+}
+
+func TestTokenizerStream(t *testing.T) {
+	var in = `1 "a"{} [2]3` + "\n" + `null`
+	var want = []string{
+		`NumberStart 0 "1"`,
+		`StringStart 2 "\"a\""`,
+		`ObjectStart 5 "{"`,
+		`ObjectEnd 6 "}"`,
+		`ArrayStart 8 "["`,
+		`NumberStart 9 "2"`,
+		`ArrayEnd 10 "]"`,
+		`NumberStart 11 "3"`,
+		`NullStart 13 "null"`,
+	}
+
+	tz := NewTokenizer(iotest.OneByteReader(strings.NewReader(in)))
+	tz.multi = true
+
+	out, err := readTokens(tz)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if strings.Join(out, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got  %q", out)
+		t.Errorf("want %q", want)
+	}
+}
+
+func BenchmarkTokenizer(b *testing.B) {
+	var r = strings.NewReader(sample)
+
+	b.SetBytes(int64(len(sample)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		r.Reset(sample)
+		t := NewTokenizer(r)
+
+		for {
+			if _, err := t.Next(); err != nil {
+				break
+			}
+		}
+	}
+}
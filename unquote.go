@@ -0,0 +1,208 @@
+package jo
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Unquote decodes a quoted string literal which the Scanner has accepted,
+// such as the Raw field of a key or string token.
+func Unquote(raw []byte) string {
+	return string(unquote(nil, raw))
+}
+
+// unquote decodes the quoted string literal raw, which the Scanner must
+// already have accepted, and appends the result to dst. Invalid UTF-8 and
+// unpaired surrogate escapes are replaced with U+FFFD, just like
+// encoding/json does.
+func unquote(dst, raw []byte) []byte {
+	raw = raw[1 : len(raw)-1]
+
+	for i := 0; i < len(raw); {
+		c := raw[i]
+
+		if c < utf8.RuneSelf && c != '\\' {
+			dst = append(dst, c)
+			i++
+			continue
+		}
+
+		if c != '\\' {
+			r, n := utf8.DecodeRune(raw[i:])
+			if r == utf8.RuneError && n == 1 {
+				dst = utf8.AppendRune(dst, utf8.RuneError)
+			} else {
+				dst = append(dst, raw[i:i+n]...)
+			}
+			i += n
+			continue
+		}
+
+		switch raw[i+1] {
+		case 'b':
+			dst = append(dst, '\b')
+		case 'f':
+			dst = append(dst, '\f')
+		case 'n':
+			dst = append(dst, '\n')
+		case 'r':
+			dst = append(dst, '\r')
+		case 't':
+			dst = append(dst, '\t')
+		case 'u':
+			r := hex4(raw[i+2:])
+			i += 6
+
+			if utf16.IsSurrogate(r) {
+				r2 := rune(-1)
+				if i+6 <= len(raw) && raw[i] == '\\' && raw[i+1] == 'u' {
+					r2 = hex4(raw[i+2:])
+				}
+
+				if r = utf16.DecodeRune(r, r2); r != utf8.RuneError {
+					i += 6
+				}
+			}
+
+			dst = utf8.AppendRune(dst, r)
+			continue
+		default:
+			// One of '"', '\\' and '/'.
+			dst = append(dst, raw[i+1])
+		}
+
+		i += 2
+	}
+
+	return dst
+}
+
+// hex4 decodes the four hexadecimal digits at the start of b.
+func hex4(b []byte) rune {
+	var r rune
+
+	for _, c := range b[:4] {
+		switch {
+		case c <= '9':
+			c -= '0'
+		case c <= 'F':
+			c -= 'A' - 10
+		default:
+			c -= 'a' - 10
+		}
+		r = r<<4 | rune(c)
+	}
+
+	return r
+}
+
+// checkString checks that the quoted string literal raw, which the Scanner
+// must already have accepted, consists of valid UTF-8 and has no unpaired
+// surrogates among its escape sequences. It returns the index of the first
+// problem along with a description, or an empty description if there is
+// none.
+func checkString(raw []byte) (int, string) {
+	for i := 1; i < len(raw)-1; {
+		c := raw[i]
+
+		if c >= utf8.RuneSelf {
+			r, n := utf8.DecodeRune(raw[i:])
+			if r == utf8.RuneError && n == 1 {
+				return i, "invalid UTF-8 in string literal"
+			}
+			i += n
+			continue
+		}
+
+		if c != '\\' {
+			i++
+			continue
+		}
+		if raw[i+1] != 'u' {
+			i += 2
+			continue
+		}
+
+		r := hex4(raw[i+2:])
+		if !utf16.IsSurrogate(r) {
+			i += 6
+			continue
+		}
+
+		next := raw[i+6:]
+		if r >= 0xdc00 || len(next) < 6 || next[0] != '\\' || next[1] != 'u' {
+			return i, "unpaired surrogate escape " + string(raw[i:i+6]) + " in string literal"
+		}
+		if r2 := hex4(next[2:]); r2 < 0xdc00 || r2 >= 0xe000 {
+			return i, "unpaired surrogate escape " + string(raw[i:i+6]) + " in string literal"
+		}
+		i += 12
+	}
+
+	return 0, ""
+}
+
+// quote appends s to dst as a quoted string literal, escaping it exactly the
+// way encoding/json does. Invalid UTF-8 is replaced with U+FFFD, and with
+// escapeHTML set, so are '<', '>' and '&' escaped.
+func quote(dst []byte, s string, escapeHTML bool) []byte {
+	const hex = "0123456789abcdef"
+
+	dst = append(dst, '"')
+	start := 0
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && (!escapeHTML || c != '<' && c != '>' && c != '&') {
+				i++
+				continue
+			}
+
+			dst = append(dst, s[start:i]...)
+
+			switch c {
+			case '"', '\\':
+				dst = append(dst, '\\', c)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+			}
+
+			i++
+			start = i
+			continue
+		}
+
+		r, n := utf8.DecodeRuneInString(s[i:])
+
+		if r == utf8.RuneError && n == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = utf8.AppendRune(dst, utf8.RuneError)
+		} else if r == '\u2028' || r == '\u2029' {
+			// Line and paragraph separators are valid in JSON strings,
+			// but not in JavaScript ones.
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hex[r&0xF])
+		} else {
+			i += n
+			continue
+		}
+
+		i += n
+		start = i
+	}
+
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
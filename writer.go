@@ -0,0 +1,332 @@
+package jo
+
+import (
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// A Writer writes a single JSON value to an io.Writer, one token at a time.
+//
+// Commas and colons are inserted automatically. Every call is checked against
+// the same grammar the Scanner implements, so a Writer can't produce invalid
+// JSON: a call which would, such as writing a value where an object key is
+// expected, fails with a *SyntaxError instead. Errors are persistent, and all
+// calls following an error return it without writing anything.
+//
+// Output is buffered; call Close once the value is complete, or Flush to write
+// out a partial value.
+type Writer struct {
+	w   io.Writer
+	buf []byte
+
+	// Number of bytes already passed on to w.
+	n int64
+
+	// The Scanner tracks what has been written so far, while probe is
+	// used to try out calls before committing to them.
+	s     Scanner
+	probe Scanner
+
+	// Set when a comma is due before the next value or key, that is
+	// after any value has been written.
+	comma bool
+
+	escapeHTML bool
+
+	// The first error encountered, and the first error returned by w.
+	err  error
+	werr error
+}
+
+// NewWriter returns a Writer writing to w.
+func NewWriter(w io.Writer) *Writer {
+	wr := &Writer{
+		w:          w,
+		buf:        make([]byte, 0, 4096),
+		escapeHTML: true,
+	}
+	wr.s.Reset()
+	return wr
+}
+
+// SetEscapeHTML specifies whether the characters '<', '>' and '&' should be
+// escaped in strings, as encoding/json does by default. It defaults to true.
+func (w *Writer) SetEscapeHTML(on bool) {
+	w.escapeHTML = on
+}
+
+// BeginObject writes the start of an object.
+func (w *Writer) BeginObject() error {
+	if !w.token(ObjectStart, "{", "start of object") {
+		return w.err
+	}
+	w.buf = append(w.buf, '{')
+	return w.flush(false)
+}
+
+// EndObject writes the end of the current object.
+func (w *Writer) EndObject() error {
+	if !w.token(None, "}", "end of object") {
+		return w.err
+	}
+	w.buf = append(w.buf, '}')
+	return w.flush(false)
+}
+
+// BeginArray writes the start of an array.
+func (w *Writer) BeginArray() error {
+	if !w.token(ArrayStart, "[", "start of array") {
+		return w.err
+	}
+	w.buf = append(w.buf, '[')
+	return w.flush(false)
+}
+
+// EndArray writes the end of the current array.
+func (w *Writer) EndArray() error {
+	if !w.token(None, "]", "end of array") {
+		return w.err
+	}
+	w.buf = append(w.buf, ']')
+	return w.flush(false)
+}
+
+// Key writes an object key, followed by a colon.
+func (w *Writer) Key(name string) error {
+	if !w.token(KeyStart, `"":`, "object key") {
+		return w.err
+	}
+	w.buf = quote(w.buf, name, w.escapeHTML)
+	w.buf = append(w.buf, ':')
+	return w.flush(false)
+}
+
+// String writes a string value.
+func (w *Writer) String(s string) error {
+	if !w.token(StringStart, `""`, "string") {
+		return w.err
+	}
+	w.buf = quote(w.buf, s, w.escapeHTML)
+	return w.flush(false)
+}
+
+// Int writes an integer value.
+func (w *Writer) Int(n int64) error {
+	if !w.token(NumberStart, "0", "number") {
+		return w.err
+	}
+	w.buf = strconv.AppendInt(w.buf, n, 10)
+	return w.flush(false)
+}
+
+// Float writes a floating-point value, formatted the way encoding/json does.
+// NaN and infinities are rejected, since JSON can't represent them.
+func (w *Writer) Float(f float64) error {
+	if w.err != nil {
+		return w.err
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		w.err = &UnsupportedValueError{reflect.ValueOf(f), strconv.FormatFloat(f, 'g', -1, 64)}
+		return w.err
+	}
+
+	if !w.token(NumberStart, "0", "number") {
+		return w.err
+	}
+	w.buf = appendFloat(w.buf, f, 64)
+	return w.flush(false)
+}
+
+// Number writes a numeric literal exactly as given, such as the text of a
+// json.Number. Anything but a valid JSON number is rejected.
+func (w *Writer) Number(n string) error {
+	if w.err != nil {
+		return w.err
+	}
+	if !validNumber([]byte(n)) {
+		w.err = &SyntaxError{msg: "jo: invalid number literal " + strconv.Quote(n), Offset: w.Offset()}
+		return w.err
+	}
+
+	if !w.token(NumberStart, "0", "number") {
+		return w.err
+	}
+	w.buf = append(w.buf, n...)
+	return w.flush(false)
+}
+
+// Bool writes a boolean value.
+func (w *Writer) Bool(b bool) error {
+	if !w.token(BoolStart, "true", "boolean") {
+		return w.err
+	}
+	w.buf = strconv.AppendBool(w.buf, b)
+	return w.flush(false)
+}
+
+// Null writes a null value.
+func (w *Writer) Null() error {
+	if !w.token(NullStart, "null", "null") {
+		return w.err
+	}
+	w.buf = append(w.buf, "null"...)
+	return w.flush(false)
+}
+
+// Offset returns the number of bytes written so far, including buffered
+// output.
+func (w *Writer) Offset() int64 {
+	return w.n + int64(len(w.buf))
+}
+
+// Flush writes any buffered output to the underlying io.Writer. Output
+// preceding an error is still written.
+func (w *Writer) Flush() error {
+	if err := w.flush(true); err != nil {
+		return err
+	}
+	return w.err
+}
+
+// Close checks that a complete value has been written, and flushes the
+// output. It doesn't close the underlying io.Writer.
+func (w *Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.s.End() == Error {
+		w.err = &SyntaxError{msg: "jo: incomplete value", Offset: w.Offset()}
+		return w.err
+	}
+	return w.flush(true)
+}
+
+// token checks whether a token of the given kind may be written next, and
+// writes a comma first if one is needed. A token's stand-in is any sequence
+// of bytes which is scanned the same way the token would be.
+//
+// If the token isn't allowed, an error is persisted and token returns false.
+func (w *Writer) token(kind Event, standIn, desc string) bool {
+	if w.err != nil {
+		return false
+	}
+
+	// Closing delimiters never follow a comma.
+	comma := w.comma && kind != None
+
+	if !w.try(kind, standIn, comma) {
+		w.err = &SyntaxError{msg: "jo: unexpected " + desc + w.expecting(), Offset: w.Offset()}
+		return false
+	}
+
+	w.s, w.probe = w.probe, w.s
+	if comma {
+		w.buf = append(w.buf, ',')
+	}
+
+	// Everything but the start of a container or a key completes a value.
+	w.comma = kind != ObjectStart && kind != ArrayStart && kind != KeyStart
+	return true
+}
+
+// try feeds the probe a copy of the Scanner's state, followed by standIn
+// (with a comma prepended if comma is set), and reports whether the first
+// byte of standIn produces the kind of event expected without any errors.
+func (w *Writer) try(kind Event, standIn string, comma bool) bool {
+	w.probe.copyFrom(&w.s)
+
+	if comma && w.probe.Scan(',') == Error {
+		return false
+	}
+	if ev := w.probe.Scan(standIn[0]); ev == Error || ev&Start != kind {
+		return false
+	}
+
+	for i := 1; i < len(standIn); i++ {
+		if w.probe.Scan(standIn[i]) == Error {
+			return false
+		}
+	}
+
+	return true
+}
+
+// expecting describes the tokens which could have been written instead of
+// an unexpected one.
+func (w *Writer) expecting() string {
+	var want []string
+
+	for _, alt := range []struct {
+		kind    Event
+		standIn string
+		desc    string
+	}{
+		{KeyStart, `"`, "object key"},
+		{NullStart, "n", "value"},
+		{None, "}", "end of object"},
+		{None, "]", "end of array"},
+	} {
+		if w.try(alt.kind, alt.standIn, false) || w.try(alt.kind, alt.standIn, true) {
+			want = append(want, alt.desc)
+		}
+	}
+
+	if len(want) == 0 {
+		return " after top-level value"
+	}
+	return ", expecting " + strings.Join(want, " or ")
+}
+
+// flush writes out buffered output if forced to, or if enough of it has
+// accumulated.
+func (w *Writer) flush(force bool) error {
+	if !force && len(w.buf) < cap(w.buf)/2 {
+		return nil
+	}
+	if w.werr != nil {
+		return w.werr
+	}
+
+	n, err := w.w.Write(w.buf)
+	w.n += int64(n)
+	w.buf = w.buf[:copy(w.buf, w.buf[n:])]
+
+	if err != nil {
+		w.werr = err
+		if w.err == nil {
+			w.err = err
+		}
+	}
+	return err
+}
+
+// appendFloat appends f, which must be finite, formatted the way
+// encoding/json formats floating-point numbers of the given bit size.
+func appendFloat(dst []byte, f float64, bits int) []byte {
+	abs := math.Abs(f)
+	format := byte('f')
+
+	// Switch to exponent notation at the same cutoffs as ES6 does, but
+	// for float32 values compare with float32 precision.
+	if abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+
+	dst = strconv.AppendFloat(dst, f, format, -1, bits)
+
+	// Trim exponents like "e-07" down to "e-7".
+	if format == 'e' {
+		n := len(dst)
+		if n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+
+	return dst
+}
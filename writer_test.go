@@ -0,0 +1,220 @@
+package jo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"testing"
+)
+
+func ExampleWriter() {
+	w := NewWriter(os.Stdout)
+
+	w.BeginObject()
+	w.Key("name")
+	w.String("jo")
+	w.Key("tags")
+	w.BeginArray()
+	w.Int(1)
+	w.Float(2.5)
+	w.Bool(true)
+	w.Null()
+	w.EndArray()
+	w.EndObject()
+
+	if err := w.Close(); err != nil {
+		panic(err)
+	}
+	// Output:
+	// {"name":"jo","tags":[1,2.5,true,null]}
+}
+
+type writerCall func(w *Writer) error
+
+var (
+	beginObject = (*Writer).BeginObject
+	endObject   = (*Writer).EndObject
+	beginArray  = (*Writer).BeginArray
+	endArray    = (*Writer).EndArray
+	null        = (*Writer).Null
+)
+
+func key(s string) writerCall { return func(w *Writer) error { return w.Key(s) } }
+func str(s string) writerCall { return func(w *Writer) error { return w.String(s) } }
+func num(n int64) writerCall  { return func(w *Writer) error { return w.Int(n) } }
+func lit(n string) writerCall { return func(w *Writer) error { return w.Number(n) } }
+
+var writerTests = []struct {
+	calls []writerCall
+	out   string
+	err   string
+}{
+	{[]writerCall{null}, `null`, ``},
+	{[]writerCall{num(-12)}, `-12`, ``},
+	{[]writerCall{beginObject, endObject}, `{}`, ``},
+	{[]writerCall{beginArray, endArray}, `[]`, ``},
+	{[]writerCall{beginArray, num(1), num(2), beginArray, endArray, beginObject, endObject, str("x"), endArray}, `[1,2,[],{},"x"]`, ``},
+	{[]writerCall{beginObject, key("a"), num(1), key("b"), beginObject, key("c"), beginArray, null, endArray, endObject, key("d"), str(""), endObject}, `{"a":1,"b":{"c":[null]},"d":""}`, ``},
+
+	{[]writerCall{beginArray, lit("1.50"), lit("-0e+7"), endArray}, `[1.50,-0e+7]`, ``},
+	{[]writerCall{beginArray, lit("01")}, `[`, `jo: invalid number literal "01"`},
+	{[]writerCall{beginArray, lit("")}, `[`, `jo: invalid number literal ""`},
+
+	{[]writerCall{beginObject, str("x")}, `{`, `jo: unexpected string, expecting object key or end of object`},
+	{[]writerCall{beginObject, key("a"), num(1), num(2)}, `{"a":1`, `jo: unexpected number, expecting object key or end of object`},
+	{[]writerCall{beginObject, key("a"), key("b")}, `{"a":`, `jo: unexpected object key, expecting value`},
+	{[]writerCall{beginObject, key("a"), endObject}, `{"a":`, `jo: unexpected end of object, expecting value`},
+	{[]writerCall{beginArray, key("a")}, `[`, `jo: unexpected object key, expecting value or end of array`},
+	{[]writerCall{beginArray, endObject}, `[`, `jo: unexpected end of object, expecting value or end of array`},
+	{[]writerCall{null, null}, `null`, `jo: unexpected null after top-level value`},
+	{[]writerCall{endArray}, ``, `jo: unexpected end of array, expecting value`},
+	{[]writerCall{beginArray, num(1)}, `[1`, `jo: incomplete value`},
+	{[]writerCall{}, ``, `jo: incomplete value`},
+}
+
+func TestWriter(t *testing.T) {
+	for i, test := range writerTests {
+		var buf bytes.Buffer
+		w := NewWriter(&buf)
+
+		var err error
+		for _, call := range test.calls {
+			if err = call(w); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = w.Close()
+		}
+		w.Flush()
+
+		var msg string
+		if err != nil {
+			msg = err.Error()
+		}
+
+		if buf.String() != test.out || msg != test.err {
+			t.Errorf("#%d: got %#q, %q", i, buf.String(), msg)
+			t.Errorf("#%d: want %#q, %q", i, test.out, test.err)
+		}
+	}
+}
+
+func TestWriterPersistentError(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	w.BeginArray()
+	err := w.Key("a")
+
+	var serr *SyntaxError
+	if !errors.As(err, &serr) || serr.Offset != 1 {
+		t.Fatalf("got error %v, want *SyntaxError at offset 1", err)
+	}
+	if err2 := w.Int(1); err2 != err {
+		t.Errorf("got error %v after failure, want %v", err2, err)
+	}
+	if err2 := w.Close(); err2 != err {
+		t.Errorf("got error %v from Close, want %v", err2, err)
+	}
+}
+
+func TestWriterStrings(t *testing.T) {
+	for _, s := range []string{
+		"", "plain", `"quoted" \back\slashed`, "\b\f\n\r\t\x00\x1f\x7f",
+		"<html> & friends", "é😀", "  ", "bad \xff utf-8 \xed\xa0\x80",
+	} {
+		for _, escapeHTML := range []bool{true, false} {
+			var buf, want bytes.Buffer
+
+			w := NewWriter(&buf)
+			w.SetEscapeHTML(escapeHTML)
+			w.String(s)
+			w.Close()
+
+			enc := json.NewEncoder(&want)
+			enc.SetEscapeHTML(escapeHTML)
+			enc.Encode(s)
+
+			if got := buf.String() + "\n"; got != want.String() {
+				t.Errorf("String(%q) with escapeHTML %v:", s, escapeHTML)
+				t.Errorf("  got  %s", got)
+				t.Errorf("  want %s", want.String())
+			}
+		}
+	}
+}
+
+func TestWriterFloats(t *testing.T) {
+	for _, f := range []float64{0, math.Copysign(0, -1), 1, -1.5, 1e20, 1e21, 1e-6, 1e-7, 123456789.125, math.MaxFloat64, math.SmallestNonzeroFloat64} {
+		var buf bytes.Buffer
+
+		w := NewWriter(&buf)
+		w.Float(f)
+		w.Close()
+
+		want, _ := json.Marshal(f)
+		if buf.String() != string(want) {
+			t.Errorf("Float(%v): got %s, want %s", f, buf.String(), want)
+		}
+	}
+
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		w := NewWriter(new(bytes.Buffer))
+		if err := w.Float(f); err == nil {
+			t.Errorf("Float(%v): got no error", f)
+		}
+	}
+}
+
+func TestWriterFlush(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	w.BeginArray()
+	for i := 0; i < 10000; i++ {
+		w.Int(int64(i))
+	}
+
+	if buf.Len() == 0 {
+		t.Errorf("got no output before Flush")
+	}
+	if w.Flush(); int64(buf.Len()) != w.Offset() {
+		t.Errorf("got %d bytes after Flush, want %d", buf.Len(), w.Offset())
+	}
+
+	w.EndArray()
+	w.Close()
+
+	var v []int
+	if err := json.Unmarshal(buf.Bytes(), &v); err != nil || len(v) != 10000 {
+		t.Errorf("got invalid output: %v", err)
+	}
+}
+
+func BenchmarkWriter(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var buf bytes.Buffer
+		w := NewWriter(&buf)
+
+		w.BeginArray()
+		for j := 0; j < 100; j++ {
+			w.BeginObject()
+			w.Key("id")
+			w.Int(int64(j))
+			w.Key("name")
+			w.String("some name")
+			w.Key("ok")
+			w.Bool(true)
+			w.EndObject()
+		}
+		w.EndArray()
+		w.Close()
+
+		b.SetBytes(int64(buf.Len()))
+	}
+}